@@ -0,0 +1,37 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DumpWithAttachments returns a string representation of value dumped with
+// opts and the child values larger than opts.ExtractLargeValues extracted
+// from it. The extracted values are keyed by their reference ids, e.g. "#1",
+// printed in the main dump as <see attachment #1>.
+func DumpWithAttachments(value interface{}, opts Options) (string, map[string]string) {
+	if opts.SnapshotFirst {
+		value = snapshot(value)
+	}
+	ds := &dumpState{Options: opts, attachments: map[string]string{}}
+	ds.startTimer()
+	s := ds.valueString(reflect.ValueOf(value), opts.Depth, 0, 0, "", false, false)
+	return ds.decorate(value, s), ds.attachments
+}
+
+// extract moves the dumped child value out to the attachments if it is larger
+// than ExtractLargeValues. indent is the indentation of the child value,
+// which is removed from the attachment.
+func (s *dumpState) extract(out, indent string) string {
+	if s.attachments == nil || s.ExtractLargeValues <= 0 || len(out) <= s.ExtractLargeValues {
+		return out
+	}
+	prefix := ""
+	if strings.HasPrefix(out, indent) {
+		prefix, out = indent, out[len(indent):]
+	}
+	id := fmt.Sprintf("#%d", len(s.attachments)+1)
+	s.attachments[id] = strings.ReplaceAll(out, "\n"+indent, "\n")
+	return prefix + "<see attachment " + id + ">"
+}
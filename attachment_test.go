@@ -0,0 +1,41 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type attachedReport struct {
+	ID   int
+	Body string
+	Rows [][]int
+}
+
+// TestDumpWithAttachments moves the large values out to the attachments
+// unindented, the inner ones first so that the outer attachments refer to
+// them.
+func TestDumpWithAttachments(t *testing.T) {
+	v := attachedReport{1, "a long body of the report", [][]int{{1, 2, 3, 4}, {5}}}
+	opts := Options{Depth: 3, ExtractLargeValues: 20}
+	out, attachments := DumpWithAttachments(v, opts)
+	if want := "gdump.attachedReport{\n• ID:int{1}\n• Body:<see attachment #1>\n• Rows:<see attachment #4>}"; out != want {
+		t.Errorf("DumpWithAttachments = %q, want %q", out, want)
+	}
+	want := map[string]string{
+		"#1": "string{a long body of the report}",
+		"#2": "[]int{\n• int{1}\n• int{2}\n• int{3}\n• int{4}}",
+		"#3": "[]int{\n• int{5}}",
+		"#4": "[][]int{\n• <see attachment #2>\n• <see attachment #3>}",
+	}
+	if !reflect.DeepEqual(attachments, want) {
+		t.Errorf("attachments = %q, want %q", attachments, want)
+	}
+
+	// Sdump has no attachments to move the values to
+	if got, want := Sdump(v, opts), Sdump(v, Options{Depth: 3}); got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if _, attachments := DumpWithAttachments(v, Options{Depth: 3}); len(attachments) != 0 {
+		t.Errorf("attachments = %q without ExtractLargeValues, want none", attachments)
+	}
+}
@@ -0,0 +1,139 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldInfo - the metadata of a struct field computed once for its type
+type fieldInfo struct {
+	ft   reflect.StructField
+	tag  fieldTag
+	refs bool // true if the values of the field may hold references
+}
+
+// fieldRule - the decisions of the options on a struct field
+type fieldRule struct {
+	omitted  bool // skipped by its tag, its names or its type
+	redacted bool // redacted by its tag or its name
+}
+
+// fieldRules - the decisions of the options on the fields of the struct
+// types dumped, shared by the dumps of a Dumper as its options are fixed.
+// The decisions depending on the paths, i.e. ExcludedField with dots, and
+// on the values, i.e. FieldFilter, are not cached.
+type fieldRules struct {
+	types sync.Map   // reflect.Type -> []fieldRule
+	paths [][]string // the entries of ExcludedField with dots split
+}
+
+var (
+	// fieldCache - the fields of the struct types dumped
+	fieldCache sync.Map // reflect.Type -> []fieldInfo
+	// rendererCache - whether the types or the pointers to them implement
+	// any interface of rendererTypes
+	rendererCache sync.Map // reflect.Type -> bool
+	// refsCache - whether the values of the types may hold references
+	refsCache sync.Map // reflect.Type -> bool
+)
+
+// typeFields returns the fields of the struct type t and the directives of
+// their struct tags by their indices. They are read once for each type, so
+// that the repeated dumps of the same types don't walk their fields again.
+func typeFields(t reflect.Type) []fieldInfo {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+	fields := make([]fieldInfo, t.NumField())
+	for i := range fields {
+		ft := t.Field(i)
+		fields[i] = fieldInfo{ft: ft, tag: parseTag(ft), refs: hasRefs(ft.Type)}
+	}
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]fieldInfo)
+}
+
+// hasRenderer returns true if the type t or the pointer to t implements any
+// interface rendering the values by its method.
+func hasRenderer(t reflect.Type) bool {
+	if has, ok := rendererCache.Load(t); ok {
+		return has.(bool)
+	}
+	has := false
+	pt := reflect.PtrTo(t)
+	for _, it := range rendererTypes {
+		if t.Implements(it) || pt.Implements(it) {
+			has = true
+			break
+		}
+	}
+	rendererCache.Store(t, has)
+	return has
+}
+
+// newFieldRules returns the empty fieldRules of o.
+func newFieldRules(o Options) *fieldRules {
+	r := &fieldRules{}
+	for _, exf := range o.ExcludedField {
+		if strings.Contains(exf, ".") {
+			r.paths = append(r.paths, strings.Split(exf, "."))
+		}
+	}
+	return r
+}
+
+// fieldRules returns the decisions of the options of s cached, which are
+// created for the dump if the options are not of a Dumper.
+func (s *dumpState) fieldRules() *fieldRules {
+	if s.rules == nil {
+		s.rules = newFieldRules(s.Options)
+	}
+	return s.rules
+}
+
+// fieldRule returns the decisions of the options on the field f made once
+// for the struct type declaring f.
+func (s *dumpState) fieldRule(f structField) fieldRule {
+	r := s.fieldRules()
+	i := f.ft.Index[len(f.ft.Index)-1]
+	if rules, ok := r.types.Load(f.owner); ok {
+		return rules.([]fieldRule)[i]
+	}
+	infos := typeFields(f.owner)
+	rules := make([]fieldRule, len(infos))
+	for n, info := range infos {
+		name := info.ft.Name
+		if info.tag.name != "" {
+			name = info.tag.name
+		}
+		rules[n] = fieldRule{
+			omitted: info.tag.skip || s.isOmittedName(info.ft.Name) || s.isOmittedName(name) ||
+				s.isExcludedType(info.ft.Type),
+			redacted: info.tag.redact || s.isRedactedName(info.ft.Name),
+		}
+	}
+	cached, _ := r.types.LoadOrStore(f.owner, rules)
+	return cached.([]fieldRule)[i]
+}
+
+// hasRefs returns true if the values of the type t may hold a reference
+// found by refKeyOf.
+func hasRefs(t reflect.Type) bool {
+	if has, ok := refsCache.Load(t); ok {
+		return has.(bool)
+	}
+	has := false
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		has = true
+	case reflect.Array:
+		has = t.Len() > 0 && hasRefs(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField() && !has; i++ {
+			has = hasRefs(t.Field(i).Type)
+		}
+	}
+	refsCache.Store(t, has)
+	return has
+}
@@ -0,0 +1,161 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type benchAddress struct {
+	Street string `gdump:"street"`
+	City   string
+	Zip    int `gdump:",omitempty"`
+}
+
+type benchUser struct {
+	ID       int
+	Name     string `gdump:"name"`
+	Email    string
+	Password string `gdump:"-"`
+	Created  time.Time
+	Tags     []string
+	Home     benchAddress
+	Work     *benchAddress
+	Attrs    map[string]int
+}
+
+type benchEvent struct {
+	Kind  string
+	Users []benchUser
+	Meta  map[string]benchAddress
+}
+
+func benchValue() benchEvent {
+	addr := benchAddress{"street", "city", 12345}
+	users := make([]benchUser, 20)
+	for i := range users {
+		users[i] = benchUser{ID: i, Name: "user", Email: "user@example.com", Password: "secret",
+			Tags: []string{"a", "b"}, Home: addr, Work: &addr, Attrs: map[string]int{"x": i}}
+	}
+	return benchEvent{Kind: "login", Users: users, Meta: map[string]benchAddress{"a": addr, "b": addr}}
+}
+
+// clearCaches drops the metadata cached per type, i.e. the dumps before
+// the caches were added.
+func clearCaches() {
+	for _, m := range []*sync.Map{&fieldCache, &rendererCache, &refsCache} {
+		m.Range(func(k, _ interface{}) bool {
+			m.Delete(k)
+			return true
+		})
+	}
+}
+
+func BenchmarkDumpStructs(b *testing.B) {
+	v := benchValue()
+	for _, bm := range []struct {
+		name   string
+		cached bool
+	}{
+		{"uncached", false},
+		{"cached", true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			d := New(WithDepth(5))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !bm.cached {
+					clearCaches()
+					d = New(WithDepth(5))
+				}
+				d.Sdump(v)
+			}
+		})
+	}
+}
+
+func BenchmarkTypeFields(b *testing.B) {
+	t := reflect.TypeOf(benchUser{})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fieldCache.Delete(t)
+			typeFields(t)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			typeFields(t)
+		}
+	})
+}
+
+func TestTypeFields(t *testing.T) {
+	fields := typeFields(reflect.TypeOf(benchUser{}))
+	tests := []struct {
+		index int
+		name  string
+		tag   string
+		skip  bool
+	}{
+		{0, "ID", "", false},
+		{1, "Name", "name", false},
+		{3, "Password", "", true},
+		{6, "Home", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fields[tt.index]
+			if f.ft.Name != tt.name || f.tag.name != tt.tag || f.tag.skip != tt.skip {
+				t.Errorf("typeFields[%d] = %s %+v, want %s name %q skip %v", tt.index, f.ft.Name, f.tag, tt.name, tt.tag, tt.skip)
+			}
+		})
+	}
+	if again := typeFields(reflect.TypeOf(benchUser{})); &again[0] != &fields[0] {
+		t.Error("typeFields walked the cached type again")
+	}
+	if !hasRenderer(reflect.TypeOf(time.Time{})) || hasRenderer(reflect.TypeOf(benchUser{})) {
+		t.Error("hasRenderer of time.Time and benchUser")
+	}
+}
+
+func TestFieldRules(t *testing.T) {
+	d := New(WithDepth(3), WithExcludedFields("Email", "Home.City"), WithExcludedTypes(reflect.TypeOf(time.Time{})))
+	s := &dumpState{Options: d.opts}
+	infos := typeFields(reflect.TypeOf(benchUser{}))
+	tests := []struct {
+		index    int
+		omitted  bool
+		redacted bool
+	}{
+		{0, false, false}, // ID
+		{2, true, false},  // Email excluded by name
+		{3, true, true},   // Password skipped by its tag and redacted by its name
+		{4, true, false},  // Created excluded by type
+		{6, false, false}, // Home, whose City is excluded by its path
+	}
+	for _, tt := range tests {
+		f := structField{owner: reflect.TypeOf(benchUser{}), fieldInfo: &infos[tt.index]}
+		if got := s.fieldRule(f); got.omitted != tt.omitted || got.redacted != tt.redacted {
+			t.Errorf("fieldRule(%s) = %+v, want omitted %v redacted %v", f.ft.Name, got, tt.omitted, tt.redacted)
+		}
+	}
+	if _, ok := d.opts.rules.types.Load(reflect.TypeOf(benchUser{})); !ok {
+		t.Error("fieldRule not cached for the dumps of the Dumper")
+	}
+	if len(d.opts.rules.paths) != 1 {
+		t.Errorf("paths = %q, want [[Home City]]", d.opts.rules.paths)
+	}
+	if d.Options().rules != nil {
+		t.Error("Options shares the decisions of the Dumper")
+	}
+	// the options changed from the copy are not decided by the Dumper.
+	o := d.Options()
+	o.ExcludedField = nil
+	if out := Sdump(benchUser{Email: "e@x"}, o); !strings.Contains(out, "e@x") {
+		t.Errorf("Email excluded by the options changed:\n%s", out)
+	}
+}
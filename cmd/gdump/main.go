@@ -0,0 +1,207 @@
+// Command gdump reads a JSON or YAML document from a file or stdin and
+// prints it in the nested format of the gdump package.
+//
+//	curl -s https://api.example.com/users | gdump --depth 4 --exclude password
+//
+// The flags are:
+//
+//	--depth N         the print depth of the document (default 10)
+//	--exclude a,b     the keys not printed at any level, or at the dotted paths
+//	                  from the top like users.*.token, where * matches any key
+//	                  or index and ** any number of them
+//	--format F        text, json, yaml, go, spew or html (default text)
+//	--color WHEN      auto, always or never (default auto)
+//	--input F         json, yaml or auto to guess by the file name or content
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/neoul/gdump"
+)
+
+// formats - the output formats by their names
+var formats = map[string]gdump.Format{
+	"text": gdump.FormatText,
+	"json": gdump.FormatJSON,
+	"yaml": gdump.FormatYAML,
+	"go":   gdump.FormatGo,
+	"spew": gdump.FormatSpewCompat,
+	"html": gdump.FormatHTML,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gdump:", err)
+		os.Exit(1)
+	}
+}
+
+// run dumps the document read from the file named in args or stdin to w.
+func run(args []string, stdin io.Reader, w io.Writer) error {
+	fs := flag.NewFlagSet("gdump", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gdump [flags] [file]")
+		fs.PrintDefaults()
+	}
+	depth := fs.Int("depth", 10, "the print depth of the document")
+	exclude := fs.String("exclude", "", "the comma-separated keys or dotted paths not printed")
+	format := fs.String("format", "text", "the output format: text, json, yaml, go, spew or html")
+	color := fs.String("color", "auto", "colors the output: auto, always or never")
+	input := fs.String("input", "auto", "the input format: json, yaml or auto")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 1 {
+		return errors.New("too many files")
+	}
+
+	out, ok := formats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	opts := []gdump.Option{gdump.WithDepth(*depth), gdump.WithFormat(out)}
+	switch *color {
+	case "auto":
+	case "always":
+		opts = append(opts, gdump.WithColor(true))
+	case "never":
+		opts = append(opts, gdump.WithColor(false))
+	default:
+		return fmt.Errorf("unknown color %q", *color)
+	}
+
+	name := ""
+	r := stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		name = fs.Arg(0)
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	doc, err := decode(data, inputFormat(*input, name, data))
+	if err != nil {
+		return err
+	}
+	if *exclude != "" {
+		var excluded [][]string
+		for _, e := range strings.Split(*exclude, ",") {
+			excluded = append(excluded, strings.Split(e, "."))
+		}
+		prune(doc, nil, excluded)
+	}
+	_, err = gdump.New(append(opts, gdump.WithWriter(w))...).Dump(doc)
+	return err
+}
+
+// inputFormat returns the format of the input data read from the file name
+// if input is auto, i.e. yaml for .yaml and .yml files, json for .json
+// files, and json for the other data starting with { or [.
+func inputFormat(input, name string, data []byte) string {
+	if input != "auto" {
+		return input
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "json"
+	}
+	return "yaml"
+}
+
+// decode returns the document of data in the input format.
+func decode(data []byte, input string) (interface{}, error) {
+	switch input {
+	case "json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		var doc interface{}
+		if err := d.Decode(&doc); err != nil {
+			return nil, err
+		}
+		return numbers(doc), nil
+	case "yaml":
+		return parseYAML(data)
+	}
+	return nil, fmt.Errorf("unknown input %q", input)
+}
+
+// numbers returns the JSON document doc with the numbers converted to
+// int64 if they are integers and to float64 otherwise.
+func numbers(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = numbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = numbers(e)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return doc
+}
+
+// prune deletes the keys excluded from the maps in doc at path. The
+// excluded paths of a segment are the keys at any level and the others are
+// the paths from the top, in which * matches any key or index and ** any
+// number of them. Unlike gdump.Options.ExcludedField printing the keys
+// excluded without their values, the keys are not printed at all.
+func prune(doc interface{}, path []string, excluded [][]string) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			p := append(path[:len(path):len(path)], k)
+			if isExcluded(p, excluded) {
+				delete(v, k)
+				continue
+			}
+			prune(e, p, excluded)
+		}
+	case []interface{}:
+		for i, e := range v {
+			prune(e, append(path[:len(path):len(path)], strconv.Itoa(i)), excluded)
+		}
+	}
+}
+
+// isExcluded returns true if the key at path is excluded.
+func isExcluded(path []string, excluded [][]string) bool {
+	for _, pattern := range excluded {
+		if len(pattern) == 1 && pattern[0] == path[len(path)-1] || len(pattern) > 1 && gdump.MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	const users = `{"users": [{"name": "alice", "token": "t1"}, {"name": "bob", "token": "t2"}], "token": "top", "count": 2}`
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		want  []string
+		not   []string
+	}{
+		{"text", nil, users, []string{"name:○string{&alice}", "count:○int64{&2}"}, nil},
+		{"exclude path", []string{"--exclude", "users.*.token"}, users,
+			[]string{"name:○string{&alice}", "token:string{"}, []string{"t1", "t2", "• • • token:"}},
+		{"exclude key", []string{"--exclude", "token,count"}, users,
+			[]string{"name:○string{&bob}"}, []string{"token", "count"}},
+		{"exclude any depth", []string{"--exclude", "**.name"}, users,
+			[]string{"count:○int64{&2}"}, []string{"name:"}},
+		{"depth", []string{"--depth", "1"}, users, []string{"users:"}, []string{"alice"}},
+		{"json", []string{"--format", "json"}, users, []string{`"name": "alice"`}, nil},
+		{"yaml input", []string{"--input", "yaml"}, "a:\n  b: 1", []string{"b:○int64{&1}"}, nil},
+		{"yaml guessed", nil, "- x\n- y", []string{"○string{&x}", "○string{&y}"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := run(append(tt.args, "--color", "never"), strings.NewReader(tt.input), &b); err != nil {
+				t.Fatal(err)
+			}
+			got := b.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("run =\n%s\nwant it containing %q", got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("run =\n%s\nwant it not containing %q", got, not)
+				}
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"format", []string{"--format", "xml"}},
+		{"color", []string{"--color", "sometimes"}},
+		{"input", []string{"--input", "toml"}},
+		{"files", []string{"a", "b"}},
+		{"missing file", []string{"/nonexistent/doc.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := run(tt.args, strings.NewReader("{}"), &strings.Builder{}); err == nil {
+				t.Error("run succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestInputFormat(t *testing.T) {
+	tests := []struct {
+		input, name, data, want string
+	}{
+		{"yaml", "a.json", "{}", "yaml"},
+		{"auto", "a.JSON", "a: 1", "json"},
+		{"auto", "a.yml", "{}", "yaml"},
+		{"auto", "", "  [1]", "json"},
+		{"auto", "", "a: 1", "yaml"},
+	}
+	for _, tt := range tests {
+		if got := inputFormat(tt.input, tt.name, []byte(tt.data)); got != tt.want {
+			t.Errorf("inputFormat(%q, %q, %q) = %q, want %q", tt.input, tt.name, tt.data, got, tt.want)
+		}
+	}
+}
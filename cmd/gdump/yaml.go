@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlParser - the parser of the subset of YAML used by the configuration
+// files and API responses, as the module has no YAML decoder: the block
+// mappings and sequences, the plain and quoted scalars, the literal and
+// folded block scalars, and the flow collections written in JSON, e.g.
+// [1, "a"] but not [a, b]. The anchors, aliases, tags and streams of
+// multiple documents are rejected by an error rather than misread.
+type yamlParser struct {
+	lines []string
+	i     int // the index of the next line
+}
+
+// parseYAML returns the YAML document of data. It fails if data has more
+// than one document.
+func parseYAML(data []byte) (interface{}, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	if _, text, ok := p.peek(); ok && text == "---" {
+		p.i++
+	}
+	doc, err := p.node(0)
+	if err != nil {
+		return nil, err
+	}
+	indent, text, ok := p.peek()
+	if !ok {
+		return doc, nil
+	}
+	if text != "---" && text != "..." {
+		return nil, fmt.Errorf("yaml: line %d: unexpected indentation %d of %q", p.i+1, indent, text)
+	}
+	line := p.i + 1
+	p.i++
+	if _, _, ok := p.peek(); ok {
+		return nil, fmt.Errorf("yaml: line %d: multiple documents are not supported", line)
+	}
+	return doc, nil
+}
+
+// peek returns the indentation and the text without the comment of the next
+// line having any content. It skips the blank and comment lines.
+func (p *yamlParser) peek() (int, string, bool) {
+	for ; p.i < len(p.lines); p.i++ {
+		line := p.lines[p.i]
+		text := strings.TrimSpace(stripComment(line))
+		if text == "" {
+			continue
+		}
+		return len(line) - len(strings.TrimLeft(line, " ")), text, true
+	}
+	return 0, "", false
+}
+
+// node returns the node of the next lines indented by min or more, or nil
+// if there is none.
+func (p *yamlParser) node(min int) (interface{}, error) {
+	indent, text, ok := p.peek()
+	if !ok || indent < min {
+		return nil, nil
+	}
+	switch {
+	case isSequenceItem(text):
+		return p.sequence(indent)
+	case isMappingEntry(text):
+		return p.mapping(indent)
+	}
+	p.i++
+	return scalar(text)
+}
+
+// sequence returns the block sequence of the items indented by indent.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for {
+		i, text, ok := p.peek()
+		if !ok || i != indent || !isSequenceItem(text) {
+			return items, nil
+		}
+		// the item is read as the node indented after the marker.
+		line := p.lines[p.i]
+		p.lines[p.i] = line[:indent] + " " + line[indent+1:]
+		item, err := p.node(indent + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// mapping returns the block mapping of the entries indented by indent.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	entries := map[string]interface{}{}
+	for {
+		i, text, ok := p.peek()
+		if !ok || i != indent || !isMappingEntry(text) {
+			return entries, nil
+		}
+		key, rest := splitEntry(text)
+		k, err := scalar(key)
+		if err != nil {
+			return nil, err
+		}
+		p.i++
+		var value interface{}
+		switch {
+		case rest == "":
+			if i, text, ok := p.peek(); ok && i == indent && isSequenceItem(text) {
+				value, err = p.sequence(indent)
+			} else {
+				value, err = p.node(indent + 1)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			value = p.blockScalar(indent, rest)
+		default:
+			value, err = scalar(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries[fmt.Sprint(k)] = value
+	}
+}
+
+// blockScalar returns the literal (|) or folded (>) block scalar of the lines
+// indented more than indent. The header - strips the final newline.
+func (p *yamlParser) blockScalar(indent int, header string) string {
+	var lines []string
+	block := -1
+	for ; p.i < len(p.lines); p.i++ {
+		line := p.lines[p.i]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		i := len(line) - len(strings.TrimLeft(line, " "))
+		if i <= indent {
+			break
+		}
+		if block < 0 {
+			block = i
+		}
+		if i < block {
+			break
+		}
+		lines = append(lines, line[block:])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	sep := "\n"
+	if header[0] == '>' {
+		sep = " "
+	}
+	var b strings.Builder
+	for n, line := range lines {
+		switch {
+		case n == 0:
+		case line == "" || sep == "\n":
+			b.WriteString("\n")
+		case lines[n-1] != "":
+			b.WriteString(sep)
+		}
+		b.WriteString(line)
+	}
+	if !strings.Contains(header, "-") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// isSequenceItem returns true if text is an item of a block sequence.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry returns true if text is an entry of a block mapping.
+func isMappingEntry(text string) bool {
+	key, _ := splitEntry(text)
+	return key != ""
+}
+
+// splitEntry returns the key and the value of the mapping entry text, or an
+// empty key if text is not an entry. The colon of the entry is not quoted
+// and is followed by a space or the end of text.
+func splitEntry(text string) (string, string) {
+	if text[0] == '[' || text[0] == '{' {
+		return "", ""
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		}
+	}
+	return "", ""
+}
+
+// stripComment returns line without the comment starting by # at the start
+// or after a space outside the quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// scalar returns the value of the scalar or the flow collection text.
+func scalar(text string) (interface{}, error) {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	switch text[0] {
+	case '&', '*', '!':
+		return nil, fmt.Errorf("yaml: anchors, aliases and tags are not supported: %s", text)
+	case '"':
+		return strconv.Unquote(text)
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("yaml: unterminated string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[', '{':
+		v, err := decode([]byte(text), "json")
+		if err != nil {
+			return nil, fmt.Errorf("yaml: only the flow collections in JSON are supported: %s", text)
+		}
+		return v, nil
+	}
+	if !strings.ContainsRune("0123456789+-.", rune(text[0])) {
+		return text, nil
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
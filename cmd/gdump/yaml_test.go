@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want interface{}
+	}{
+		{"scalars", "s: text\ni: 12\nf: 1.5\nb: true\nn: ~\nq: \"a: b\"\nsq: 'it''s'\nv: 1.2.3",
+			map[string]interface{}{"s": "text", "i": int64(12), "f": 1.5, "b": true, "n": nil, "q": "a: b", "sq": "it's", "v": "1.2.3"}},
+		{"nested", "user:\n  name: alice\n  tags:\n    - a\n    - b",
+			map[string]interface{}{"user": map[string]interface{}{"name": "alice", "tags": []interface{}{"a", "b"}}}},
+		{"sequence at key indent", "tags:\n- a\n- b",
+			map[string]interface{}{"tags": []interface{}{"a", "b"}}},
+		{"sequence of mappings", "- name: a\n  id: 1\n- name: b\n  id: 2",
+			[]interface{}{map[string]interface{}{"name": "a", "id": int64(1)}, map[string]interface{}{"name": "b", "id": int64(2)}}},
+		{"comments", "# head\na: 1 # one\n\nb: \"#2\"\n",
+			map[string]interface{}{"a": int64(1), "b": "#2"}},
+		{"document marker", "---\na: 1\n...",
+			map[string]interface{}{"a": int64(1)}},
+		{"document marker at end", "a: 1\n---\n# end\n",
+			map[string]interface{}{"a": int64(1)}},
+		{"literal", "text: |\n  line 1\n  line 2\nnext: x",
+			map[string]interface{}{"text": "line 1\nline 2\n", "next": "x"}},
+		{"folded strip", "text: >-\n  a\n  b\n\n  c",
+			map[string]interface{}{"text": "a b\nc"}},
+		{"flow", "list: [1, 2]\nmap: {\"a\": \"b\"}",
+			map[string]interface{}{"list": []interface{}{int64(1), int64(2)}, "map": map[string]interface{}{"a": "b"}}},
+		{"empty", "", nil},
+		{"scalar", "hello", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"indentation", "a:\n    b: 1\n  c: 2"},
+		{"unterminated", "a: 'b"},
+		{"flow", "a: [b, c]"},
+		{"quoted", "a: \"b"},
+		{"documents", "a: 1\n---\nb: 2"},
+		{"documents after end", "a: 1\n...\n---\nb: 2"},
+		{"anchor", "base: &base\n  a: 1\nderived: *base"},
+		{"alias", "a: *base"},
+		{"tag", "a: !!str 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if doc, err := parseYAML([]byte(tt.in)); err == nil {
+				t.Errorf("parseYAML = %#v, want an error", doc)
+			}
+		})
+	}
+}
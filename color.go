@@ -0,0 +1,33 @@
+package gdump
+
+import (
+	"io"
+	"os"
+)
+
+// the ANSI escape codes of Options.Color
+const (
+	ansiType  = "\x1b[36m" // cyan
+	ansiName  = "\x1b[33m" // yellow
+	ansiValue = "\x1b[32m" // green
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// paint returns str colored by the ANSI escape code if Options.Color is set.
+func (s *dumpState) paint(code, str string) string {
+	if !s.Color || str == "" {
+		return str
+	}
+	return code + str + ansiReset
+}
+
+// isTerminal returns true if w is a terminal, i.e. a character device file.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
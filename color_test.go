@@ -0,0 +1,48 @@
+package gdump
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+)
+
+type colorRecord struct {
+	Name string
+	N    int
+	P    *int
+	M    map[string]int
+}
+
+// TestColor paints the types, the names and keys and the values, the nil
+// and zero values dimmed, and leaves the other formats and writers plain.
+func TestColor(t *testing.T) {
+	v := colorRecord{Name: "a", M: map[string]int{"k": 2}}
+	typ := func(s string) string { return ansiType + s + ansiReset }
+	name := func(s string) string { return ansiName + s + ansiReset }
+	want := typ("gdump.colorRecord") + "{" +
+		"\n• " + name("Name") + ":" + typ("string") + "{" + ansiValue + "a" + ansiReset + "}" +
+		"\n• " + name("N") + ":" + typ("int") + "{" + ansiDim + "0" + ansiReset + "}" +
+		"\n• " + name("P") + ":" + typ("*int") + "{" + ansiDim + "nil" + ansiReset + "}" +
+		"\n• " + name("M") + ":" + typ("map[string]int") + "{" +
+		"\n• • " + name("k") + ":" + typ("int") + "{" + ansiValue + "2" + ansiReset + "}}}"
+	got := Sdump(v, Options{Depth: 2, Color: true})
+	if got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if plain := regexp.MustCompile("\x1b\\[[0-9]+m").ReplaceAllString(got, ""); plain != Sdump(v, Options{Depth: 2}) {
+		t.Errorf("Sdump uncolored = %q, want %q", plain, Sdump(v, Options{Depth: 2}))
+	}
+	if got, want := Sdump(v, Options{Depth: 2, Color: true, Format: FormatJSON}), Sdump(v, Options{Depth: 2, Format: FormatJSON}); got != want {
+		t.Errorf("Sdump = %q, want %q uncolored", got, want)
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) || isTerminal(&bytes.Buffer{}) {
+		t.Error("isTerminal = true for a file or a buffer, want false")
+	}
+}
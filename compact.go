@@ -0,0 +1,49 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isPrimitiveKind returns true if the values of kind k have no nested value.
+func isPrimitiveKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// compactElements returns the elements of the slice or array v of the
+// primitive values printed in a line, e.g. 1, 2, 3, if CompactPrimitiveSlices
+// is set and the elements are within depth. The strings with newlines are
+// quoted if noIndent is set.
+func (s *dumpState) compactElements(v reflect.Value, depth, level int, noIndent bool) (string, bool) {
+	if !s.CompactPrimitiveSlices || depth <= 0 || !isPrimitiveKind(v.Type().Elem().Kind()) {
+		return "", false
+	}
+	indices, elided := s.itemIndices(level, v.Len())
+	items := make([]string, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 {
+			items = append(items, fmt.Sprintf("…(+%d more)", elided))
+			continue
+		}
+		e := v.Index(i)
+		value, ok := enumString(e)
+		if !ok {
+			value = s.format(e)
+			if e.Kind() == reflect.String {
+				value = s.stringValue(value, "", true)
+			}
+		}
+		items = append(items, value)
+	}
+	value := strings.Join(items, ", ")
+	s.setNode(v, value)
+	return value, true
+}
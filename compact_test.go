@@ -0,0 +1,44 @@
+package gdump
+
+import "testing"
+
+type compactLevel int
+
+// TestCompactPrimitiveSlices prints the slices and arrays of the primitive
+// kinds in a line, quoting the unsafe strings, and the composite elements
+// in lines.
+func TestCompactPrimitiveSlices(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"ints", []int{1, 2, 3}, "[]int{1, 2, 3}"},
+		{"strings", []string{"a", "a\nb"}, `[]string{a, "a\nb"}`},
+		{"floats", []float64{1.5, 2}, "[]float64{1.5, 2}"},
+		{"bools", []bool{true, false}, "[]bool{true, false}"},
+		{"named", []compactLevel{1, 2}, "[]gdump.compactLevel{1, 2}"},
+		{"array", [3]int{1, 2, 3}, "[3]int{1, 2, 3}"},
+		{"empty", []int{}, "[]int{}"},
+		{"nested", [][]int{{1}, {2, 3}}, "[][]int{\n• []int{1}\n• []int{2, 3}}"},
+		{"interfaces", []interface{}{1}, "[]interface {}{\n• ○int{&1}}"},
+		{"pointers", []*int{nil}, "[]*int{\n• *int{nil}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 3, CompactPrimitiveSlices: true}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := Sdump([]int{1, 2, 3, 4, 5}, Options{Depth: 3, CompactPrimitiveSlices: true, MaxItems: 2}), "[]int{1, 2, …(+3 more)}"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if got, want := Sdump([]int{1, 2}, Options{Depth: 3}), "[]int{\n• int{1}\n• int{2}}"; got != want {
+		t.Errorf("Sdump = %q, want %q when disabled", got, want)
+	}
+	if got, want := ValueDump([]int{1, 2}, 3, nil), "[]int{1, 2}\n"; got != want {
+		t.Errorf("ValueDump = %q, want %q by default", got, want)
+	}
+}
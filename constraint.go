@@ -0,0 +1,41 @@
+package gdump
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	constraintMutex sync.RWMutex
+	constraints     = map[reflect.Type]map[string]string{}
+)
+
+// RegisterConstraint registers the constraint of the type parameter declared
+// for the field of the struct type t. Type parameters are not available at
+// runtime, so it is printed only when registered and Options.ShowConstraints
+// is enabled, e.g. Value:any<Comparable>○int{5}.
+func RegisterConstraint(t reflect.Type, field string, constraint string) {
+	constraintMutex.Lock()
+	defer constraintMutex.Unlock()
+	t = getBaseType(t)
+	if constraints[t] == nil {
+		constraints[t] = map[string]string{}
+	}
+	constraints[t][field] = constraint
+}
+
+// constraintString returns the declared type and the registered constraint
+// of the struct field ft of t or an empty string if not registered.
+func constraintString(t reflect.Type, ft reflect.StructField) string {
+	constraintMutex.RLock()
+	defer constraintMutex.RUnlock()
+	c, ok := constraints[t][ft.Name]
+	if !ok {
+		return ""
+	}
+	declared := ft.Type.String()
+	if ft.Type.Kind() == reflect.Interface && ft.Type.NumMethod() == 0 {
+		declared = "any"
+	}
+	return declared + "<" + c + ">"
+}
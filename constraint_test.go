@@ -0,0 +1,39 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type constrainedBox[T comparable] struct {
+	Value T
+	Any   interface{}
+	N     int
+}
+
+// TestShowConstraints prints the constraints registered for an instance of
+// a generic type, by the type or the pointer to it, and not for the other
+// instances.
+func TestShowConstraints(t *testing.T) {
+	RegisterConstraint(reflect.TypeOf(constrainedBox[int]{}), "Value", "comparable")
+	RegisterConstraint(reflect.TypeOf(&constrainedBox[int]{}), "Any", "Stringer")
+	v := constrainedBox[int]{Value: 1, Any: "s"}
+	tests := []struct {
+		name  string
+		value interface{}
+		show  bool
+		want  string
+	}{
+		{"value", v, true, "gdump.constrainedBox[int]{\n• Value:int<comparable>int{1}\n• Any:any<Stringer>○string{&s}\n• N:int{0}}"},
+		{"pointer", &v, true, "*gdump.constrainedBox[int]{\n• Value:int<comparable>int{1}\n• Any:any<Stringer>○string{&s}\n• N:int{0}}"},
+		{"other instance", constrainedBox[string]{Value: "x"}, true, "gdump.constrainedBox[string]{\n• Value:string{x}\n• Any:○nil\n• N:int{0}}"},
+		{"not shown", v, false, "gdump.constrainedBox[int]{\n• Value:int{1}\n• Any:○string{&s}\n• N:int{0}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 3, ShowConstraints: tt.show}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package gdump
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// DumpContext writes the string representation of value dumped like Fdump
+// in DefaultPrintDepth to w. The text dump is streamed to w while it is
+// dumped, and the values not dumped yet when ctx is done are printed as
+// …(canceled), so that a huge value can be interrupted. The dump also stops
+// at MaxOutputBytes with the mark of the bytes cut off. It returns the
+// number of bytes written and the write error or ctx.Err() if ctx is done
+// before the dump ends.
+func DumpContext(ctx context.Context, w io.Writer, value interface{}) (int, error) {
+	return dumpContext(ctx, w, value, globalOptions(DefaultPrintDepth))
+}
+
+// DumpContext writes the string representation of value dumped with the
+// options of d to w like the package-level DumpContext.
+func (d *Dumper) DumpContext(ctx context.Context, w io.Writer, value interface{}) (int, error) {
+	return dumpContext(ctx, w, value, d.optionsFor(w))
+}
+
+// dumpContext writes value dumped with opts until ctx is done to w.
+func dumpContext(ctx context.Context, w io.Writer, value interface{}, opts Options) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := opts.dumpTo(ctx, w, value)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return n, err
+}
+
+// clip returns the output out cut at Options.MaxOutputBytes with the mark
+// of the bytes cut off.
+func (s *dumpState) clip(out string) string {
+	if s.MaxOutputBytes <= 0 || len(out) <= s.MaxOutputBytes {
+		return out
+	}
+	cut := s.MaxOutputBytes
+	for cut > 0 && !utf8.RuneStart(out[cut]) {
+		cut--
+	}
+	return out[:cut] + fmt.Sprintf("\n…(truncated at %d bytes)", s.MaxOutputBytes)
+}
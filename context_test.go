@@ -0,0 +1,64 @@
+package gdump
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// cancelingStringer cancels the dump when it is dumped.
+type cancelingStringer struct{ cancel context.CancelFunc }
+
+func (c cancelingStringer) String() string {
+	c.cancel()
+	return "cancel"
+}
+
+func TestDumpContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	var b bytes.Buffer
+	if n, err := DumpContext(canceled, &b, 1); n != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("DumpContext(canceled) = %d, %v, want 0, context.Canceled", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v := make([]interface{}, 1000)
+	v[0] = cancelingStringer{cancel}
+	for i := 1; i < len(v); i++ {
+		v[i] = i
+	}
+	b.Reset()
+	n, err := New(WithDepth(2), WithStringer(true)).DumpContext(ctx, &b, v)
+	if !errors.Is(err, context.Canceled) || n != b.Len() {
+		t.Errorf("DumpContext = %d, %v, want %d, context.Canceled", n, err, b.Len())
+	}
+	if !strings.Contains(b.String(), canceledMarker) || strings.Contains(b.String(), "int{999}") {
+		t.Errorf("DumpContext not stopped at the cancel:\n%s", b.String())
+	}
+}
+
+func TestDumpContextMaxOutputBytes(t *testing.T) {
+	calls := 0
+	v := make([]countingStringer, 10000)
+	for i := range v {
+		v[i] = countingStringer{&calls}
+	}
+	var b bytes.Buffer
+	d := New(WithDepth(2), WithStringer(true), WithMaxOutputBytes(1000))
+	if _, err := d.DumpContext(context.Background(), &b, v); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(b.String(), "…(truncated at 1000 bytes)\n") {
+		t.Errorf("DumpContext not cut at 1000 bytes:\n%s", b.String())
+	}
+	if calls >= len(v) {
+		t.Errorf("DumpContext dumped all %d values beyond MaxOutputBytes", calls)
+	}
+	if want := d.Sdump(v); b.String() != want {
+		t.Errorf("DumpContext =\n%s\nwant\n%s", b.String(), want)
+	}
+}
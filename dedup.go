@@ -0,0 +1,37 @@
+package gdump
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+)
+
+// dedup returns the back-reference to the path of the subtree dumped before
+// that is rendered equal to v if DedupEqualSubtrees is set. Otherwise, it
+// records the hash of the rendered v and returns false. Only the values
+// having nested values are deduplicated.
+func (s *dumpState) dedup(v reflect.Value, depth, level int) (string, bool) {
+	if !s.DedupEqualSubtrees || !v.IsValid() || depth < 0 {
+		return "", false
+	}
+	sub := &dumpState{Options: s.Options, path: append([]string(nil), s.path...)}
+	sub.DedupEqualSubtrees = false
+	rendered := sub.valueString(v, depth, level, 0, "", true, true)
+	if strings.Count(rendered, "{") < 2 {
+		return "", false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(rendered))
+	sum := h.Sum64()
+	if path, ok := s.subtrees[sum]; ok {
+		ref := "(same as above: " + path + ")"
+		s.setNode(v, ref)
+		return fmt.Sprintf("%s{%s}", v.Type(), ref), true
+	}
+	if s.subtrees == nil {
+		s.subtrees = map[uint64]string{}
+	}
+	s.subtrees[sum] = strings.Join(s.path, ".")
+	return "", false
+}
@@ -0,0 +1,45 @@
+package gdump
+
+import "testing"
+
+type dedupTemplate struct {
+	Port int
+	Tags []string
+}
+
+type dedupConfig struct {
+	Web, API, Other dedupTemplate
+}
+
+// TestDedupEqualSubtrees refers back to the first of the equal subtrees at
+// different addresses by its path, the nested ones included, and prints the
+// leaves and the other subtrees in full.
+func TestDedupEqualSubtrees(t *testing.T) {
+	web := dedupTemplate{80, []string{"a"}}
+	v := dedupConfig{web, dedupTemplate{80, []string{"a"}}, dedupTemplate{81, []string{"a"}}}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"fields", v, Options{Depth: 4},
+			"gdump.dedupConfig{\n• Web:gdump.dedupTemplate{\n• • Port:int{80}\n• • Tags:[]string{\n• • • string{a}}}\n" +
+				"• API:gdump.dedupTemplate{(same as above: Web)}\n" +
+				"• Other:gdump.dedupTemplate{\n• • Port:int{81}\n• • Tags:[]string{(same as above: Web.Tags)}}}"},
+		{"pointers", []*dedupTemplate{&web, {80, []string{"a"}}}, Options{Depth: 4},
+			"[]*gdump.dedupTemplate{\n• *gdump.dedupTemplate{\n• • Port:int{80}\n• • Tags:[]string{\n• • • string{a}}}\n• *gdump.dedupTemplate{(same as above: 0)}}"},
+		{"leaves", []int{1, 1}, Options{Depth: 4}, "[]int{\n• int{1}\n• int{1}}"},
+		{"json", v, Options{Depth: 4, Format: FormatJSON},
+			"{\n  \"Web\": {\n    \"Port\": 80,\n    \"Tags\": [\n      \"a\"\n    ]\n  },\n  \"API\": \"(same as above: Web)\",\n" +
+				"  \"Other\": {\n    \"Port\": 81,\n    \"Tags\": \"(same as above: Web.Tags)\"\n  }\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.DedupEqualSubtrees = true
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValueDiff returns the differences between a and b walked in parallel up
+// to depth, one per line, e.g. "- A: int{1}" for a and "+ A: int{2}" for b.
+// The values are dumped in a line like ValueDumpInline. The elements and
+// entries present in only one side are printed with - or + alone, and the
+// runs of the equal fields, elements and entries between the differences
+// are collapsed to … after the path of their parent, e.g. Items.…. It
+// returns an empty string if a and b are equal.
+func ValueDiff(a, b interface{}, depth int) string {
+	return diffString(a, b, globalOptions(depth))
+}
+
+// Diff returns the differences between a and b like ValueDiff in
+// DefaultPrintDepth.
+func Diff(a, b interface{}) string {
+	return ValueDiff(a, b, DefaultPrintDepth)
+}
+
+// Diff returns the differences between a and b like ValueDiff with the
+// options of d. The fields and entries not printed by Sdump are not
+// compared, and the redacted values changed are printed as ***REDACTED***.
+func (d *Dumper) Diff(a, b interface{}) string {
+	return diffString(a, b, d.optionsFor(nil))
+}
+
+// diffString returns the differences between a and b walked in opts.Depth.
+func diffString(a, b interface{}, opts Options) string {
+	d := &differ{opts: opts}
+	d.diff(reflect.ValueOf(a), reflect.ValueOf(b), nil, opts.Depth)
+	return strings.Join(d.lines, "\n")
+}
+
+// differ keeps the lines of the differences found.
+type differ struct {
+	opts  Options
+	lines []string
+}
+
+// line returns the line of the value v at path dumped in depth.
+func (d *differ) line(sign string, path []string, v reflect.Value, depth int) string {
+	if depth < 0 {
+		depth = 0
+	}
+	ds := &dumpState{Options: d.opts}
+	ds.Depth = depth
+	if len(path) == 0 {
+		return sign + " " + ds.inline(v)
+	}
+	return sign + " " + strings.Join(path, ".") + ": " + ds.inline(v)
+}
+
+// redactedLine returns the line of the value v at path redacted.
+func (d *differ) redactedLine(sign string, path []string, v reflect.Value) string {
+	ds := &dumpState{Options: d.opts}
+	ds.Depth = 0
+	return sign + " " + strings.Join(path, ".") + ": " + ds.redacted(v, 0)
+}
+
+// isOmittedField returns true if the struct field f at path is not compared
+// as it is not printed by the text walker.
+func (d *differ) isOmittedField(path []string, f structField) bool {
+	ds := &dumpState{Options: d.opts, path: path}
+	ft, tag := f.ft, f.tag
+	name := ft.Name
+	if tag.name != "" {
+		name = tag.name
+	}
+	if tag.skip || ds.isOmittedField(ft.Name) || ds.isOmittedField(name) || ds.isExcludedType(ft.Type) || ds.isFilteredField(f) {
+		return true
+	}
+	return ds.isHiddenPath(ft.Name, ft.Type) && ds.isHiddenPath(name, ft.Type)
+}
+
+// isRedactedField returns true if the values of the struct field f are
+// redacted.
+func (d *differ) isRedactedField(f structField) bool {
+	ds := &dumpState{Options: d.opts}
+	return f.tag.redact || ds.isRedactedName(f.ft.Name)
+}
+
+// isRedactedKey returns true if the values of the map entry keyed by k are
+// redacted.
+func (d *differ) isRedactedKey(k reflect.Value) bool {
+	ds := &dumpState{Options: d.opts}
+	return k.Kind() == reflect.String && ds.isRedactedName(k.String())
+}
+
+// isHiddenKey returns true if the entry keyed by k at path of the map type t
+// is not compared.
+func (d *differ) isHiddenKey(path []string, k reflect.Value, t reflect.Type) bool {
+	ds := &dumpState{Options: d.opts, path: path}
+	return k.Kind() == reflect.String && ds.isHiddenPath(k.String(), t.Elem())
+}
+
+// redactedChange appends the redacted lines of a and b at path if they
+// differ, not to print the secrets changed.
+func (d *differ) redactedChange(path []string, a, b reflect.Value) {
+	switch {
+	case !a.IsValid():
+		d.lines = append(d.lines, d.redactedLine("+", path, b))
+	case !b.IsValid():
+		d.lines = append(d.lines, d.redactedLine("-", path, a))
+	case !d.equal(a, b):
+		d.lines = append(d.lines, d.redactedLine("-", path, a), d.redactedLine("+", path, b))
+	}
+}
+
+func (d *differ) removed(path []string, v reflect.Value, depth int) {
+	d.lines = append(d.lines, d.line("-", path, v, depth))
+}
+
+func (d *differ) added(path []string, v reflect.Value, depth int) {
+	d.lines = append(d.lines, d.line("+", path, v, depth))
+}
+
+func (d *differ) changed(path []string, a, b reflect.Value, depth int) {
+	d.removed(path, a, depth)
+	d.added(path, b, depth)
+}
+
+// equal returns true if a and b are deeply equal like reflect.DeepEqual,
+// except that the funcs, chans and unsafe pointers are equal if they point
+// to the same code or value. The unexported fields are compared as well.
+func (d *differ) equal(a, b reflect.Value) bool {
+	return deepEqual(a, b, map[visitPair]bool{})
+}
+
+// visitPair - the references compared by deepEqual, not to walk the cycles
+type visitPair struct {
+	a, b uintptr
+	t    reflect.Type
+}
+
+func deepEqual(a, b reflect.Value, visited map[visitPair]bool) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		pair := visitPair{a.Pointer(), b.Pointer(), a.Type()}
+		if pair.a == pair.b && (a.Kind() != reflect.Slice || a.Len() == b.Len()) {
+			return true
+		}
+		if visited[pair] {
+			return true
+		}
+		visited[pair] = true
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return deepEqual(a.Elem(), b.Elem(), visited)
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !deepEqual(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() || !deepEqual(iter.Value(), bv, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !deepEqual(a.Field(i), b.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	}
+	return false
+}
+
+// readable returns the struct v addressable, copied if it is not, so that
+// its unexported fields are read by exposeField.
+func readable(v reflect.Value) reflect.Value {
+	if v.CanAddr() || !v.CanInterface() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// diff appends the differences between a and b at path.
+func (d *differ) diff(a, b reflect.Value, path []string, depth int) {
+	switch {
+	case !a.IsValid() || !b.IsValid():
+		if a.IsValid() != b.IsValid() {
+			d.changed(path, a, b, depth)
+		}
+		return
+	case a.Type() != b.Type():
+		d.changed(path, a, b, depth)
+		return
+	case depth < 0:
+		if !d.equal(a, b) {
+			d.changed(path, a, b, depth)
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.changed(path, a, b, depth)
+			}
+			return
+		}
+		if a.Kind() == reflect.Ptr && a.Pointer() == b.Pointer() {
+			return
+		}
+		d.diff(a.Elem(), b.Elem(), path, depth)
+	case reflect.Struct:
+		a, b = readable(a), readable(b)
+		c := d.collapser(path)
+		infos := typeFields(a.Type())
+		for i := range infos {
+			f := structField{owner: a.Type(), parent: a, fieldInfo: &infos[i], fv: a.Field(i)}
+			if d.isOmittedField(path, f) {
+				continue
+			}
+			name := f.ft.Name
+			if f.tag.name != "" {
+				name = f.tag.name
+			}
+			p := appendPath(path, name)
+			c.child(func() {
+				af, bf := exposeField(a.Field(i)), exposeField(b.Field(i))
+				if d.isRedactedField(f) {
+					d.redactedChange(p, af, bf)
+					return
+				}
+				d.diff(af, bf, p, depth-1)
+			})
+		}
+		c.end()
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && (a.IsNil() || b.IsNil()) && a.IsNil() != b.IsNil() {
+			d.changed(path, a, b, depth)
+			return
+		}
+		c := d.collapser(path)
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			p := appendPath(path, fmt.Sprint(i))
+			c.child(func() {
+				switch {
+				case i >= b.Len():
+					d.removed(p, a.Index(i), depth-1)
+				case i >= a.Len():
+					d.added(p, b.Index(i), depth-1)
+				default:
+					d.diff(a.Index(i), b.Index(i), p, depth-1)
+				}
+			})
+		}
+		c.end()
+	case reflect.Map:
+		if (a.IsNil() || b.IsNil()) && a.IsNil() != b.IsNil() {
+			d.changed(path, a, b, depth)
+			return
+		}
+		// the entries of b not found in a, e.g. the NaN keys, are added.
+		type pair struct{ key, a, b reflect.Value }
+		var pairs []pair
+		var keys []reflect.Value
+		ds := &dumpState{Options: d.opts}
+		for _, e := range ds.sortedEntries(a) {
+			pairs = append(pairs, pair{e.key, e.value, b.MapIndex(e.key)})
+			keys = append(keys, e.key)
+		}
+		for _, e := range ds.sortedEntries(b) {
+			if !a.MapIndex(e.key).IsValid() {
+				pairs = append(pairs, pair{e.key, reflect.Value{}, e.value})
+				keys = append(keys, e.key)
+			}
+		}
+		if order := ds.keyOrder(keys); order != nil {
+			sorted := make([]pair, len(pairs))
+			for i, o := range order {
+				sorted[i] = pairs[o]
+			}
+			pairs = sorted
+		}
+		c := d.collapser(path)
+		for _, e := range pairs {
+			if d.isHiddenKey(path, e.key, a.Type()) {
+				continue
+			}
+			p := appendPath(path, ds.keyString(e.key))
+			av, bv := e.a, e.b
+			c.child(func() {
+				switch {
+				case d.isRedactedKey(e.key):
+					d.redactedChange(p, av, bv)
+				case !bv.IsValid():
+					d.removed(p, av, depth-1)
+				case !av.IsValid():
+					d.added(p, bv, depth-1)
+				default:
+					d.diff(av, bv, p, depth-1)
+				}
+			})
+		}
+		c.end()
+	default:
+		if !d.equal(a, b) {
+			d.changed(path, a, b, depth)
+		}
+	}
+}
+
+// collapser collapses the runs of the equal children of a value between the
+// differences to … after the path of the value, e.g. Items.….
+type collapser struct {
+	d       *differ
+	marker  string
+	start   int  // the number of the lines before the children
+	skipped bool // true if there are equal children not marked yet
+}
+
+func (d *differ) collapser(path []string) *collapser {
+	marker := "  …"
+	if len(path) > 0 {
+		marker = "  " + strings.Join(path, ".") + ".…"
+	}
+	return &collapser{d: d, marker: marker, start: len(d.lines)}
+}
+
+// child appends the differences of a child by diff.
+func (c *collapser) child(diff func()) {
+	n := len(c.d.lines)
+	diff()
+	if len(c.d.lines) == n {
+		c.skipped = true
+		return
+	}
+	if c.skipped {
+		c.d.lines = append(c.d.lines[:n], append([]string{c.marker}, c.d.lines[n:]...)...)
+		c.skipped = false
+	}
+}
+
+// end marks the trailing equal children if any child differs.
+func (c *collapser) end() {
+	if c.skipped && len(c.d.lines) > c.start {
+		c.d.lines = append(c.d.lines, c.marker)
+	}
+}
+
+func appendPath(path []string, name string) []string {
+	return append(append([]string(nil), path...), name)
+}
@@ -0,0 +1,104 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type diffAccount struct {
+	Name     string
+	Password string
+	Key      string `gdump:"redact"`
+	Internal string `gdump:"-"`
+	Created  time.Time
+	Labels   map[string]string
+	Items    []int
+}
+
+func TestDiffRules(t *testing.T) {
+	a := diffAccount{Name: "alice", Password: "pw1", Key: "k1", Internal: "i1",
+		Labels: map[string]string{"token": "t1", "env": "dev"}, Items: []int{1, 2, 3}}
+	tests := []struct {
+		name   string
+		opts   []Option
+		b      func(b *diffAccount)
+		want   []string
+		hidden []string
+	}{
+		{"equal", nil, func(b *diffAccount) {}, nil, nil},
+		{"field", nil, func(b *diffAccount) { b.Name = "bob" },
+			[]string{"- Name: string{alice}", "+ Name: string{bob}"}, nil},
+		{"element", nil, func(b *diffAccount) { b.Items = []int{1, 2, 4} },
+			[]string{"  Items.…", "- Items.2: int{3}", "+ Items.2: int{4}"}, nil},
+		{"redacted name", nil, func(b *diffAccount) { b.Password = "pw2" },
+			[]string{"- Password: string{***REDACTED***}", "+ Password: string{***REDACTED***}"}, []string{"pw1", "pw2"}},
+		{"redact tag", nil, func(b *diffAccount) { b.Key = "k2" },
+			[]string{"- Key: string{***REDACTED***}"}, []string{"k1", "k2"}},
+		{"redacted key", nil, func(b *diffAccount) { b.Labels = map[string]string{"token": "t2", "env": "dev"} },
+			[]string{"- Labels.token: string{***REDACTED***}"}, []string{"t1", "t2"}},
+		{"skipped tag", nil, func(b *diffAccount) { b.Internal = "i2" }, nil, []string{"i1", "i2"}},
+		{"excluded field", []Option{WithExcludedFields("Name")}, func(b *diffAccount) { b.Name = "bob" }, nil, []string{"bob"}},
+		{"excluded type", []Option{WithExcludedTypes(reflect.TypeOf(time.Time{}))}, func(b *diffAccount) { b.Created = time.Unix(1, 0) }, nil, []string{"Created"}},
+		{"field filter", []Option{WithFieldFilter(func(_ reflect.Value, f reflect.StructField, _ reflect.Value) bool { return f.Name != "Items" })},
+			func(b *diffAccount) { b.Items = nil }, nil, []string{"Items"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := a
+			b.Labels = a.Labels
+			tt.b(&b)
+			out := New(append([]Option{WithDepth(3)}, tt.opts...)...).Diff(a, b)
+			if tt.want == nil && out != "" {
+				t.Errorf("Diff = %q, want no differences", out)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("Diff = %q, want %q", out, w)
+				}
+			}
+			for _, h := range tt.hidden {
+				if strings.Contains(out, h) {
+					t.Errorf("Diff = %q, printed %q", out, h)
+				}
+			}
+		})
+	}
+}
+
+type diffHandler struct {
+	Name    string `gdump:"name"`
+	OnEvent func()
+	Events  chan int
+	Next    *diffHandler
+}
+
+func diffNop() {}
+
+func TestValueDiff(t *testing.T) {
+	events := make(chan int)
+	a := &diffHandler{Name: "a", OnEvent: diffNop, Events: events}
+	tests := []struct {
+		name string
+		a, b interface{}
+		want string
+	}{
+		{"same func and chan", a, &diffHandler{Name: "a", OnEvent: diffNop, Events: events}, ""},
+		{"same func beyond depth", []*diffHandler{a}, []*diffHandler{{Name: "a", OnEvent: diffNop, Events: events}}, ""},
+		{"other chan", a, &diffHandler{Name: "a", OnEvent: diffNop, Events: make(chan int)},
+			"  …\n- Events: chan int{both, 0/0}\n+ Events: chan int{both, 0/0}\n  …"},
+		{"tag name", a, &diffHandler{Name: "b", OnEvent: diffNop, Events: events},
+			"- name: string{a}\n+ name: string{b}\n  …"},
+		{"nil", a, (*diffHandler)(nil), "- *gdump.diffHandler{4 fields …}\n+ *gdump.diffHandler{nil}"},
+		{"lengths", []int{1, 2}, []int{1, 3, 4}, "  …\n- 1: int{2}\n+ 1: int{3}\n+ 2: int{4}"},
+		{"keys", map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2, "c": 3}, "- a: int{1}\n  …\n+ c: int{3}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValueDiff(tt.a, tt.b, 0); got != tt.want {
+				t.Errorf("ValueDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,29 +2,85 @@ package gdump
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// The package-level variables below are read by every dump without any
+// synchronization. Set them before dumping from multiple goroutines, e.g. in
+// init, or configure a Dumper by New instead.
+
 // NewlineAtEnd - inserts a newline after ValueDump if enabled
 var NewlineAtEnd bool = true
 
 // DefaultPrintDepth - the print level of the value printed
 var DefaultPrintDepth int = 3
 
+// IndentUnit - the indentation added to each nested level by the package-level
+// functions, e.g. "  " or "\t"
+var IndentUnit string = "• "
+
+// MaxStringLen - the maximum number of the runes of the strings and the bytes
+// of the byte slices printed by the package-level functions. The rest is
+// elided. 0 means unlimited.
+var MaxStringLen int = 0
+
+// UseStringer - prints the values implementing error or fmt.Stringer via
+// their methods in ValueDump and ValueDumpInline if enabled
+var UseStringer bool = true
+
+// CompactPrimitiveSlices - prints the slices of numbers, strings and booleans
+// in a line in the package-level functions if enabled, e.g. []int{1, 2, 3}
+var CompactPrimitiveSlices bool = true
+
+// SortMapKeys - prints the map entries in the order of their keys in the
+// package-level functions if enabled, otherwise in the iteration order of
+// the maps
+var SortMapKeys bool = true
+
+// RedactSecrets - prints the struct fields and string map keys named like
+// the secrets, i.e. matching DefaultRedactPattern such as Password, Token,
+// Secret and APIKey, as ***REDACTED*** in the package-level functions and
+// the Dumpers created by New if enabled
+var RedactSecrets bool = true
+
+// globalOptions returns the options of the package-level functions set by
+// the package-level variables.
+func globalOptions(depth int) Options {
+	opts := Options{
+		Depth:        depth,
+		NewlineAtEnd: NewlineAtEnd,
+		IndentUnit:   IndentUnit,
+		MaxStringLen: MaxStringLen,
+		UseStringer:  UseStringer,
+		KeepMapOrder: !SortMapKeys,
+
+		CompactPrimitiveSlices: CompactPrimitiveSlices,
+	}
+	if RedactSecrets {
+		opts.RedactPattern = DefaultRedactPattern
+	}
+	return opts
+}
+
 // Print - print the input value to Stdout
 func Print(value ...interface{}) {
 	for _, v := range value {
-		ValueDump(v, DefaultPrintDepth, func(x ...interface{}) { fmt.Print(x...) })
+		Fdump(os.Stdout, v, DefaultPrintDepth)
 	}
 }
 
 // PrintInDepth - print the input value to Stdout
 func PrintInDepth(level int, value ...interface{}) {
 	for _, v := range value {
-		ValueDump(v, level, func(x ...interface{}) { fmt.Print(x...) })
+		Fdump(os.Stdout, v, level)
 	}
 }
 
@@ -34,13 +90,35 @@ func PrintInDepth(level int, value ...interface{}) {
 // - depth: The depth of the printed values and types.
 // - print: The print function
 func ValueDump(value interface{}, depth int, print func(a ...interface{}), excludedField ...string) string {
+	opts := globalOptions(depth)
+	opts.ExcludedField = excludedField
+	ds := &dumpState{Options: opts}
+	return ds.dump(value, print)
+}
+
+// ValueDumpOnly returns a string representation of value like ValueDump, but
+// only the struct fields and string map keys named in includedField are
+// printed at every nesting level.
+// - value: The value to print.
+// - depth: The depth of the printed values and types.
+// - print: The print function
+func ValueDumpOnly(value interface{}, depth int, print func(a ...interface{}), includedField ...string) string {
+	opts := globalOptions(depth)
+	opts.IncludedField = includedField
+	ds := &dumpState{Options: opts}
+	return ds.dump(value, print)
+}
+
+// dump returns the string representation of value or prints it line by line
+// if print is not nil.
+func (s *dumpState) dump(value interface{}, print func(a ...interface{})) string {
 	v := reflect.ValueOf(value)
-	s := valueString(v, depth, 0, "", false, false, excludedField...)
-	if NewlineAtEnd {
-		s = s + "\n"
+	out := s.valueString(v, s.Depth, 0, 0, "", false, false)
+	if s.NewlineAtEnd {
+		out += "\n"
 	}
 	if print != nil {
-		reader := bufio.NewReader(strings.NewReader(s))
+		reader := bufio.NewReader(strings.NewReader(out))
 		for {
 			line, err := reader.ReadString('\n')
 			if err == io.EOF {
@@ -57,18 +135,20 @@ func ValueDump(value interface{}, depth int, print func(a ...interface{}), exclu
 		// print("\n")
 		return ""
 	}
-	return s
+	return out
 }
 
 // ValueDumpInline returns a string representation of value which may be a value, ptr,
-// or struct type.
+// or struct type in a line. The strings with newlines are quoted.
 // - value: The value to print.
 // - depth: The depth of the printed values and types.
 // - print: The print function
 func ValueDumpInline(value interface{}, depth int, print func(a ...interface{}), excludedField ...string) string {
 	v := reflect.ValueOf(value)
-	s := valueString(v, depth, 0, "", false, true, excludedField...)
-	s = strings.ReplaceAll(s, "\n", " ")
+	opts := globalOptions(depth)
+	opts.ExcludedField = excludedField
+	ds := &dumpState{Options: opts}
+	s := ds.inline(v)
 	if print != nil {
 		print(s)
 		return ""
@@ -76,6 +156,27 @@ func ValueDumpInline(value interface{}, depth int, print func(a ...interface{}),
 	return s
 }
 
+// inline returns the string representation of v in a line.
+func (s *dumpState) inline(v reflect.Value) string {
+	return s.valueString(v, s.Depth, 0, 0, "", false, true)
+}
+
+// Fdump - writes the string representation of value dumped like ValueDump to w.
+// It returns the number of bytes written and any write error.
+func Fdump(w io.Writer, value interface{}, depth int, excludedField ...string) (int, error) {
+	opts := globalOptions(depth)
+	opts.ExcludedField = excludedField
+	ds := &dumpState{Options: opts}
+	return ds.writeTo(w, reflect.ValueOf(value))
+}
+
+// FdumpInline - writes the string representation of value dumped like
+// ValueDumpInline to w. It returns the number of bytes written and any write
+// error.
+func FdumpInline(w io.Writer, value interface{}, depth int, excludedField ...string) (int, error) {
+	return io.WriteString(w, ValueDumpInline(value, depth, nil, excludedField...))
+}
+
 func isExcludedField(fieldname string, excludedField ...string) bool {
 	if len(excludedField) > 0 {
 		for _, exf := range excludedField {
@@ -87,141 +188,946 @@ func isExcludedField(fieldname string, excludedField ...string) bool {
 	return false
 }
 
-func valueString(v reflect.Value, depth, ptrcnt int, indent string, disableIndent bool, noIndent bool, excludedField ...string) string {
+// isOmittedField returns true if the struct field or string map key name is
+// listed in ExcludedField or not listed in IncludedField if it is set.
+// The entries of ExcludedField having dots are matched against the dotted
+// path of the field from the top, e.g. User.Credentials.Password, where *
+// matches any single field, key or index, e.g. Users.*.Password.
+func (s *dumpState) isOmittedField(name string) bool {
+	return s.isOmittedName(name) || s.isOmittedPath(name)
+}
+
+// isOmittedName returns true if name is omitted by the entries of
+// ExcludedField without dots or by IncludedField, wherever it is.
+func (s *dumpState) isOmittedName(name string) bool {
+	for _, exf := range s.ExcludedField {
+		if exf == name {
+			return true
+		}
+	}
+	return len(s.IncludedField) > 0 && !isExcludedField(name, s.IncludedField...)
+}
+
+// isOmittedPath returns true if the path to name is matched by an entry of
+// ExcludedField having dots.
+func (s *dumpState) isOmittedPath(name string) bool {
+	patterns := s.fieldRules().paths
+	if len(patterns) == 0 {
+		return false
+	}
+	path := append(append(make([]string, 0, len(s.path)+1), s.path...), name)
+	for _, pattern := range patterns {
+		if MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath returns true if the segments of the pattern match path, where
+// a * segment matches any segment and a ** segment matches any number of
+// segments including none, e.g. the pattern Spec.**.Password split by dots
+// as the paths of Options.ExcludedField.
+func MatchPath(pattern, path []string) bool {
+	for i, p := range pattern {
+		if p == "**" {
+			for j := i; j <= len(path); j++ {
+				if MatchPath(pattern[i+1:], path[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if i >= len(path) || p != "*" && p != path[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+// isHiddenPath returns true if the struct field or string map key name of
+// the type t is not on the paths of Options.IncludedPaths if it is set, i.e.
+// it is neither included, a descendant of an included path nor an ancestor
+// of one. The values of t having no fields or keys, e.g. Name by **.Zip,
+// are not the ancestors.
+func (s *dumpState) isHiddenPath(name string, t reflect.Type) bool {
+	if len(s.IncludedPaths) == 0 {
+		return false
+	}
+	path := append(append([]string(nil), s.path...), name)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	leaf := isPrimitiveKind(t.Kind())
+	for _, inc := range s.IncludedPaths {
+		pattern := strings.Split(inc, ".")
+		if len(pattern) == 1 {
+			for _, p := range path {
+				if p == inc {
+					return false
+				}
+			}
+			continue
+		}
+		if !leaf && matchPrefix(pattern, path) {
+			return false
+		}
+		for k := 1; k <= len(path); k++ {
+			if MatchPath(pattern, path[:k]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchPrefix returns true if path is a prefix of the paths matched by the
+// segments of the pattern like MatchPath. Any path is a prefix after a **
+// segment.
+func matchPrefix(pattern, path []string) bool {
+	for i, p := range path {
+		if i >= len(pattern) {
+			return false
+		}
+		if pattern[i] == "**" {
+			return true
+		}
+		if pattern[i] != "*" && pattern[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// isExcludedType returns true if the values of the type t are not printed
+// by Options.ExcludedTypes.
+func (s *dumpState) isExcludedType(t reflect.Type) bool {
+	for _, et := range s.ExcludedTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// typeDepth returns the depth set by Options.TypeDepth for the type t or
+// the type pointed by t.
+func (s *dumpState) typeDepth(t reflect.Type) (int, bool) {
+	if len(s.TypeDepth) == 0 {
+		return 0, false
+	}
+	if d, ok := s.TypeDepth[t]; ok {
+		return d, true
+	}
+	if t.Kind() == reflect.Ptr {
+		return s.typeDepth(t.Elem())
+	}
+	return 0, false
+}
+
+// dumpState keeps the options and the context of a dump.
+type dumpState struct {
+	Options
+	path       []string  // the field names, map keys and indices from the top
+	node       *DumpNode // the node of the value being dumped if building a tree
+	expandZero bool      // dumps the zero structs field by field, not as the leaves
+
+	attachments map[string]string // the values extracted by ExtractLargeValues
+	keyTable    map[string]string // the map keys hashed by HashLongKeys
+	subtrees    map[uint64]string // the paths of the subtrees by their hashes
+
+	deadline time.Time // the deadline set by PerValueTimeout
+	visits   int       // the number of the values dumped
+	timedOut bool
+	ctx      context.Context // stops the dump if done, nil if never done
+	nodes    int             // the number of the values dumped counted for MaxNodes
+	size     int             // the bytes of the leaves printed counted for MaxOutputBytes
+
+	visited   map[refKey]string // the paths of the referenced values dumped
+	ancestors map[refKey]bool   // the referenced values being dumped
+	shared    map[refKey]int    // the numbers of the values referenced again
+	refs      int               // the number of the values numbered in shared
+	refPaths  bool              // refers back to the values by their paths, not numbered in the trees
+	methods   methodGuard       // the receivers of the methods being called, shared by ContextStringer
+	inMethod  bool              // dumped by a method called by another dump without its guard
+}
+
+// enter moves into the child value named name of the value being dumped.
+// It returns the current node to be restored by leave.
+func (s *dumpState) enter(name string) *DumpNode {
+	s.path = append(s.path, name)
+	return s.enterNode(name)
+}
+
+// leave moves back to the parent value from the child value.
+func (s *dumpState) leave(parent *DumpNode) {
+	s.path = s.path[:len(s.path)-1]
+	s.node = parent
+}
+
+// expandsZero returns true if the zero value v is dumped field by field
+// rather than as a leaf, i.e. a struct dumped into a document or with the
+// fields selected by Options.FieldFilter.
+func (s *dumpState) expandsZero(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct && (s.expandZero || s.FieldFilter != nil)
+}
+
+// indentUnit returns the indentation added to each nested level.
+func (s *dumpState) indentUnit() string {
+	if s.IndentUnit != "" {
+		return s.IndentUnit
+	}
+	return "• "
+}
+
+// pointerMarker returns the marker of the values referenced by pointers.
+func (s *dumpState) pointerMarker() string {
+	if s.PointerMarker != "" {
+		return s.PointerMarker
+	}
+	return "*"
+}
+
+// pointerPrefix returns the prefix of the value referenced by the pointer v,
+// i.e. the pointer marker followed by the address if ShowPointerAddr is set.
+func (s *dumpState) pointerPrefix(v reflect.Value) string {
+	if s.ShowPointerAddr {
+		return fmt.Sprintf("%s0x%x ", s.pointerMarker(), v.Pointer())
+	}
+	return s.pointerMarker()
+}
+
+// interfaceMarker returns the marker of the dynamic values of interfaces.
+func (s *dumpState) interfaceMarker() string {
+	if s.InterfaceMarker != "" {
+		return s.InterfaceMarker
+	}
+	return "○"
+}
+
+// rendererPriority returns the interfaces checked by UseStringer in order.
+func (s *dumpState) rendererPriority() []RendererKind {
+	if s.RendererPriority != nil {
+		return s.RendererPriority
+	}
+	return DefaultRendererPriority
+}
+
+// callsMethods returns true if v is printed by its methods by UseStringer.
+func (s *dumpState) callsMethods(v reflect.Value) bool {
+	if !s.UseStringer || s.NoMethodCalls {
+		return false
+	}
+	if len(s.IgnoreStringer) > 0 {
+		t := getBaseType(v.Type())
+		for _, it := range s.IgnoreStringer {
+			if getBaseType(it) == t {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// childDepth returns the depth of the child value entered from the value
+// dumped with depth. If FocusPath is set, the ancestors of the focused value
+// don't consume the depth, the focused value is dumped with Options.Depth and
+// the others off the path are collapsed.
+func (s *dumpState) childDepth(depth int) int {
+	if s.FocusPath == "" {
+		return depth - 1
+	}
+	path := strings.Join(s.path, ".")
+	switch {
+	case path == s.FocusPath:
+		return s.Depth
+	case strings.HasPrefix(s.FocusPath, path+"."):
+		return depth
+	case strings.HasPrefix(path, s.FocusPath+"."):
+		return depth - 1
+	}
+	return -1
+}
+
+// isHighlighted returns true if the path of the value being dumped is listed
+// in Options.HighlightPaths.
+func (s *dumpState) isHighlighted() bool {
+	if len(s.HighlightPaths) == 0 {
+		return false
+	}
+	path := strings.Join(s.path, ".")
+	for _, p := range s.HighlightPaths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// itemIndices returns the indices of the entries printed from n entries of
+// a slice or map placed at the level and the number of the elided entries.
+// -1 is placed in the indices where the entries are elided.
+func (s *dumpState) itemIndices(level, n int) ([]int, int) {
+	head, tail := n, 0
+	if s.MaxItems > 0 && level >= s.MinFullDepth && n > s.MaxItems {
+		switch s.TruncateStrategy {
+		case TruncTail:
+			head, tail = 0, s.MaxItems
+		case TruncMiddle:
+			head, tail = (s.MaxItems+1)/2, s.MaxItems/2
+		default:
+			head, tail = s.MaxItems, 0
+		}
+	}
+	indices := make([]int, 0, head+tail+1)
+	for i := 0; i < head; i++ {
+		indices = append(indices, i)
+	}
+	if head+tail < n {
+		indices = append(indices, -1)
+	}
+	for i := n - tail; i < n; i++ {
+		indices = append(indices, i)
+	}
+	return indices, n - head - tail
+}
+
+func (s *dumpState) valueString(v reflect.Value, depth, level, ptrcnt int, indent string, disableIndent bool, noIndent bool) string {
+	var b strings.Builder
+	s.writeValue(&b, v, depth, level, ptrcnt, indent, disableIndent, noIndent)
+	return b.String()
+}
+
+// writeValue writes the string representation of v to w. The containers
+// write their elements, fields and entries to w one by one as they are
+// dumped, so a dump is streamed to w unless an option needs the whole
+// string of a child, e.g. ExtractLargeValues.
+func (s *dumpState) writeValue(w io.StringWriter, v reflect.Value, depth, level, ptrcnt int, indent string, disableIndent bool, noIndent bool) {
+	// put writes out indented unless it continues a line.
+	put := func(out string) {
+		if !disableIndent && !noIndent {
+			w.WriteString(indent)
+		}
+		w.WriteString(out)
+	}
 	var out string
-	if depth < 0 {
-		return " ..."
+	switch {
+	case s.shared != nil:
+	case s.refPaths:
+		s.shared = map[refKey]int{}
+	default:
+		// the first value dumped by s
+		s.shared = s.sharedRefs(v, depth, level)
 	}
-	if v.Type() == reflect.TypeOf(nil) {
-		if disableIndent || noIndent {
-			return "nil{nil}"
+	if s.isTimedOut() {
+		marker := s.stopMarker()
+		s.setNode(v, marker)
+		if !disableIndent && !noIndent {
+			// an element stopped on its own line
+			put(marker)
+			return
 		}
-		return indent + "nil{nil}"
+		w.WriteString(" " + marker)
+		return
 	}
-	if v.IsZero() {
-		if disableIndent || noIndent {
-			return fmt.Sprintf("%s{%v}", v.Type(), v)
+	s.nodes++
+	if depth < 0 {
+		s.setNode(v, "...")
+		if !disableIndent && !noIndent {
+			// an element collapsed off FocusPath on its own line
+			put("...")
+			return
 		}
-		return indent + fmt.Sprintf("%s{%v}", v.Type(), v)
+		w.WriteString(" ...")
+		return
 	}
 	if !v.IsValid() {
-		if disableIndent || noIndent {
-			return fmt.Sprintf("%s{invalid}", v.Type())
+		// reflect.ValueOf(nil) has neither type nor value.
+		s.setNode(v, "nil")
+		put(s.paint(ansiDim, "nil{nil}"))
+		return
+	}
+	s.setNode(v, "")
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		s.setNode(v, "nil")
+		// A nil interface has no dynamic type, unlike an interface holding
+		// a typed nil that is rendered as ○*T{nil} by the Interface case.
+		put(s.interfaceMarker() + "nil")
+		return
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		s.setNode(v, "nil")
+		put(s.leaf(v, depth, "nil"))
+		return
+	}
+	if value, ok := s.formatterString(v); ok {
+		s.setNode(v, value)
+		value = inlineValue(value, noIndent)
+		put(s.leaf(v, depth, value))
+		return
+	}
+	if value, ok := enumString(v); ok {
+		s.setNode(v, value)
+		put(s.leaf(v, depth, value))
+		return
+	}
+	if value, ok := s.textBytesString(v, indent); ok {
+		s.setNode(v, value)
+		value = inlineValue(value, noIndent)
+		put(s.leaf(v, depth, value))
+		return
+	}
+	if value, ok := specialString(v, !s.NoMethodCalls, s.RawStdTypes, s.ExpandURLs); ok {
+		if v.Kind() == reflect.Ptr {
+			// the nodes hold the values pointed as the other pointers
+			s.setNode(v, strings.TrimPrefix(value, "&"))
+		} else {
+			s.setNode(v, value)
 		}
-		return indent + fmt.Sprintf("%s{invalid}", v.Type())
+		value = inlineValue(value, noIndent)
+		put(s.leaf(v, depth, value))
+		return
+	}
+	if value, ok := s.errorChain(v); ok {
+		s.setNode(v, value)
+		put(value)
+		return
 	}
-	if v.Kind() == reflect.Ptr && v.IsNil() || isValueNil(v.Interface()) {
-		if disableIndent || noIndent {
-			return fmt.Sprintf("%s{nil}", v.Type())
+	if s.callsMethods(v) {
+		if value, ok := s.methodString(v, s.rendererPriority()); ok {
+			s.setNode(v, value)
+			value = inlineValue(value, noIndent)
+			put(s.leaf(v, depth, value))
+			return
 		}
-		return indent + fmt.Sprintf("%s{nil}", v.Type())
 	}
-	_depth := depth
+	if v.Kind() != reflect.Interface && v.Kind() != reflect.Array && v.IsZero() && !s.expandsZero(v) {
+		value := s.format(v)
+		if isNilable(v.Kind()) {
+			// not map[] or [] of the empty ones
+			value = "nil"
+		}
+		s.setNode(v, value)
+		put(s.leaf(v, depth, value))
+		return
+	}
+	if isValueNil(v) {
+		s.setNode(v, "nil")
+		put(s.leaf(v, depth, "nil"))
+		return
+	}
+	if summary, ok := s.depthSummary(v, depth); ok {
+		s.setNode(v, summary)
+		out = s.paint(ansiType, v.Type().String()) + "{" + s.paint(ansiDim, summary) + "}"
+		put(out)
+		return
+	}
+	if ref, seen := s.visit(v); seen {
+		s.setNode(v, ref)
+		out = s.paint(ansiType, v.Type().String()) + "{" + ref + "}"
+		if v.Kind() == reflect.Ptr && s.ShowPointerAddr {
+			out = s.pointerPrefix(v) + s.paint(ansiType, v.Type().Elem().String()) + "{" + ref + "}"
+		}
+		put(out)
+		return
+	}
+	defer s.unvisit(v)
+	put(s.anchor(v))
 	switch v.Kind() {
 	case reflect.Ptr:
 		ptrcnt++
-		out = "*" + valueString(v.Elem(), depth, ptrcnt, indent, true, noIndent, excludedField...)
+		w.WriteString(s.pointerPrefix(v))
+		if e := v.Elem(); e.Kind() == reflect.Ptr && e.IsNil() {
+			// told from a nil pointer to the pointer, e.g. **int{&nil}
+			s.setNode(e, "nil")
+			w.WriteString(s.leaf(e, depth, strings.Repeat("&", ptrcnt)+"nil"))
+			break
+		}
+		s.writeValue(w, v.Elem(), depth, level, ptrcnt, indent, true, noIndent)
 	case reflect.Interface:
 		ptrcnt++
-		out = "○" + valueString(v.Elem(), depth, ptrcnt, indent, true, noIndent, excludedField...)
-	case reflect.Slice:
-		out = fmt.Sprintf("%s{", v.Type())
-		for i := 0; i < v.Len(); i++ {
-			if !noIndent && depth > 0 {
-				out += "\n"
+		w.WriteString(s.interfaceMarker())
+		s.writeValue(w, v.Elem(), depth, level, ptrcnt, indent, true, noIndent)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			value := s.bytesString(v, indent+s.indentUnit(), noIndent)
+			out = s.paint(ansiType, v.Type().String()) + "{" + s.paint(ansiValue, value) + "}"
+			if s.node != nil && s.BytesFormat == BytesHexdump {
+				// the lines of the nodes are not indented as the text dump
+				value = strings.TrimPrefix(s.bytesString(v, "", noIndent), "\n")
 			}
-			out += valueString(v.Index(i), depth-1, 0, indent+"• ", false, noIndent, excludedField...)
+			s.setNode(v, value)
+			break
 		}
-		out += "}"
-	case reflect.Struct:
-		t := v.Type()
-		out = fmt.Sprintf("%s{", v.Type())
-		for i := 0; i < v.NumField(); i++ {
-			fv := v.Field(i)
-			ft := t.Field(i)
-			if areSameType(ft.Type, t) {
-				depth = 0
+		if value, ok := s.compactElements(v, depth, level, noIndent); ok {
+			out = s.paint(ansiType, v.Type().String()) + "{" + s.paint(ansiValue, value) + "}"
+			break
+		}
+		if value, ok := s.tableString(v, depth, level, indent, noIndent); ok {
+			out = s.paint(ansiType, v.Type().String()) + "{" + value + "}"
+			break
+		}
+		w.WriteString(s.paint(ansiType, v.Type().String()) + "{")
+		indices, elided := s.itemIndices(level, v.Len())
+		for n, i := range indices {
+			if s.timedOut {
+				break
+			}
+			if s.ElementSeparator != "" && n > 0 {
+				w.WriteString(s.ElementSeparator)
 			}
-			if isExcludedField(ft.Name, excludedField...) {
+			if s.isOverBudget() {
+				w.WriteString(s.truncation(n, v.Len(), depth, indent+s.indentUnit(), noIndent))
+				break
+			}
+			if i < 0 {
+				w.WriteString(s.elision(elided, depth, indent+s.indentUnit(), noIndent))
 				continue
 			}
-			if noIndent {
-				if fv.CanInterface() {
-					out += fmt.Sprintf("\n%s:%v", ft.Name, valueString(fv, depth-1, 0, indent+"• ", true, noIndent, excludedField...))
-				} else {
-					out += fmt.Sprintf("\n%s:%v", ft.Name, fv)
-				}
-			} else {
-				if _depth > 0 {
-					out += fmt.Sprintf("\n%s", indent+"• ")
-				} else {
-					out += " "
-				}
-				if fv.CanInterface() {
-					out += fmt.Sprintf("%s:%v", ft.Name, valueString(fv, depth-1, 0, indent+"• ", true, noIndent, excludedField...))
-				} else {
-					out += fmt.Sprintf("%s:%v", ft.Name, fv)
+			switch {
+			case s.ElementSeparator != "":
+				// separated before the element
+			case noIndent:
+				if n > 0 {
+					w.WriteString(" ")
 				}
+			case depth > 0:
+				w.WriteString("\n")
 			}
-			depth = _depth
+			parent := s.enter(strconv.Itoa(i))
+			// the elements not on their own lines are not indented
+			s.writeChild(w, v.Index(i), depth, level+1, indent+s.indentUnit(), s.ElementSeparator != "" || depth <= 0, noIndent)
+			s.leave(parent)
 		}
-		out += "}"
+		w.WriteString("}")
+	case reflect.Struct:
+		s.writeStruct(w, v, depth, level, indent, noIndent)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		var value string
+		switch v.Kind() {
+		case reflect.Func:
+			value = s.funcString(v)
+		case reflect.Chan:
+			value = s.chanString(v)
+		case reflect.UnsafePointer:
+			value = s.unsafePointerString(v)
+		default:
+			value = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+		}
+		out = s.leaf(v, depth, value)
+		s.setNode(v, value)
 	case reflect.Map:
-		out = fmt.Sprintf("%s{", v.Type())
-		iter := v.MapRange()
-		for iter.Next() {
-			k := iter.Key()
-			e := iter.Value()
-			if k.Kind() == reflect.String {
-				if isExcludedField(k.Interface().(string), excludedField...) {
-					depth = 0
+		entries := s.sortedEntries(v)
+		if s.RecognizeSets && isSetType(v.Type()) {
+			out = s.setString(v, entries, level)
+			break
+		}
+		w.WriteString(s.paint(ansiType, v.Type().String()) + "{")
+		s.writeMapEntries(w, entries, depth, level, indent, noIndent)
+		w.WriteString("}")
+	default:
+		value := s.format(v)
+		printed := value
+		if v.Kind() == reflect.String {
+			switch {
+			case s.RedactHighEntropy && s.isHighEntropy(v.String()):
+				value = redactedValue
+				printed = value
+			default:
+				printed = s.stringValue(value, indent, noIndent)
+				value = s.truncateString(value)
+			}
+		}
+		out = s.leaf(v, depth, strings.Repeat("&", ptrcnt)+printed)
+		s.setNode(v, value)
+	}
+	w.WriteString(out)
+}
+
+// leaf returns the leaf value v printed as value in its type, or only the
+// value if its depth is exhausted and BareLeavesAtMaxDepth is set.
+func (s *dumpState) leaf(v reflect.Value, depth int, value string) string {
+	out := s.leafString(v, depth, value)
+	s.size += len(out)
+	return out
+}
+
+// leafString returns the leaf value v printed like leaf.
+func (s *dumpState) leafString(v reflect.Value, depth int, value string) string {
+	code := ansiValue
+	if s.Color && (value == "nil" || v.IsZero()) {
+		code = ansiDim
+	}
+	if s.BareLeavesAtMaxDepth && depth <= 0 {
+		switch v.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		default:
+			return s.paint(code, value)
+		}
+	}
+	return s.paint(ansiType, v.Type().String()) + "{" + s.paint(code, value) + "}"
+}
+
+// childString returns the string representation of the child value v of the
+// value dumped in depth.
+func (s *dumpState) childString(v reflect.Value, depth, level int, indent string, disableIndent, noIndent bool) string {
+	return s.childValue(v, s.childDepth(depth), level, indent, disableIndent, noIndent)
+}
+
+// childValue returns the child value v printed in depth, which is replaced
+// by Options.TypeDepth if set for the type of v.
+func (s *dumpState) childValue(v reflect.Value, depth, level int, indent string, disableIndent, noIndent bool) string {
+	var b strings.Builder
+	s.writeChildValue(&b, v, depth, level, indent, disableIndent, noIndent)
+	return b.String()
+}
+
+// writeChild writes the child value v of the value dumped in depth to w.
+func (s *dumpState) writeChild(w io.StringWriter, v reflect.Value, depth, level int, indent string, disableIndent, noIndent bool) {
+	s.writeChildValue(w, v, s.childDepth(depth), level, indent, disableIndent, noIndent)
+}
+
+// writeChildValue writes the child value v printed in depth like childValue
+// to w. The child is streamed to w unless it may be replaced by an
+// attachment of ExtractLargeValues.
+func (s *dumpState) writeChildValue(w io.StringWriter, v reflect.Value, depth, level int, indent string, disableIndent, noIndent bool) {
+	if d, ok := s.typeDepth(v.Type()); ok {
+		depth = d
+	}
+	if ref, ok := s.dedup(v, depth, level); ok {
+		if !disableIndent && !noIndent {
+			w.WriteString(indent)
+		}
+		w.WriteString(ref)
+		return
+	}
+	if !noIndent && s.fitsWidth(v, depth, level) {
+		out := s.valueString(v, depth, level, 0, indent, disableIndent, true)
+		if !disableIndent {
+			out = indent + out
+		}
+		w.WriteString(s.extract(out, indent))
+		return
+	}
+	if s.attachments != nil && s.ExtractLargeValues > 0 {
+		w.WriteString(s.extract(s.valueString(v, depth, level, 0, indent, disableIndent, noIndent), indent))
+		return
+	}
+	s.writeValue(w, v, depth, level, 0, indent, disableIndent, noIndent)
+}
+
+// writeStruct writes the string representation of the struct v to w. The
+// fields are buffered only if the type printed before them depends on them,
+// i.e. by CountZeroFields and SingleFieldAsValue.
+func (s *dumpState) writeStruct(w io.StringWriter, v reflect.Value, depth, level int, indent string, noIndent bool) {
+	t := v.Type()
+	if s.ShowUnexported && !v.CanAddr() && v.CanInterface() {
+		// copied to read the unexported fields by their addresses
+		c := reflect.New(t).Elem()
+		c.Set(v)
+		v = c
+	}
+	fields := s.structFields(v)
+	collapse := s.CollapseSingleField && len(fields) == 1
+	fieldIndent := indent + s.indentUnit()
+	if collapse {
+		fieldIndent = indent
+	}
+	var buf strings.Builder
+	buffered := s.CountZeroFields || collapse && s.SingleFieldAsValue
+	out := &countingWriter{w: w}
+	if buffered {
+		out.w = &buf
+	} else {
+		w.WriteString(s.paint(ansiType, t.String()) + "{")
+	}
+	// the fields decorated or collapsed are printed as strings.
+	decorated := s.ShowConstraints || s.ShowValidation || collapse && s.SingleFieldAsValue
+	set, zero, hidden := 0, 0, 0
+	for i, f := range fields {
+		if s.timedOut {
+			break
+		}
+		fv, ft, tag := f.fv, f.ft, f.tag
+		name := ft.Name
+		if tag.name != "" {
+			name = tag.name
+		}
+		rule := s.fieldRule(f)
+		if rule.omitted || s.isOmittedPath(ft.Name) || name != ft.Name && s.isOmittedPath(name) || s.isFilteredField(f) {
+			continue
+		}
+		if s.isHiddenPath(ft.Name, ft.Type) && s.isHiddenPath(name, ft.Type) {
+			hidden++
+			continue
+		}
+		if (s.OmitZero || s.CountZeroFields || tag.omitEmpty) && fv.IsZero() {
+			zero++
+			continue
+		}
+		if s.isOverBudget() {
+			out.WriteString(s.truncation(i, len(fields), depth, fieldIndent, noIndent))
+			break
+		}
+		set++
+		parent := s.enter(name)
+		if parent != nil && f.from != "" {
+			// named as printed, not to repeat the names in the documents
+			s.node.Name = f.from + name
+		}
+		if !fv.CanInterface() && s.ShowUnexported {
+			fv = exposeField(fv)
+		}
+		var fvalue string
+		fdepth := s.childDepth(depth)
+		if tag.depth >= 0 {
+			fdepth = tag.depth
+		}
+		stream := false
+		switch {
+		case rule.redacted:
+			fvalue = s.redacted(fv, depth)
+		case fv.CanInterface() && !decorated && !(s.DecodeBits && depth > 0 && len(tag.bits) > 0):
+			stream = true
+		case fv.CanInterface():
+			fvalue = s.childValue(fv, fdepth, level+1, fieldIndent, true, noIndent)
+		case isPrimitiveKind(fv.Kind()):
+			fvalue = s.format(fv)
+			s.setNode(fv, fvalue)
+		case s.ShowUnexported:
+			fvalue = fmt.Sprintf("%s{unexported}", fv.Type())
+			s.setNode(fv, "unexported")
+		default:
+			// not printed by fmt, which reads the nested fields unredacted
+			fvalue = fmt.Sprintf("%s{…}", fv.Type())
+			s.setNode(fv, "…")
+		}
+		if s.DecodeBits && depth > 0 {
+			if len(tag.bits) > 0 {
+				if decoded, ok := decodeBits(fv, tag.bits); ok {
+					fvalue = decoded
 				}
 			}
-			if noIndent {
-				out += fmt.Sprintf("\n%v:%s", k, valueString(e, depth-1, 0, indent+"• ", true, noIndent, excludedField...))
-			} else {
-				if _depth > 0 {
-					out += fmt.Sprintf("\n%s", indent+"• ")
-				} else {
-					out += " "
+		}
+		if s.ShowConstraints {
+			fvalue = constraintString(f.owner, ft) + fvalue
+		}
+		if s.ShowValidation {
+			fvalue += validationString(fv, tag.validate)
+		}
+		if collapse && s.SingleFieldAsValue {
+			s.leave(parent)
+			w.WriteString(fmt.Sprintf("%s(%s)", t, fvalue))
+			return
+		}
+		switch {
+		case collapse:
+			// printed in the line of the struct
+		case noIndent:
+			if out.n > 0 {
+				out.WriteString(" ")
+			}
+		case depth > 0:
+			out.WriteString("\n" + fieldIndent)
+		default:
+			out.WriteString(" ")
+		}
+		if s.isHighlighted() {
+			out.WriteString(highlightMarker)
+		}
+		if f.from != "" {
+			out.WriteString(s.paint(ansiDim, f.from))
+		}
+		out.WriteString(s.paint(ansiName, name))
+		switch {
+		case s.ShowFieldTags:
+			out.WriteString(s.paint(ansiDim, fieldMetadata(f)))
+		case s.ShowOffsets:
+			out.WriteString(fmt.Sprintf("@%d+%d", f.offset, ft.Type.Size()))
+		}
+		out.WriteString(":")
+		if stream {
+			s.writeChildValue(out, fv, fdepth, level+1, fieldIndent, true, noIndent)
+		} else {
+			out.WriteString(fvalue)
+		}
+		s.leave(parent)
+	}
+	if hidden > 0 && !collapse {
+		out.WriteString(s.elision(hidden, depth, fieldIndent, noIndent))
+	}
+	switch {
+	case s.CountZeroFields:
+		w.WriteString(fmt.Sprintf("%s(%d set, %d zero){", s.paint(ansiType, t.String()), set, zero) + buf.String())
+	case buffered:
+		w.WriteString(s.paint(ansiType, t.String()) + "{" + buf.String())
+	}
+	w.WriteString("}")
+}
+
+// mapEntries returns the string representation of the entries of a map
+// printed in their order.
+func (s *dumpState) mapEntries(entries []mapEntry, depth, level int, indent string, noIndent bool) string {
+	var b strings.Builder
+	s.writeMapEntries(&b, entries, depth, level, indent, noIndent)
+	return b.String()
+}
+
+// writeMapEntries writes the entries of a map like mapEntries to w.
+func (s *dumpState) writeMapEntries(w io.StringWriter, entries []mapEntry, depth, level int, indent string, noIndent bool) {
+	out := &countingWriter{w: w}
+	_depth := depth
+	indices, elided := s.itemIndices(level, len(entries))
+	width := 0
+	if s.AlignMapValues && !noIndent && depth > 0 && s.ElementSeparator == "" {
+		for _, i := range indices {
+			if i >= 0 {
+				if w := utf8.RuneCountInString(s.hashKey(s.keyString(entries[i].key))); w > width {
+					width = w
 				}
-				out += fmt.Sprintf("%v:%s", k, valueString(e, depth-1, 0, indent+"• ", true, noIndent, excludedField...))
 			}
-			depth = _depth
 		}
-		out += "}"
-	default:
-		out = fmt.Sprintf("%s{", v.Type())
-		for i := 0; i < ptrcnt; i++ {
-			out = out + "&"
+	}
+	hidden := 0
+	for n, i := range indices {
+		if s.timedOut {
+			break
+		}
+		if i >= 0 && entries[i].key.Kind() == reflect.String && s.isHiddenPath(entries[i].key.String(), entries[i].value.Type()) {
+			hidden++
+			continue
+		}
+		if s.ElementSeparator != "" && n > hidden {
+			out.WriteString(s.ElementSeparator)
+		}
+		if s.isOverBudget() {
+			out.WriteString(s.truncation(n, len(entries), _depth, indent+s.indentUnit(), noIndent))
+			break
 		}
-		out = out + fmt.Sprintf("%v}", v)
+		if i < 0 {
+			out.WriteString(s.elision(elided, _depth, indent+s.indentUnit(), noIndent))
+			continue
+		}
+		k, e := entries[i].key, entries[i].value
+		if k.Kind() == reflect.String {
+			if s.isOmittedField(k.String()) {
+				depth = 0
+			}
+		}
+		key := s.keyString(k)
+		parent := s.enter(key)
+		key = s.hashKey(key)
+		if !s.RawStrings {
+			// quoted as the string values not to break the lines
+			key = quoteUnsafe(key)
+		}
+		key = inlineValue(key, noIndent)
+		switch {
+		case s.ElementSeparator != "":
+			// separated before the entry
+		case noIndent:
+			if out.n > 0 {
+				out.WriteString(" ")
+			}
+		case _depth > 0:
+			out.WriteString("\n" + indent + s.indentUnit())
+		default:
+			out.WriteString(" ")
+		}
+		if s.isHighlighted() {
+			out.WriteString(highlightMarker)
+		}
+		// the width is widened by the escape codes painted around the key.
+		painted := s.paint(ansiName, key)
+		out.WriteString(keyLabel(k, painted, width+len(painted)-len(key)))
+		if k.Kind() == reflect.String && s.isRedactedName(k.String()) {
+			out.WriteString(s.redacted(e, depth))
+		} else {
+			s.writeChild(out, e, depth, level+1, indent+s.indentUnit(), true, noIndent)
+		}
+		s.leave(parent)
+		depth = _depth
 	}
-	if disableIndent || noIndent {
-		return out
+	if hidden > 0 {
+		if s.ElementSeparator != "" && out.n > 0 {
+			out.WriteString(s.ElementSeparator)
+		}
+		out.WriteString(s.elision(hidden, _depth, indent+s.indentUnit(), noIndent))
 	}
-	return indent + out
 }
 
-// isValueNil returns true if either value is nil, or has dynamic type {ptr,
-// map, slice} with value nil.
-func isValueNil(value interface{}) bool {
-	if value == nil {
-		return true
+// keyLabel returns the label of the map key k formatted to key printed
+// before its value. The key is padded to width runes. Numeric keys are
+// right-aligned and the others are left-aligned.
+func keyLabel(k reflect.Value, key string, width int) string {
+	pad := width - utf8.RuneCountInString(key)
+	if pad <= 0 {
+		return key + ":"
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return strings.Repeat(" ", pad) + key + ":"
 	}
-	switch reflect.TypeOf(value).Kind() {
+	return key + ":" + strings.Repeat(" ", pad)
+}
+
+// elision returns the mark of the n entries omitted from a slice or map.
+func (s *dumpState) elision(n, depth int, indent string, noIndent bool) string {
+	return s.marker(fmt.Sprintf("…(+%d more)", n), depth, indent, noIndent)
+}
+
+// marker returns the text put in place of the entries of a container.
+func (s *dumpState) marker(text string, depth int, indent string, noIndent bool) string {
+	if s.ElementSeparator != "" {
+		return text
+	}
+	if depth > 0 && !noIndent {
+		return "\n" + indent + text
+	}
+	return " " + text
+}
+
+// inlineValue returns value quoted if it has newlines not to break the line
+// of the inline dump.
+func inlineValue(value string, noIndent bool) string {
+	if noIndent && strings.ContainsAny(value, "\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// isOverBudget returns true if Options.MaxNodes values or the leaves of
+// Options.MaxOutputBytes are already dumped.
+func (s *dumpState) isOverBudget() bool {
+	return s.MaxNodes > 0 && s.nodes >= s.MaxNodes ||
+		s.MaxOutputBytes > 0 && s.size >= s.MaxOutputBytes
+}
+
+// truncation returns the marker of the entries not dumped by Options.MaxNodes.
+func (s *dumpState) truncation(shown, total, depth int, indent string, noIndent bool) string {
+	return s.marker(fmt.Sprintf("…(truncated, %d of %d shown)", shown, total), depth, indent, noIndent)
+}
+
+// isValueNil returns true if v is a nil ptr, map or slice. It doesn't call
+// v.Interface(), which panics on the values of unexported fields.
+func isValueNil(v reflect.Value) bool {
+	switch v.Kind() {
 	case reflect.Slice, reflect.Ptr, reflect.Map:
-		return reflect.ValueOf(value).IsNil()
+		return v.IsNil()
 	}
 	return false
 }
 
-// areSameType returns true if t1 and t2 has the same reflect.Type,
-// otherwise it returns false.
-func areSameType(t1 reflect.Type, t2 reflect.Type) bool {
-	b1 := getBaseType(t1)
-	b2 := getBaseType(t2)
-	return b1 == b2
+// isNilable returns true if the values of the kind k can be nil without
+// being held by an interface, i.e. the kinds of the zero values being nil.
+func isNilable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
 }
 
 // getBaseType returns not reflect.Ptr type.
@@ -0,0 +1,694 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type nilError struct{}
+
+func (*nilError) Error() string { return "nil error" }
+
+type nilHolder struct {
+	I interface{}
+	E error
+}
+
+// TestNilInterfaces tells the nil interfaces, printed as ○nil, from the
+// interfaces holding typed nils, printed as ○T{nil} by their dynamic types.
+func TestNilInterfaces(t *testing.T) {
+	var p *int
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   string
+		inline string
+	}{
+		{"untyped nil", nil, "nil{nil}", "nil{nil}"},
+		{"nil pointer", p, "*int{nil}", "*int{nil}"},
+		{"slice", []interface{}{nil, p}, "[]interface {}{\n• ○nil\n• ○*int{nil}}", "[]interface {}{○nil ○*int{nil}}"},
+		{"fields", nilHolder{I: p, E: (*nilError)(nil)}, "gdump.nilHolder{\n• I:○*int{nil}\n• E:○*gdump.nilError{nil}}", "gdump.nilHolder{I:○*int{nil} E:○*gdump.nilError{nil}}"},
+		{"map values", map[string]interface{}{"a": nil, "b": p}, "map[string]interface {}{\n• a:○nil\n• b:○*int{nil}}", "map[string]interface {}{a:○nil b:○*int{nil}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, globalOptions(3)); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+			if got := ValueDumpInline(tt.value, 3, nil); got != tt.inline {
+				t.Errorf("ValueDumpInline = %q, want %q", got, tt.inline)
+			}
+		})
+	}
+}
+
+// TestUntypedNil dumps the untyped nil and the invalid reflect.Value by every
+// entry point and format without a panic.
+func TestUntypedNil(t *testing.T) {
+	if got, want := ValueDump(nil, 2, nil), "nil{nil}\n"; got != want {
+		t.Errorf("ValueDump = %q, want %q", got, want)
+	}
+	var printed []interface{}
+	ValueDump(nil, 2, func(a ...interface{}) { printed = append(printed, a...) })
+	if len(printed) == 0 || printed[0] != "nil{nil}\n" {
+		t.Errorf("printed %q, want the dump", printed)
+	}
+	var b strings.Builder
+	if _, err := Fdump(&b, nil, 2); err != nil || b.String() != "nil{nil}\n" {
+		t.Errorf("Fdump = %q, %v", b.String(), err)
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatText, "nil{nil}"},
+		{FormatJSON, "null"},
+		{FormatYAML, "null"},
+		{FormatGo, "nil"},
+		{FormatSpewCompat, "(interface {}) <nil>\n"},
+	}
+	for _, tt := range tests {
+		if got := Sdump(nil, Options{Depth: 2, Format: tt.format}); got != tt.want {
+			t.Errorf("Sdump in format %d = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got, want := Sdump(reflect.Value{}, globalOptions(2)), "reflect.Value{<invalid Value>}"; got != want {
+		t.Errorf("Sdump(reflect.Value{}) = %q, want %q", got, want)
+	}
+	if got, want := ValueDiff(nil, 1, 2), "- nil{nil}\n+ int{1}"; got != want {
+		t.Errorf("ValueDiff = %q, want %q", got, want)
+	}
+}
+
+type nilKinds struct {
+	M  map[string]int
+	S  []int
+	C  chan int
+	F  func()
+	P  *int
+	PP **int
+	I  interface{}
+}
+
+// TestNilKinds prints the nil maps, slices, channels, funcs and pointers as
+// T{nil} at the top, in the fields, the elements and the map values, and
+// through the pointers and the interfaces.
+func TestNilKinds(t *testing.T) {
+	var p *int
+	const fields = "M:map[string]int{nil}_S:[]int{nil}_C:chan int{nil}_F:func(){nil}_P:*int{nil}_PP:**int{&nil}_I:○*int{nil}"
+	v := nilKinds{PP: &p, I: p}
+	lines := func(indent string) string { return strings.ReplaceAll(fields, "_", "\n"+indent) }
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"map", map[string]int(nil), "map[string]int{nil}"},
+		{"slice", []int(nil), "[]int{nil}"},
+		{"chan", (chan int)(nil), "chan int{nil}"},
+		{"func", (func())(nil), "func(){nil}"},
+		{"pointer", p, "*int{nil}"},
+		{"pointer to nil", &p, "**int{&nil}"},
+		{"nil pointer to pointer", (**int)(nil), "**int{nil}"},
+		{"fields", v, "gdump.nilKinds{\n• " + lines("• ") + "}"},
+		{"pointer to struct", &v, "*gdump.nilKinds{\n• " + lines("• ") + "}"},
+		{"elements", []interface{}{nil, p, []int(nil), map[int]int(nil)}, "[]interface {}{\n• ○nil\n• ○*int{nil}\n• ○[]int{nil}\n• ○map[int]int{nil}}"},
+		{"in slice", []nilKinds{v}, "[]gdump.nilKinds{\n• gdump.nilKinds{\n• • " + lines("• • ") + "}}"},
+		{"in map", map[string]*nilKinds{"a": &v, "b": nil}, "map[string]*gdump.nilKinds{\n• a:*gdump.nilKinds{\n• • " + lines("• • ") + "}\n• b:*gdump.nilKinds{nil}}"},
+		{"zero struct", nilKinds{}, "gdump.nilKinds{{map[] [] <nil> <nil> <nil> <nil> <nil>}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 3}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	got := Sdump(v, Options{Depth: 3, Format: FormatJSON})
+	if want := `{"M":null,"S":null,"C":null,"F":null,"P":null,"PP":null,"I":null}`; strings.Join(strings.Fields(got), "") != want {
+		t.Errorf("Sdump = %s, want %s", got, want)
+	}
+}
+
+// TestMinFullDepth prints all the entries of the levels shallower than
+// MinFullDepth and elides the deeper ones by MaxItems. Depth still cuts the
+// levels off.
+func TestMinFullDepth(t *testing.T) {
+	v := [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	m := map[string][]int{"a": {1, 2, 3}, "b": {4, 5, 6}, "c": {7, 8, 9}}
+	tests := []struct {
+		name   string
+		value  interface{}
+		opts   Options
+		elided int
+		leaves int
+	}{
+		{"items only", v, Options{Depth: 3, MaxItems: 2}, 3, 4},
+		{"top level", v, Options{Depth: 3, MaxItems: 2, MinFullDepth: 1}, 3, 6},
+		{"all levels", v, Options{Depth: 3, MaxItems: 2, MinFullDepth: 2}, 0, 9},
+		{"map", m, Options{Depth: 3, MaxItems: 1, MinFullDepth: 1}, 3, 3},
+		{"depth", v, Options{Depth: 1, MaxItems: 2, MinFullDepth: 2}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.value, tt.opts)
+			if n := strings.Count(got, "more)"); n != tt.elided {
+				t.Errorf("Sdump = %s, want %d entries elided, got %d", got, tt.elided, n)
+			}
+			if n := strings.Count(got, "• int{"); n != tt.leaves {
+				t.Errorf("Sdump = %s, want %d ints printed, got %d", got, tt.leaves, n)
+			}
+		})
+	}
+}
+
+type highlightSpec struct {
+	Replicas int
+	Labels   map[string]string
+	Items    []highlightItem
+}
+
+type highlightItem struct{ Name string }
+
+// TestHighlightPaths marks the fields and map entries on the paths, with
+// the slice elements addressed by their indices, and nothing else.
+func TestHighlightPaths(t *testing.T) {
+	v := highlightSpec{2, map[string]string{"app": "x", "tier": "y"}, []highlightItem{{"a"}, {"b"}}}
+	got := Sdump(v, Options{Depth: 4, HighlightPaths: []string{"Replicas", "Labels.app", "Items.1.Name", "Missing"}})
+	want := "gdump.highlightSpec{\n• ▶Replicas:int{2}\n• Labels:map[string]string{\n• • ▶app:string{x}\n• • tier:string{y}}\n" +
+		"• Items:[]gdump.highlightItem{\n• • gdump.highlightItem{\n• • • Name:string{a}}\n• • gdump.highlightItem{\n• • • ▶Name:string{b}}}}"
+	if got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if got := Sdump(v, Options{Depth: 4}); strings.Contains(got, highlightMarker) {
+		t.Errorf("Sdump = %q without HighlightPaths, want no marker", got)
+	}
+}
+
+// TestFocusPath expands the focused value with the depth wherever it is and
+// collapses the siblings of it and of its ancestors.
+func TestFocusPath(t *testing.T) {
+	v := highlightSpec{2, map[string]string{"app": "x", "tier": "y"}, []highlightItem{{"a"}, {"b"}}}
+	tests := []struct {
+		path  string
+		depth int
+		want  string
+	}{
+		{"Items", 2, "gdump.highlightSpec{\n• Replicas: ...\n• Labels: ...\n• Items:[]gdump.highlightItem{\n• • gdump.highlightItem{\n• • • Name:string{a}}\n• • gdump.highlightItem{\n• • • Name:string{b}}}}"},
+		// the depth runs out below the focused value
+		{"Items", 1, "gdump.highlightSpec{\n• Replicas: ...\n• Labels: ...\n• Items:[]gdump.highlightItem{\n• • gdump.highlightItem{1 field …}\n• • gdump.highlightItem{1 field …}}}"},
+		{"Items.1.Name", 1, "gdump.highlightSpec{\n• Replicas: ...\n• Labels: ...\n• Items:[]gdump.highlightItem{\n• • ...\n• • gdump.highlightItem{\n• • • Name:string{b}}}}"},
+		{"Labels.tier", 1, "gdump.highlightSpec{\n• Replicas: ...\n• Labels:map[string]string{\n• • app: ...\n• • tier:string{y}}\n• Items: ...}"},
+		{"Missing", 4, "gdump.highlightSpec{\n• Replicas: ...\n• Labels: ...\n• Items: ...}"},
+	}
+	for _, tt := range tests {
+		if got := Sdump(v, Options{Depth: tt.depth, FocusPath: tt.path}); got != tt.want {
+			t.Errorf("Sdump with FocusPath %q = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestAlignMapValues pads the keys of each map to its widest key, on the
+// left for the numeric keys and on the right for the others.
+func TestAlignMapValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"int", map[int]int{1: 1, 10: 2, -100: 3}, "map[int]int{\n• -100:int{3}\n•    1:int{1}\n•   10:int{2}}"},
+		{"uint8", map[uint8]string{7: "a", 200: "b"}, "map[uint8]string{\n•   7:string{a}\n• 200:string{b}}"},
+		{"float", map[float64]int{1.5: 1, 10: 2}, "map[float64]int{\n• 1.5:int{1}\n•  10:int{2}}"},
+		{"string", map[string]int{"a": 1, "bbb": 2}, "map[string]int{\n• a:  int{1}\n• bbb:int{2}}"},
+		{"nested", map[int]map[int]int{1: {5: 0, 500: 0}, 100: nil}, "map[int]map[int]int{\n•   1:map[int]int{\n• •   5:int{0}\n• • 500:int{0}}\n• 100:map[int]int{nil}}"},
+		{"empty", map[int]int{}, "map[int]int{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 3, AlignMapValues: true}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, want := Sdump(map[int]int{1: 1, 10: 2}, Options{Depth: 2}), "map[int]int{\n• 1:int{1}\n• 10:int{2}}"; got != want {
+		t.Errorf("Sdump = %q without AlignMapValues, want %q", got, want)
+	}
+}
+
+type separatedLists struct {
+	L []int
+	M map[string][]int
+}
+
+// TestElementSeparator prints the entries of the slices and maps, nested or
+// elided, in the lines of their collections while the fields stay in lines.
+func TestElementSeparator(t *testing.T) {
+	v := separatedLists{[]int{1, 2}, map[string][]int{"a": {3}, "b": nil}}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"comma", v, Options{Depth: 3, ElementSeparator: ", "},
+			"gdump.separatedLists{\n• L:[]int{int{1}, int{2}}\n• M:map[string][]int{a:[]int{int{3}}, b:[]int{nil}}}"},
+		{"pipe", v, Options{Depth: 3, ElementSeparator: " | "},
+			"gdump.separatedLists{\n• L:[]int{int{1} | int{2}}\n• M:map[string][]int{a:[]int{int{3}} | b:[]int{nil}}}"},
+		{"elided", []int{1, 2, 3}, Options{Depth: 2, ElementSeparator: ", ", MaxItems: 2}, "[]int{int{1}, int{2}, …(+1 more)}"},
+		{"empty", []int{}, Options{Depth: 2, ElementSeparator: ", "}, "[]int{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type paddedFields struct {
+	A bool
+	B int64
+	C uint16
+	D *int
+}
+
+type OffsetBase struct{ Y byte }
+
+type offsetOuter struct {
+	X int32
+	OffsetBase
+	P paddedFields
+}
+
+// TestShowOffsets prints the offsets and sizes of the fields, in which the
+// padding shows as the gaps, and the offsets of the promoted fields in the
+// embedding struct.
+func TestShowOffsets(t *testing.T) {
+	pt, ot := reflect.TypeOf(paddedFields{}), reflect.TypeOf(offsetOuter{})
+	field := func(t reflect.Type, name string) string {
+		f, _ := t.FieldByName(name)
+		return fmt.Sprintf("%s@%d+%d:", name, f.Offset, f.Type.Size())
+	}
+	if a, b := pt.Field(0), pt.Field(1); b.Offset == a.Offset+a.Type.Size() {
+		t.Fatalf("no padding between %s and %s", a.Name, b.Name)
+	}
+	v := offsetOuter{X: 1, OffsetBase: OffsetBase{2}, P: paddedFields{A: true}}
+	base, _ := ot.FieldByName("OffsetBase")
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"fields", Options{Depth: 3, ShowOffsets: true}, []string{
+			field(ot, "X") + "int32{1}", field(ot, "OffsetBase") + "gdump.OffsetBase{", field(ot, "P") + "gdump.paddedFields{",
+			"• • " + field(pt, "A") + "bool{true}", field(pt, "B") + "int64{0}", field(pt, "C") + "uint16{0}", field(pt, "D") + "*int{nil}",
+		}},
+		{"flattened", Options{Depth: 3, ShowOffsets: true, FlattenEmbedded: true}, []string{
+			fmt.Sprintf("OffsetBase.Y@%d+1:uint8{2}", base.Offset),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(v, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %s, want %q", got, want)
+				}
+			}
+		})
+	}
+	if got := Sdump(v, Options{Depth: 3}); strings.Contains(got, "@") {
+		t.Errorf("Sdump = %s without ShowOffsets, want no offsets", got)
+	}
+}
+
+type singleID struct{ V string }
+
+type singleWrap struct{ Inner singleID }
+
+type singlePair struct{ A, B int }
+
+// TestCollapseSingleField collapses the structs of a single field, nested
+// or pointed, into the lines of the structs, and leaves the others.
+func TestCollapseSingleField(t *testing.T) {
+	v := struct {
+		ID   singleID
+		W    singleWrap
+		Pair singlePair
+		P    *singleID
+	}{singleID{"x"}, singleWrap{singleID{"y"}}, singlePair{1, 2}, &singleID{"z"}}
+	const top = "struct { ID gdump.singleID; W gdump.singleWrap; Pair gdump.singlePair; P *gdump.singleID }{\n"
+	pair := "• Pair:gdump.singlePair{\n• • A:int{1}\n• • B:int{2}}\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"collapsed", Options{Depth: 4, CollapseSingleField: true},
+			top + "• ID:gdump.singleID{V:string{x}}\n• W:gdump.singleWrap{Inner:gdump.singleID{V:string{y}}}\n" + pair + "• P:*gdump.singleID{V:string{z}}}"},
+		{"as value", Options{Depth: 4, CollapseSingleField: true, SingleFieldAsValue: true},
+			top + "• ID:gdump.singleID(string{x})\n• W:gdump.singleWrap(gdump.singleID(string{y}))\n" + pair + "• P:*gdump.singleID(string{z})}"},
+		// SingleFieldAsValue applies only to the structs collapsed
+		{"as value only", Options{Depth: 4, SingleFieldAsValue: true}, Sdump(v, Options{Depth: 4})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type sparseConfig struct {
+	Name    string
+	Port    int
+	Hosts   []string
+	TLS     *singleID
+	Limits  singlePair
+	retries int
+}
+
+// TestCountZeroFields omits the zero fields and counts them after the type
+// of each struct, the empty but not nil slices being set.
+func TestCountZeroFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"sparse", sparseConfig{Port: 80, Hosts: []string{}, retries: 3}, "gdump.sparseConfig(3 set, 3 zero){\n• Port:int{80}\n• Hosts:[]string{}\n• retries:3}"},
+		{"nested", []sparseConfig{{Name: "a", Limits: singlePair{B: 1}}}, "[]gdump.sparseConfig{\n• gdump.sparseConfig(2 set, 4 zero){\n• • Name:string{a}\n• • Limits:gdump.singlePair(1 set, 1 zero){\n• • • B:int{1}}}}"},
+		{"all set", singlePair{1, 2}, "gdump.singlePair(2 set, 0 zero){\n• A:int{1}\n• B:int{2}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 3, CountZeroFields: true}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, want := Sdump(sparseConfig{Port: 80}, Options{Depth: 3, OmitZero: true}), "gdump.sparseConfig{\n• Port:int{80}}"; got != want {
+		t.Errorf("Sdump with OmitZero = %q, want %q without the counts", got, want)
+	}
+}
+
+// TestMarkers replaces the markers of the values referenced by pointers and
+// held by interfaces, while the nil pointers print their types.
+func TestMarkers(t *testing.T) {
+	n := 1
+	pn := &n
+	v := struct {
+		P  *int
+		PP **int
+		I  interface{}
+		IP interface{}
+		N  *int
+	}{&n, &pn, 2, &n, nil}
+	const top = "struct { P *int; PP **int; I interface {}; IP interface {}; N *int }{\n"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default", Options{Depth: 3}, top + "• P:#1 *int{&1}\n• PP:**int{→ #1}\n• I:○int{&2}\n• IP:○*int{→ #1}\n• N:*int{nil}}"},
+		{"ascii", Options{Depth: 3, PointerMarker: "^", InterfaceMarker: "@"}, top + "• P:#1 ^int{&1}\n• PP:^*int{→ #1}\n• I:@int{&2}\n• IP:@*int{→ #1}\n• N:*int{nil}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type bareLeaves struct {
+	N   int
+	S   string
+	L   []int
+	P   *int
+	I   interface{}
+	Sub singlePair
+}
+
+// TestBareLeavesAtMaxDepth prints the leaves at the depth limit by their
+// values, through the pointers and interfaces, and keeps the types of the
+// containers and of the leaves above the limit.
+func TestBareLeavesAtMaxDepth(t *testing.T) {
+	v := bareLeaves{1, "s", []int{2}, new(int), 3, singlePair{4, 5}}
+	tests := []struct {
+		depth int
+		value interface{}
+		want  string
+	}{
+		{0, 5, "5"},
+		{1, 5, "int{5}"},
+		{0, v, "gdump.bareLeaves{6 fields …}"},
+		{1, v, "gdump.bareLeaves{\n• N:1\n• S:s\n• L:[]int{len:1 …}\n• P:*0\n• I:○&3\n• Sub:gdump.singlePair{2 fields …}}"},
+		{2, v, "gdump.bareLeaves{\n• N:int{1}\n• S:string{s}\n• L:[]int{\n• • 2}\n• P:*int{0}\n• I:○int{&3}\n• Sub:gdump.singlePair{\n• • A:4\n• • B:5}}"},
+	}
+	for _, tt := range tests {
+		if got := Sdump(tt.value, Options{Depth: tt.depth, BareLeavesAtMaxDepth: true}); got != tt.want {
+			t.Errorf("Sdump(%T) in depth %d = %q, want %q", tt.value, tt.depth, got, tt.want)
+		}
+	}
+	if got, want := Sdump(v, Options{Depth: 1}), "• N:int{1}\n"; !strings.Contains(got, want) {
+		t.Errorf("Sdump = %q, want %q without BareLeavesAtMaxDepth", got, want)
+	}
+}
+
+type includedNode struct {
+	A    int
+	B    string
+	Next *includedNode
+	M    map[string]int
+	D    int
+}
+
+// TestValueDumpOnly prints only the included fields at every level, the
+// string map keys not included being collapsed like the excluded ones, and
+// the fields also excluded are left out.
+func TestValueDumpOnly(t *testing.T) {
+	v := includedNode{1, "b", &includedNode{A: 2, B: "x", D: 5}, map[string]int{"A": 1, "z": 2}, 4}
+	tests := []struct {
+		name     string
+		included []string
+		want     string
+	}{
+		{"nested", []string{"A", "Next"}, "gdump.includedNode{\n• A:int{1}\n• Next:*gdump.includedNode{\n• • A:int{2}\n• • Next:*gdump.includedNode{nil}}}\n"},
+		{"map keys", []string{"M", "z"}, "gdump.includedNode{\n• M:map[string]int{\n• • A: ...\n• • z:int{2}}}\n"},
+		{"none", []string{"X"}, "gdump.includedNode{}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValueDumpOnly(v, 3, nil, tt.included...); got != tt.want {
+				t.Errorf("ValueDumpOnly = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := ValueDumpOnly(v, 3, nil), ValueDump(v, 3, nil); got != want {
+		t.Errorf("ValueDumpOnly = %q without the fields, want all %q", got, want)
+	}
+	got := Sdump(v, Options{Depth: 3, IncludedField: []string{"A", "D"}, ExcludedField: []string{"D"}})
+	if want := "gdump.includedNode{\n• A:int{1}}"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+}
+
+type arrayCell struct{ A, B int }
+
+// TestArrays dumps the arrays element by element like the slices within the
+// depth and with the excluded fields, and the empty arrays without a line.
+func TestArrays(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		depth    int
+		excluded []string
+		want     string
+	}{
+		{"ints", [3]int{1, 2, 3}, 3, nil, "[3]int{1, 2, 3}\n"},
+		{"empty", [0]int{}, 3, nil, "[0]int{}\n"},
+		{"empty field", struct{ A [0]string }{}, 3, nil, "struct { A [0]string }{{[]}}\n"},
+		{"structs", [2]arrayCell{{1, 2}}, 3, []string{"B"}, "[2]gdump.arrayCell{\n• gdump.arrayCell{\n• • A:int{1}}\n• gdump.arrayCell{{0 0}}}\n"},
+		{"nested", [2][2]int{{1}}, 1, nil, "[2][2]int{\n• [2]int{len:2 …}\n• [2]int{len:2 …}}\n"},
+		{"pointer", &[2]int{}, 3, nil, "*[2]int{0, 0}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValueDump(tt.value, tt.depth, nil, tt.excluded...); got != tt.want {
+				t.Errorf("ValueDump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, want := ValueDumpInline([0]int{}, 1, nil), "[0]int{}"; got != want {
+		t.Errorf("ValueDumpInline = %q, want %q", got, want)
+	}
+}
+
+type excludedCred struct{ Password, Token string }
+
+type excludedUser struct {
+	ID          int
+	Credentials excludedCred
+}
+
+type excludedRoot struct {
+	User  excludedUser
+	Users []excludedUser
+	ID    int
+}
+
+// TestExcludedPaths excludes the dotted entries by the paths from the root,
+// with * for a single level and ** for any levels, the bare names at any
+// depth and the fields of the excluded types.
+func TestExcludedPaths(t *testing.T) {
+	v := excludedRoot{
+		User:  excludedUser{1, excludedCred{"p1", "t1"}},
+		Users: []excludedUser{{2, excludedCred{"p2", "t2"}}},
+		ID:    9,
+	}
+	user := func(id, cred string) string {
+		return "gdump.excludedUser{" + id + cred + "}"
+	}
+	tests := []struct {
+		name     string
+		excluded []string
+		types    []reflect.Type
+		user     string
+		users    string
+		id       string
+	}{
+		{"full path", []string{"User.Credentials.Password"}, nil,
+			user("\n• • ID:int{1}", "\n• • Credentials:gdump.excludedCred{\n• • • Token:string{t1}}"),
+			user("\n• • • ID:int{2}", "\n• • • Credentials:gdump.excludedCred{\n• • • • Password:string{p2}\n• • • • Token:string{t2}}"),
+			"\n• ID:int{9}"},
+		{"bare name", []string{"ID"}, nil,
+			user("", "\n• • Credentials:gdump.excludedCred{\n• • • Password:string{p1}\n• • • Token:string{t1}}"),
+			user("", "\n• • • Credentials:gdump.excludedCred{\n• • • • Password:string{p2}\n• • • • Token:string{t2}}"),
+			""},
+		{"single level", []string{"Users.*.Credentials"}, nil,
+			user("\n• • ID:int{1}", "\n• • Credentials:gdump.excludedCred{\n• • • Password:string{p1}\n• • • Token:string{t1}}"),
+			user("\n• • • ID:int{2}", ""),
+			"\n• ID:int{9}"},
+		{"any levels", []string{"**.Password"}, nil,
+			user("\n• • ID:int{1}", "\n• • Credentials:gdump.excludedCred{\n• • • Token:string{t1}}"),
+			user("\n• • • ID:int{2}", "\n• • • Credentials:gdump.excludedCred{\n• • • • Token:string{t2}}"),
+			"\n• ID:int{9}"},
+		{"types", nil, []reflect.Type{reflect.TypeOf(excludedCred{})},
+			user("\n• • ID:int{1}", ""),
+			user("\n• • • ID:int{2}", ""),
+			"\n• ID:int{9}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(v, Options{Depth: 4, ExcludedField: tt.excluded, ExcludedTypes: tt.types})
+			want := "gdump.excludedRoot{\n• User:" + tt.user + "\n• Users:[]gdump.excludedUser{\n• • " + tt.users + "}" + tt.id + "}"
+			if got != want {
+				t.Errorf("Sdump = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+type inlineNode struct {
+	S    string
+	N    []int
+	M    map[string]string
+	Next *inlineNode
+}
+
+// TestInlineLine dumps the values in a single line with single spaces, the
+// strings and the map keys with newlines quoted to keep their contents.
+func TestInlineLine(t *testing.T) {
+	v := inlineNode{S: "a\nb", N: []int{1, 2}, M: map[string]string{"k\n": "v", "x": "y\r\n"}, Next: &inlineNode{S: "c"}}
+	got := ValueDumpInline(v, 3, nil)
+	want := `gdump.inlineNode{S:string{"a\nb"} N:[]int{1, 2} M:map[string]string{"k\n":string{v} x:string{"y\r\n"}} ` +
+		`Next:*gdump.inlineNode{S:string{c} N:[]int{nil} M:map[string]string{nil} Next:*gdump.inlineNode{nil}}}`
+	if got != want {
+		t.Errorf("ValueDumpInline = %q, want %q", got, want)
+	}
+	if strings.ContainsAny(got, "\r\n") || strings.Contains(got, "  ") || strings.Contains(got, "•") {
+		t.Errorf("ValueDumpInline = %q, want a line with single spaces", got)
+	}
+	if got, want := ValueDumpInline("a b", 1, nil), "string{a b}"; got != want {
+		t.Errorf("ValueDumpInline = %q, want %q", got, want)
+	}
+}
+
+type sameTypedNode struct {
+	Name        string
+	Left, Right *sameTypedNode
+	Kids        []sameTypedNode
+}
+
+// TestSameTypedFields expands the fields and the children of the enclosing
+// type to the depth, the cycles being cut by the references instead.
+func TestSameTypedFields(t *testing.T) {
+	v := sameTypedNode{Name: "root", Left: &sameTypedNode{Name: "l"}, Right: &sameTypedNode{Name: "r", Left: &sameTypedNode{Name: "rl"}}, Kids: []sameTypedNode{{Name: "k"}}}
+	const empty = "Left:*gdump.sameTypedNode{nil}\n%[1]sRight:*gdump.sameTypedNode{nil}\n%[1]sKids:[]gdump.sameTypedNode{nil}}"
+	want := "gdump.sameTypedNode{\n• Name:string{root}" +
+		"\n• Left:*gdump.sameTypedNode{\n• • Name:string{l}\n• • " + fmt.Sprintf(empty, "• • ") +
+		"\n• Right:*gdump.sameTypedNode{\n• • Name:string{r}" +
+		"\n• • Left:*gdump.sameTypedNode{\n• • • Name:string{rl}\n• • • " + fmt.Sprintf(empty, "• • • ") +
+		"\n• • Right:*gdump.sameTypedNode{nil}\n• • Kids:[]gdump.sameTypedNode{nil}}" +
+		"\n• Kids:[]gdump.sameTypedNode{\n• • gdump.sameTypedNode{\n• • • Name:string{k}\n• • • " + fmt.Sprintf(empty, "• • • ") + "}}"
+	if got := Sdump(v, Options{Depth: 3}); got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+
+	cyclic := &sameTypedNode{Name: "c"}
+	cyclic.Left = cyclic
+	got := Sdump(cyclic, Options{Depth: 5})
+	if want := "#1 *gdump.sameTypedNode{\n• Name:string{c}\n• Left:*gdump.sameTypedNode{↩ #1}\n• Right:*gdump.sameTypedNode{nil}\n• Kids:[]gdump.sameTypedNode{nil}}"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+}
+
+type includedAddr struct{ City, Zip string }
+
+type includedPerson struct {
+	Name string
+	Home includedAddr
+	Work *includedAddr
+	Pets []includedAddr
+	M    map[string]includedAddr
+}
+
+// TestIncludedPaths prints only the included paths with their ancestors and
+// descendants, eliding the rest, and the leaves ahead of ** only if matched.
+func TestIncludedPaths(t *testing.T) {
+	v := includedPerson{"a", includedAddr{"c", "z"}, &includedAddr{"w", "wz"}, []includedAddr{{"p", "pz"}}, map[string]includedAddr{"k": {"m", "mz"}}}
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"Name"}, "gdump.includedPerson{\n• Name:string{a}\n• …(+4 more)}"},
+		{[]string{"Home"}, "gdump.includedPerson{\n• Home:gdump.includedAddr{\n• • City:string{c}\n• • Zip:string{z}}\n• …(+4 more)}"},
+		{[]string{"Work.Zip", "Name"}, "gdump.includedPerson{\n• Name:string{a}\n• Work:*gdump.includedAddr{\n• • Zip:string{wz}\n• • …(+1 more)}\n• …(+3 more)}"},
+		{[]string{"Pets.*.City"}, "gdump.includedPerson{\n• Pets:[]gdump.includedAddr{\n• • gdump.includedAddr{\n• • • City:string{p}\n• • • …(+1 more)}}\n• …(+4 more)}"},
+		{[]string{"M.k.Zip"}, "gdump.includedPerson{\n• M:map[string]gdump.includedAddr{\n• • k:gdump.includedAddr{\n• • • Zip:string{mz}\n• • • …(+1 more)}}\n• …(+4 more)}"},
+		{[]string{"**.City"}, "gdump.includedPerson{" +
+			"\n• Home:gdump.includedAddr{\n• • City:string{c}\n• • …(+1 more)}" +
+			"\n• Work:*gdump.includedAddr{\n• • City:string{w}\n• • …(+1 more)}" +
+			"\n• Pets:[]gdump.includedAddr{\n• • gdump.includedAddr{\n• • • City:string{p}\n• • • …(+1 more)}}" +
+			"\n• M:map[string]gdump.includedAddr{\n• • k:gdump.includedAddr{\n• • • City:string{m}\n• • • …(+1 more)}}" +
+			"\n• …(+1 more)}"},
+		{[]string{"Nothing"}, "gdump.includedPerson{\n• …(+5 more)}"},
+	}
+	for _, tt := range tests {
+		if got := Sdump(v, Options{Depth: 3, IncludedPaths: tt.paths}); got != tt.want {
+			t.Errorf("Sdump with %q = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+
+	got := Sdump(v, Options{Depth: 3, IncludedPaths: []string{"Home"}, ExcludedField: []string{"Zip"}, Format: FormatJSON})
+	if want := "{\n  \"Home\": {\n    \"City\": \"c\"\n  }\n}"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+}
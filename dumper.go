@@ -0,0 +1,369 @@
+package gdump
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// Dumper - a dumper having its own options, so that the dumpers configured
+// differently can be used together in a program. A Dumper is safe for the
+// concurrent use by multiple goroutines. Its options are copied by New and
+// only changed by RegisterFormatter, which is guarded against the dumps.
+type Dumper struct {
+	mu    sync.RWMutex
+	opts  Options
+	w     io.Writer
+	color *bool // colors the output if set, or if the writer is a terminal if nil
+}
+
+// Option - a function configuring a Dumper created by New
+type Option func(d *Dumper)
+
+// New returns a Dumper configured by opts. It starts from the options of the
+// package-level functions at the time of the call and writes to os.Stdout.
+func New(opts ...Option) *Dumper {
+	d := &Dumper{opts: globalOptions(DefaultPrintDepth), w: os.Stdout}
+	for _, opt := range opts {
+		opt(d)
+	}
+	// not shared with the options given by WithOptions
+	d.opts = d.opts.clone()
+	d.opts.rules = newFieldRules(d.opts)
+	return d
+}
+
+// WithOptions - replaces all the options of the Dumper with opts
+func WithOptions(opts Options) Option {
+	return func(d *Dumper) {
+		d.opts = opts
+	}
+}
+
+// WithDepth - sets the print depth of the dumped values
+func WithDepth(depth int) Option {
+	return func(d *Dumper) {
+		d.opts.Depth = depth
+	}
+}
+
+// WithIndent - sets the indentation added to each nested level, e.g. "  "
+func WithIndent(unit string) Option {
+	return func(d *Dumper) {
+		d.opts.IndentUnit = unit
+	}
+}
+
+// WithBullet - sets the marker put in place of the last indentation unit of
+// the lines, e.g. "- "
+func WithBullet(bullet string) Option {
+	return func(d *Dumper) {
+		d.opts.Bullet = bullet
+	}
+}
+
+// WithCompactWidth - prints the nested structs, slices and maps in a line if
+// they fit in width runes
+func WithCompactWidth(width int) Option {
+	return func(d *Dumper) {
+		d.opts.CompactWidth = width
+	}
+}
+
+// WithExcludedFields - adds the names of the struct fields and string map
+// keys not printed. See Options.ExcludedField.
+func WithExcludedFields(names ...string) Option {
+	return func(d *Dumper) {
+		d.opts.ExcludedField = append(d.opts.ExcludedField, names...)
+	}
+}
+
+// WithIncludedFields - adds the paths of the struct fields and string map
+// keys only printed. See Options.IncludedPaths.
+func WithIncludedFields(paths ...string) Option {
+	return func(d *Dumper) {
+		d.opts.IncludedPaths = append(d.opts.IncludedPaths, paths...)
+	}
+}
+
+// WithFlattenEmbedded - prints the promoted fields of the embedded structs
+// at the level of the embedding struct if enabled. See
+// Options.FlattenEmbedded.
+func WithFlattenEmbedded(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.FlattenEmbedded = enabled
+	}
+}
+
+// WithFieldTags - prints the struct tags, the exported state, and the
+// offsets and sizes of the struct fields if enabled. See
+// Options.ShowFieldTags.
+func WithFieldTags(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.ShowFieldTags = enabled
+	}
+}
+
+// WithRawStdTypes - prints the standard types like time.Time and net.IP as
+// their fields or elements if enabled. See Options.RawStdTypes.
+func WithRawStdTypes(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.RawStdTypes = enabled
+	}
+}
+
+// WithStringer - prints the values implementing error, fmt.Stringer and the
+// other interfaces of Options.RendererPriority via their methods if enabled
+func WithStringer(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.UseStringer = enabled
+	}
+}
+
+// WithIgnoreStringer - prints the values of types by their fields even if
+// their methods are called by WithStringer
+func WithIgnoreStringer(types ...reflect.Type) Option {
+	return func(d *Dumper) {
+		d.opts.IgnoreStringer = append(d.opts.IgnoreStringer, types...)
+	}
+}
+
+// WithErrorChain - prints the wrapped errors as their unwrap chains if
+// enabled. The errors of the chains are printed by Error() if byError is
+// set, otherwise by their fields. See Options.UnwrapErrors.
+func WithErrorChain(enabled, byError bool) Option {
+	return func(d *Dumper) {
+		d.opts.UnwrapErrors = enabled
+		d.opts.UseStringer = byError
+	}
+}
+
+// WithSortMapKeys - prints the map entries in the order of their keys if
+// enabled, otherwise in the iteration order of the maps for speed
+func WithSortMapKeys(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.KeepMapOrder = !enabled
+	}
+}
+
+// WithMaxElements - prints the first n elements and entries of the slices
+// and maps followed by …(+N more). 0 means unlimited.
+func WithMaxElements(n int) Option {
+	return func(d *Dumper) {
+		d.opts.MaxItems = n
+	}
+}
+
+// WithMaxStringLen - prints the first n runes of the strings and bytes of
+// the byte slices followed by …(+N more). 0 means unlimited.
+func WithMaxStringLen(n int) Option {
+	return func(d *Dumper) {
+		d.opts.MaxStringLen = n
+	}
+}
+
+// WithRawStrings - prints the strings as they are without quoting the
+// strings having newlines, control characters or invalid UTF-8 if enabled.
+// See Options.RawStrings.
+func WithRawStrings(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.RawStrings = enabled
+	}
+}
+
+// WithMultilineStrings - prints the lines of the strings having newlines
+// in the indented lines if enabled. See Options.MultilineStrings.
+func WithMultilineStrings(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.MultilineStrings = enabled
+	}
+}
+
+// WithMaxOutputBytes - stops the dump and cuts the output at n bytes with a
+// truncation mark. See Options.MaxOutputBytes.
+func WithMaxOutputBytes(n int) Option {
+	return func(d *Dumper) {
+		d.opts.MaxOutputBytes = n
+	}
+}
+
+// WithTable - prints the slices of structs as the tables of their fields if
+// enabled. The cells are truncated to width runes unless width is 0.
+func WithTable(enabled bool, width int) Option {
+	return func(d *Dumper) {
+		d.opts.TableSlices = enabled
+		d.opts.MaxColumnWidth = width
+	}
+}
+
+// WithBytesFormat - sets the format of the byte slices and arrays, e.g.
+// BytesHexdump
+func WithBytesFormat(format BytesFormat) Option {
+	return func(d *Dumper) {
+		d.opts.BytesFormat = format
+	}
+}
+
+// WithUnexported - dumps the unexported struct fields by reading them via
+// unsafe if enabled. See Options.ShowUnexported.
+func WithUnexported(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.ShowUnexported = enabled
+	}
+}
+
+// WithAddresses - prints the addresses of the pointers if enabled, so that
+// the values shared by the pointers, which are printed once numbered as #N
+// and then referred to as → #N, can be told apart by their addresses
+func WithAddresses(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.ShowPointerAddr = enabled
+	}
+}
+
+// WithRedaction - prints the struct fields and string map keys named in
+// names or matching pattern as ***REDACTED***. pattern can be nil to keep
+// the pattern set, e.g. DefaultRedactPattern set by RedactSecrets.
+func WithRedaction(pattern *regexp.Regexp, names ...string) Option {
+	return func(d *Dumper) {
+		if pattern != nil {
+			d.opts.RedactPattern = pattern
+		}
+		d.opts.RedactFields = append(d.opts.RedactFields, names...)
+	}
+}
+
+// WithDefaultRedaction - redacts the fields and keys named like the secrets
+// by DefaultRedactPattern if enabled, or disables the pattern otherwise.
+// See RedactSecrets.
+func WithDefaultRedaction(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.RedactPattern = nil
+		if enabled {
+			d.opts.RedactPattern = DefaultRedactPattern
+		}
+	}
+}
+
+// WithExcludedTypes - adds the types of the struct fields not printed, e.g.
+// reflect.TypeOf(sync.Mutex{})
+func WithExcludedTypes(types ...reflect.Type) Option {
+	return func(d *Dumper) {
+		d.opts.ExcludedTypes = append(d.opts.ExcludedTypes, types...)
+	}
+}
+
+// WithTypeDepth - sets the print depth of the values of the type t and the
+// pointers to them, e.g. WithTypeDepth(reflect.TypeOf(grpc.ClientConn{}), 0)
+func WithTypeDepth(t reflect.Type, depth int) Option {
+	return func(d *Dumper) {
+		types := make(map[reflect.Type]int, len(d.opts.TypeDepth)+1)
+		for k, v := range d.opts.TypeDepth {
+			types[k] = v
+		}
+		types[t] = depth
+		d.opts.TypeDepth = types
+	}
+}
+
+// WithFieldFilter - adds the filter of the struct fields printed. The fields
+// are printed if all the filters added return true. See Options.FieldFilter.
+func WithFieldFilter(filter FieldFilterFunc) Option {
+	return func(d *Dumper) {
+		if prev := d.opts.FieldFilter; prev != nil {
+			d.opts.FieldFilter = func(owner reflect.Value, f reflect.StructField, v reflect.Value) bool {
+				return prev(owner, f, v) && filter(owner, f, v)
+			}
+			return
+		}
+		d.opts.FieldFilter = filter
+	}
+}
+
+// WithPlainEllipsis - prints the collapsed children at the depth limit as
+// "..." instead of the summaries of the values if enabled. See
+// Options.PlainEllipsis.
+func WithPlainEllipsis(enabled bool) Option {
+	return func(d *Dumper) {
+		d.opts.PlainEllipsis = enabled
+	}
+}
+
+// WithRenderer - renders the trees of the dumps by the Renderer returned by
+// newRenderer for each dump instead of the Format, e.g.
+// WithRenderer(NewDefaultRenderer)
+func WithRenderer(newRenderer func() Renderer) Option {
+	return func(d *Dumper) {
+		d.opts.NewRenderer = newRenderer
+	}
+}
+
+// WithFormat - sets the output format, e.g. FormatJSON or FormatYAML
+func WithFormat(format Format) Option {
+	return func(d *Dumper) {
+		d.opts.Format = format
+	}
+}
+
+// WithColor - colors the output by the ANSI escape codes if enabled. Without
+// this option, the output written to a terminal is colored.
+func WithColor(enabled bool) Option {
+	return func(d *Dumper) {
+		d.color = &enabled
+	}
+}
+
+// WithWriter - sets the writer of Dump
+func WithWriter(w io.Writer) Option {
+	return func(d *Dumper) {
+		d.w = w
+	}
+}
+
+// Options returns a copy of the options of d.
+func (d *Dumper) Options() Options {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.opts.clone()
+}
+
+// optionsFor returns the options of d writing to w. The options are colored
+// if WithColor is enabled or, without WithColor, if w is a terminal.
+func (d *Dumper) optionsFor(w io.Writer) Options {
+	d.mu.RLock()
+	opts := d.opts
+	d.mu.RUnlock()
+	switch {
+	case d.color != nil:
+		opts.Color = *d.color
+	case w != nil:
+		opts.Color = isTerminal(w)
+	default:
+		opts.Color = false
+	}
+	return opts
+}
+
+// Sdump returns a string representation of value dumped with the options of
+// d. It is colored only if WithColor is enabled.
+func (d *Dumper) Sdump(value interface{}) string {
+	return d.optionsFor(nil).Dump(value)
+}
+
+// Dump writes the string representation of value to the writer of d.
+// It returns the number of bytes written and any write error.
+func (d *Dumper) Dump(value interface{}) (int, error) {
+	return d.DumpTo(d.w, value)
+}
+
+// DumpTo writes the string representation of value to w instead of the
+// writer of d. The text dump is written while it is dumped, so a large value
+// is not held in memory as a whole unless an option changes the whole dump,
+// e.g. WithBullet. It returns the number of bytes written and any write
+// error, which stops the dump.
+func (d *Dumper) DumpTo(w io.Writer, value interface{}) (int, error) {
+	return d.optionsFor(w).dumpTo(context.Background(), w, value)
+}
@@ -0,0 +1,129 @@
+package gdump
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDumperImmutable(t *testing.T) {
+	opts := globalOptions(2)
+	opts.ExcludedField = []string{"A"}
+	d := New(WithOptions(opts))
+	opts.ExcludedField[0] = "B"
+	got := d.Options()
+	if !reflect.DeepEqual(got.ExcludedField, []string{"A"}) {
+		t.Errorf("ExcludedField = %q after changing the options given, want [A]", got.ExcludedField)
+	}
+	got.ExcludedField[0] = "C"
+	if got := d.Options().ExcludedField; got[0] != "A" {
+		t.Errorf("ExcludedField = %q after changing the options returned, want [A]", got)
+	}
+
+	defer func(newline bool) { NewlineAtEnd = newline }(NewlineAtEnd)
+	NewlineAtEnd = true
+	d = New(WithDepth(1))
+	NewlineAtEnd = false
+	if got, want := d.Sdump(1), "int{1}\n"; got != want {
+		t.Errorf("Sdump = %q after changing NewlineAtEnd, want %q", got, want)
+	}
+}
+
+// TestDumperOptions sets the options of the Dumpers by the functional
+// options, the later ones taking over the earlier ones.
+func TestDumperOptions(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	tests := []struct {
+		name string
+		opts []Option
+		ok   func(o Options) bool
+	}{
+		{"depth", []Option{WithDepth(7)}, func(o Options) bool { return o.Depth == 7 }},
+		{"indent", []Option{WithIndent("  "), WithBullet("- ")}, func(o Options) bool { return o.IndentUnit == "  " && o.Bullet == "- " }},
+		{"compact width", []Option{WithCompactWidth(40)}, func(o Options) bool { return o.CompactWidth == 40 }},
+		{"fields", []Option{WithExcludedFields("A"), WithExcludedFields("B"), WithIncludedFields("C.D")},
+			func(o Options) bool {
+				return reflect.DeepEqual(o.ExcludedField, []string{"A", "B"}) && reflect.DeepEqual(o.IncludedPaths, []string{"C.D"})
+			}},
+		{"stringer", []Option{WithStringer(true), WithIgnoreStringer(intType)},
+			func(o Options) bool {
+				return o.UseStringer && reflect.DeepEqual(o.IgnoreStringer, []reflect.Type{intType})
+			}},
+		{"error chain", []Option{WithStringer(true), WithErrorChain(true, false)}, func(o Options) bool { return o.UnwrapErrors && !o.UseStringer }},
+		{"unsorted", []Option{WithSortMapKeys(false)}, func(o Options) bool { return o.KeepMapOrder }},
+		{"limits", []Option{WithMaxElements(3), WithMaxStringLen(4), WithMaxOutputBytes(5)},
+			func(o Options) bool { return o.MaxItems == 3 && o.MaxStringLen == 4 && o.MaxOutputBytes == 5 }},
+		{"strings", []Option{WithRawStrings(true), WithMultilineStrings(true)}, func(o Options) bool { return o.RawStrings && o.MultilineStrings }},
+		{"table", []Option{WithTable(true, 12)}, func(o Options) bool { return o.TableSlices && o.MaxColumnWidth == 12 }},
+		{"bytes", []Option{WithBytesFormat(BytesBase64)}, func(o Options) bool { return o.BytesFormat == BytesBase64 }},
+		{"flags", []Option{WithFlattenEmbedded(true), WithFieldTags(true), WithRawStdTypes(true), WithUnexported(true), WithAddresses(true), WithPlainEllipsis(true)},
+			func(o Options) bool {
+				return o.FlattenEmbedded && o.ShowFieldTags && o.RawStdTypes && o.ShowUnexported && o.ShowPointerAddr && o.PlainEllipsis
+			}},
+		{"type depth", []Option{WithTypeDepth(intType, 1), WithTypeDepth(intType, 2), WithExcludedTypes(intType)},
+			func(o Options) bool {
+				return o.TypeDepth[intType] == 2 && len(o.TypeDepth) == 1 && o.ExcludedTypes[0] == intType
+			}},
+		{"redaction", []Option{WithDefaultRedaction(true), WithRedaction(nil, "PIN")},
+			func(o Options) bool {
+				return o.RedactPattern == DefaultRedactPattern && reflect.DeepEqual(o.RedactFields, []string{"PIN"})
+			}},
+		{"no redaction", []Option{WithDefaultRedaction(true), WithDefaultRedaction(false)}, func(o Options) bool { return o.RedactPattern == nil }},
+		{"format", []Option{WithFormat(FormatJSON), WithFormat(FormatYAML)}, func(o Options) bool { return o.Format == FormatYAML }},
+		{"replaced", []Option{WithDepth(7), WithOptions(Options{Depth: 2})}, func(o Options) bool { return o.Depth == 2 && o.IndentUnit == "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if o := New(tt.opts...).Options(); !tt.ok(o) {
+				t.Errorf("Options = %+v", o)
+			}
+		})
+	}
+
+	var b bytes.Buffer
+	d := New(WithDepth(1), WithWriter(&b), WithColor(false))
+	if _, err := d.Dump([]int{1}); err != nil || b.String() != d.Sdump([]int{1}) {
+		t.Errorf("Dump = %q, %v, want %q", b.String(), err, d.Sdump([]int{1}))
+	}
+	if got := New(WithDepth(1), WithColor(true)).Sdump(1); !strings.Contains(got, "\x1b[") {
+		t.Errorf("Sdump = %q, want colored", got)
+	}
+}
+
+type concurrentNode struct {
+	Name  string
+	Items []int
+	M     map[string]*concurrentNode
+	When  time.Time
+}
+
+// TestDumperConcurrent dumps a shared value by a Dumper from goroutines
+// registering the formatters at the same time; run it with -race.
+func TestDumperConcurrent(t *testing.T) {
+	v := &concurrentNode{Name: "a", Items: []int{1, 2}, M: map[string]*concurrentNode{"x": {Name: "b"}}}
+	v.M["self"] = v
+	d := New(WithDepth(5), WithExcludedFields("Secret"))
+	want := d.Sdump(v)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				d.RegisterFormatter(reflect.TypeOf(map[int]int{}), func(v reflect.Value) string { return "m" })
+			}
+			for j := 0; j < 20; j++ {
+				if got := d.Sdump(v); got != want {
+					t.Errorf("concurrent Sdump =\n%s\nwant\n%s", got, want)
+					return
+				}
+				_ = Sdump(v, globalOptions(3))
+				_ = d.Options()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,62 @@
+package gdump
+
+import "reflect"
+
+// structField - a field of the struct dumped, which is declared by the
+// struct or promoted from an embedded struct by FlattenEmbedded
+type structField struct {
+	owner  reflect.Type  // the struct type declaring the field
+	parent reflect.Value // the struct declaring the field, of the type owner
+	*fieldInfo
+	fv     reflect.Value
+	offset uintptr // the offset in the struct dumped, or in the struct pointed
+	from   string  // the embedded fields promoting the field, e.g. "Base."
+}
+
+// structFields returns the fields of the struct v printed. The fields of
+// the embedded structs and the pointers to them are returned in place of
+// the embedded fields if FlattenEmbedded is set.
+func (s *dumpState) structFields(v reflect.Value) []structField {
+	fields := make([]structField, 0, v.NumField())
+	return s.appendFields(fields, v, 0, "", map[reflect.Type]bool{v.Type(): true})
+}
+
+// appendFields appends the fields of the struct v promoted from the
+// embedded fields of from to fields. embedding holds the types of v and its
+// embedding structs not to flatten a struct embedding itself by a pointer.
+func (s *dumpState) appendFields(fields []structField, v reflect.Value, offset uintptr, from string, embedding map[reflect.Type]bool) []structField {
+	t := v.Type()
+	infos := typeFields(t)
+	for i := range infos {
+		info, fv := &infos[i], v.Field(i)
+		ft := info.ft
+		if ev, ok := s.embeddedStruct(ft, fv, info.tag); ok && !embedding[ev.Type()] {
+			embedding[ev.Type()] = true
+			eoffset := uintptr(0)
+			if fv.Kind() == reflect.Struct {
+				eoffset = offset + ft.Offset
+			}
+			fields = s.appendFields(fields, ev, eoffset, from+ft.Name+".", embedding)
+			delete(embedding, ev.Type())
+			continue
+		}
+		fields = append(fields, structField{owner: t, parent: v, fieldInfo: info, fv: fv, offset: offset + ft.Offset, from: from})
+	}
+	return fields
+}
+
+// embeddedStruct returns the struct embedded by the field ft of the value fv
+// tagged with tag if it is flattened by FlattenEmbedded. The embedded fields
+// not printed and the nil pointers are not flattened.
+func (s *dumpState) embeddedStruct(ft reflect.StructField, fv reflect.Value, tag fieldTag) (reflect.Value, bool) {
+	if !s.FlattenEmbedded || !ft.Anonymous {
+		return reflect.Value{}, false
+	}
+	if tag.skip || s.isOmittedField(ft.Name) || s.isExcludedType(ft.Type) {
+		return reflect.Value{}, false
+	}
+	if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+	return fv, fv.Kind() == reflect.Struct
+}
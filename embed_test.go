@@ -0,0 +1,53 @@
+package gdump
+
+import "testing"
+
+type EmbedMeta struct {
+	ID   int
+	Name string
+}
+
+type EmbedAudit struct {
+	By string
+	EmbedMeta
+}
+
+type embedDoc struct {
+	EmbedMeta
+	*EmbedAudit
+	Name string
+}
+
+// TestFlattenEmbedded prints the fields of the embedded structs and the
+// pointers to them at the level of the embedding struct, named by the
+// embedded fields also in the documents, and the nil pointers as they are.
+func TestFlattenEmbedded(t *testing.T) {
+	v := embedDoc{EmbedMeta{1, "meta"}, &EmbedAudit{"bob", EmbedMeta{2, "inner"}}, "doc"}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"nested", v, Options{Depth: 3},
+			"gdump.embedDoc{\n• EmbedMeta:gdump.EmbedMeta{\n• • ID:int{1}\n• • Name:string{meta}}" +
+				"\n• EmbedAudit:*gdump.EmbedAudit{\n• • By:string{bob}\n• • EmbedMeta:gdump.EmbedMeta{\n• • • ID:int{2}\n• • • Name:string{inner}}}\n• Name:string{doc}}"},
+		{"flattened", v, Options{Depth: 3, FlattenEmbedded: true},
+			"gdump.embedDoc{\n• EmbedMeta.ID:int{1}\n• EmbedMeta.Name:string{meta}\n• EmbedAudit.By:string{bob}" +
+				"\n• EmbedAudit.EmbedMeta.ID:int{2}\n• EmbedAudit.EmbedMeta.Name:string{inner}\n• Name:string{doc}}"},
+		{"nil pointer", embedDoc{Name: "n"}, Options{Depth: 3, FlattenEmbedded: true},
+			"gdump.embedDoc{\n• EmbedMeta.ID:int{0}\n• EmbedMeta.Name:string{}\n• EmbedAudit:*gdump.EmbedAudit{nil}\n• Name:string{n}}"},
+		{"json", v, Options{Depth: 3, FlattenEmbedded: true, Format: FormatJSON},
+			"{\n  \"EmbedMeta.ID\": 1,\n  \"EmbedMeta.Name\": \"meta\",\n  \"EmbedAudit.By\": \"bob\"," +
+				"\n  \"EmbedAudit.EmbedMeta.ID\": 2,\n  \"EmbedAudit.EmbedMeta.Name\": \"inner\",\n  \"Name\": \"doc\"\n}"},
+		{"excluded", v, Options{Depth: 3, FlattenEmbedded: true, ExcludedField: []string{"EmbedAudit", "ID"}},
+			"gdump.embedDoc{\n• EmbedMeta.Name:string{meta}\n• Name:string{doc}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
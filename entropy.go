@@ -0,0 +1,49 @@
+package gdump
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultEntropyThreshold - the entropy in bits per character above which
+// the strings are redacted by Options.RedactHighEntropy if
+// Options.EntropyThreshold is not set
+var DefaultEntropyThreshold = 4.0
+
+// minEntropyLength - the length of the shortest strings redacted by entropy
+const minEntropyLength = 16
+
+// redactedValue - the value printed instead of the redacted values
+const redactedValue = "***REDACTED***"
+
+// entropy returns the Shannon entropy of the characters of str in bits per
+// character.
+func entropy(str string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range str {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// isHighEntropy returns true if str looks like a secret such as a token or
+// key. The strings shorter than minEntropyLength or having spaces are not
+// considered.
+func (s *dumpState) isHighEntropy(str string) bool {
+	if len(str) < minEntropyLength || strings.IndexFunc(str, unicode.IsSpace) >= 0 {
+		return false
+	}
+	threshold := s.EntropyThreshold
+	if threshold <= 0 {
+		threshold = DefaultEntropyThreshold
+	}
+	return entropy(str) > threshold
+}
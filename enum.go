@@ -0,0 +1,66 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	enumMutex sync.RWMutex
+	enums     = map[reflect.Type]map[int64]string{}
+)
+
+// EnumPair - a named value of an enum type
+type EnumPair struct {
+	Value int64
+	Name  string
+}
+
+// RegisterEnum registers the names of the values of the integer type t.
+// The registered values are printed with their names, e.g.
+// main.Status{Active(1)}.
+func RegisterEnum(t reflect.Type, names map[int64]string) {
+	enumMutex.Lock()
+	defer enumMutex.Unlock()
+	if enums[t] == nil {
+		enums[t] = map[int64]string{}
+	}
+	for value, name := range names {
+		enums[t][value] = name
+	}
+}
+
+// RegisterEnumAuto registers the named values of the integer type t like
+// RegisterEnum, e.g.
+//
+//	gdump.RegisterEnumAuto(reflect.TypeOf(StatusIdle),
+//		gdump.EnumPair{Value: int64(StatusIdle), Name: "Idle"},
+//		gdump.EnumPair{Value: int64(StatusActive), Name: "Active"})
+func RegisterEnumAuto(t reflect.Type, pairs ...EnumPair) {
+	names := make(map[int64]string, len(pairs))
+	for _, p := range pairs {
+		names[p.Value] = p.Name
+	}
+	RegisterEnum(t, names)
+}
+
+// enumString returns the registered name of the integer v with its value.
+func enumString(v reflect.Value) (string, bool) {
+	var value int64
+	switch {
+	case isInt(v):
+		value = v.Int()
+	case isUint(v):
+		value = int64(v.Uint())
+	default:
+		return "", false
+	}
+	enumMutex.RLock()
+	defer enumMutex.RUnlock()
+	name, ok := enums[v.Type()][value]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s(%d)", name, value), true
+}
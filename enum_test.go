@@ -0,0 +1,56 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type enumStatus int
+
+const (
+	enumIdle enumStatus = iota
+	enumActive
+)
+
+type enumLevel uint8
+
+// TestRegisterEnumAuto prints the registered values by their names, the
+// names registered later replacing the earlier ones, and the other values
+// by their numbers.
+func TestRegisterEnumAuto(t *testing.T) {
+	RegisterEnumAuto(reflect.TypeOf(enumIdle),
+		EnumPair{Value: int64(enumIdle), Name: "Idle"},
+		EnumPair{Value: int64(enumActive), Name: "Active"})
+	RegisterEnumAuto(reflect.TypeOf(enumLevel(0)), EnumPair{Value: 255, Name: "Max"})
+	status := enumActive
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"fields", struct {
+			S enumStatus
+			U enumLevel
+			N enumStatus
+		}{enumActive, 255, 7}, Options{Depth: 2}, "struct { S gdump.enumStatus; U gdump.enumLevel; N gdump.enumStatus }{\n• S:gdump.enumStatus{Active(1)}\n• U:gdump.enumLevel{Max(255)}\n• N:gdump.enumStatus{7}}"},
+		{"pointer", &status, Options{Depth: 2}, "*gdump.enumStatus{Active(1)}"},
+		{"interface", []interface{}{enumIdle}, Options{Depth: 2}, "[]interface {}{\n• ○gdump.enumStatus{Idle(0)}}"},
+		{"json", []enumStatus{enumIdle, enumActive}, Options{Depth: 2, Format: FormatJSON}, "[\n  \"Idle(0)\",\n  \"Active(1)\"\n]"},
+		// the Go syntax keeps the values compilable
+		{"go", []enumStatus{enumIdle, enumActive}, Options{Depth: 2, Format: FormatGo}, "[]gdump.enumStatus{0, 1}"},
+		{"not registered", []int{0, 1}, Options{Depth: 2}, "[]int{\n• int{0}\n• int{1}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	RegisterEnum(reflect.TypeOf(enumIdle), map[int64]string{int64(enumActive): "Running"})
+	if got, want := Sdump([]enumStatus{enumIdle, enumActive}, Options{Depth: 2, ElementSeparator: ", "}), "[]gdump.enumStatus{gdump.enumStatus{Idle(0)}, gdump.enumStatus{Running(1)}}"; got != want {
+		t.Errorf("Sdump = %q after renaming, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// errorLinkMarker - the separator of the errors of an unwrap chain
+const errorLinkMarker = " → "
+
+// maxErrorLinks - the maximum number of the errors of an unwrap chain
+// printed, not to loop forever on an error unwrapped to itself
+const maxErrorLinks = 32
+
+// errorChain returns the error v printed as the chain of the errors
+// unwrapped from it if UnwrapErrors is set and v wraps any error, e.g.
+// *fmt.wrapError{read config: open x: no such file or directory} →
+// *fs.PathError{open x: no such file or directory} → syscall.Errno{2}.
+// The errors joined by errors.Join are printed as the chains in brackets.
+func (s *dumpState) errorChain(v reflect.Value) (string, bool) {
+	if !s.UnwrapErrors || s.NoMethodCalls || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return "", false
+	}
+	err, ok := v.Interface().(error)
+	if !ok || !isWrapper(err) {
+		return "", false
+	}
+	return s.errorLinks(err, 0), true
+}
+
+// isWrapper returns true if err has the Unwrap method of errors.Unwrap or
+// errors.Join.
+func isWrapper(err error) bool {
+	switch err.(type) {
+	case interface{ Unwrap() error }, interface{ Unwrap() []error }:
+		return true
+	}
+	return false
+}
+
+// errorLinks returns err and the errors unwrapped from it printed in a line.
+// n is the number of the errors printed before err in the chain.
+func (s *dumpState) errorLinks(err error, n int) (out string) {
+	if n >= maxErrorLinks {
+		return "…"
+	}
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		// the message is the messages of the joined errors printed after it
+		out = s.paint(ansiType, reflect.TypeOf(err).String())
+	} else {
+		out = s.errorLink(err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			out += errorLinkMarker + fmt.Sprintf("<panic: %v>", r)
+		}
+	}()
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := e.Unwrap(); next != nil {
+			out += errorLinkMarker + s.errorLinks(next, n+1)
+		}
+	case interface{ Unwrap() []error }:
+		var chains []string
+		for _, next := range e.Unwrap() {
+			if next != nil {
+				chains = append(chains, s.errorLinks(next, n+1))
+			}
+		}
+		out += errorLinkMarker + "[" + strings.Join(chains, ", ") + "]"
+	}
+	return out
+}
+
+// errorLink returns an error of an unwrap chain printed by Error() if
+// UseStringer is set, or by its fields in a line otherwise.
+func (s *dumpState) errorLink(err error) string {
+	v := reflect.ValueOf(err)
+	if s.callsMethods(v) {
+		if value, ok := s.methodString(v, []RendererKind{RenderError}); ok {
+			return s.leaf(v, 1, inlineValue(value, true))
+		}
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// the codes like syscall.Errno, not formatted by Error()
+		return s.leaf(v, 1, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return s.leaf(v, 1, strconv.FormatUint(v.Uint(), 10))
+	}
+	sub := &dumpState{Options: s.Options}
+	sub.UnwrapErrors = false
+	sub.UseStringer = false
+	return sub.valueString(v, 1, 0, 0, "", false, true)
+}
@@ -0,0 +1,61 @@
+package gdump
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// selfError unwraps to itself forever.
+type selfError struct{}
+
+func (e *selfError) Error() string { return "self" }
+
+func (e *selfError) Unwrap() error { return e }
+
+// panicError panics in Unwrap.
+type panicError struct{}
+
+func (panicError) Error() string { return "panic" }
+
+func (panicError) Unwrap() error { panic("unwrap") }
+
+func TestErrorChain(t *testing.T) {
+	enoent := &fs.PathError{Op: "open", Path: "x", Err: syscall.ENOENT}
+	methods := Options{Depth: 3, UnwrapErrors: true, UseStringer: true}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"chain", fmt.Errorf("read config: %w", enoent), methods,
+			"*fmt.wrapError{read config: open x: no such file or directory} → *fs.PathError{open x: no such file or directory} → syscall.Errno{no such file or directory}"},
+		// the nil errors joined are left out
+		{"joined", errors.Join(errors.New("a"), nil, fmt.Errorf("b: %w", fs.ErrNotExist)), methods,
+			"*errors.joinError → [*errors.errorString{a}, *fmt.wrapError{b: file does not exist} → *errors.errorString{file does not exist}]"},
+		{"field", struct{ E error }{fmt.Errorf("x: %w", syscall.EPERM)}, methods,
+			"struct { E error }{\n• E:○*fmt.wrapError{x: operation not permitted} → syscall.Errno{operation not permitted}}"},
+		// the codes are printed as numbers without UseStringer
+		{"fields", fmt.Errorf("x: %w", syscall.EPERM), Options{Depth: 3, UnwrapErrors: true},
+			"*fmt.wrapError{msg:x: operation not permitted err:error{…}} → syscall.Errno{1}"},
+		{"panic", panicError{}, methods, "gdump.panicError{panic} → <panic: unwrap>"},
+		{"not wrapping", errors.New("plain"), methods, "*errors.errorString{plain}"},
+		{"disabled", fmt.Errorf("x: %w", enoent), Options{Depth: 3, UseStringer: true}, "*fmt.wrapError{x: open x: no such file or directory}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got := Sdump(&selfError{}, methods)
+	if n := strings.Count(got, errorLinkMarker); n != maxErrorLinks || !strings.HasSuffix(got, errorLinkMarker+"…") {
+		t.Errorf("Sdump of the error unwrapped to itself = %q, want %d links ending in …", got, maxErrorLinks)
+	}
+}
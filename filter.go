@@ -0,0 +1,15 @@
+package gdump
+
+import "reflect"
+
+// FieldFilterFunc - a function returning false if the field f of the struct
+// owner having the value v is not printed, e.g. to skip the internal state
+// of the generated types. owner is addressable if the struct is dumped by
+// its pointer.
+type FieldFilterFunc func(owner reflect.Value, f reflect.StructField, v reflect.Value) bool
+
+// isFilteredField returns true if the struct field f is not printed by
+// Options.FieldFilter.
+func (s *dumpState) isFilteredField(f structField) bool {
+	return s.FieldFilter != nil && !s.FieldFilter(f.parent, f.ft, f.fv)
+}
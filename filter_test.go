@@ -0,0 +1,50 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type filterBase struct {
+	ID int
+}
+
+type filterItem struct {
+	filterBase
+	Name string
+	Hide bool
+}
+
+// TestFieldFilterOwner filters the fields by the structs declaring them,
+// i.e. the embedded structs for the fields promoted, which are addressable
+// if dumped by their pointers.
+func TestFieldFilterOwner(t *testing.T) {
+	var owners []string
+	var addressable bool
+	filter := func(owner reflect.Value, f reflect.StructField, v reflect.Value) bool {
+		owners = append(owners, owner.Type().Name()+"."+f.Name)
+		if owner.CanAddr() {
+			addressable = true
+		}
+		return owner.Type() != reflect.TypeOf(filterItem{}) || f.Name != "Name" || !owner.FieldByName("Hide").Bool()
+	}
+	v := &filterItem{filterBase{1}, "a", true}
+	got := New(WithFieldFilter(filter), WithFlattenEmbedded(true)).Sdump(v)
+	if strings.Contains(got, "Name:") || !strings.Contains(got, "ID:int{1}") {
+		t.Errorf("Sdump = %s, want ID and not Name", got)
+	}
+	want := []string{"filterBase.ID", "filterItem.Name", "filterItem.Hide"}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("owners = %q, want %q", owners, want)
+	}
+	if !addressable {
+		t.Error("owners not addressable for a pointer dumped")
+	}
+
+	owners, addressable = nil, false
+	_ = New(WithFieldFilter(filter)).Sdump(*v)
+	if addressable {
+		t.Error("owners addressable for a value dumped")
+	}
+}
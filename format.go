@@ -0,0 +1,114 @@
+package gdump
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// format returns v formatted like %v. The methods of v are not called if
+// Options.NoMethodCalls is set.
+func (s *dumpState) format(v reflect.Value) string {
+	if !s.NoMethodCalls && (!isPrimitiveKind(v.Kind()) || v.Type().NumMethod() > 0) {
+		return fmt.Sprint(v)
+	}
+	// printed by fmt alike without any method
+	return rawString(v)
+}
+
+// rawString returns v formatted like %v by reflection without calling any
+// method of v. Pointers are printed as their addresses.
+func rawString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return "<nil>"
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.String:
+		return v.String()
+	case reflect.Interface:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return rawString(v.Elem())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = rawString(v.Index(i))
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, rawString(iter.Key())+":"+rawString(iter.Value()))
+		}
+		sort.Strings(items)
+		return "map[" + strings.Join(items, " ") + "]"
+	case reflect.Struct:
+		items := make([]string, v.NumField())
+		for i := range items {
+			items[i] = rawString(v.Field(i))
+		}
+		return "{" + strings.Join(items, " ") + "}"
+	}
+	// pointers, channels, functions and unsafe pointers
+	if v.IsNil() {
+		return "<nil>"
+	}
+	return fmt.Sprintf("0x%x", v.Pointer())
+}
+
+// funcString returns the name and the source position of the function v,
+// e.g. name: main.handler, at: main.go:12, with its entry address if
+// ShowPointerAddr is set. Closures are named after their enclosing
+// functions, e.g. main.main.func1, since the captured variables are not
+// visible via reflection. The signature is printed as the type of v.
+func (s *dumpState) funcString(v reflect.Value) string {
+	out := "name: ?"
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		file, line := f.FileLine(v.Pointer())
+		out = fmt.Sprintf("name: %s, at: %s:%d", f.Name(), filepath.Base(file), line)
+	}
+	if s.ShowPointerAddr {
+		out += fmt.Sprintf(", ptr: 0x%x", v.Pointer())
+	}
+	return out
+}
+
+// chanString returns the direction, length and capacity of the channel v,
+// e.g. both, 2/10, with its address if ShowPointerAddr is set.
+func (s *dumpState) chanString(v reflect.Value) string {
+	dir := "both"
+	switch v.Type().ChanDir() {
+	case reflect.RecvDir:
+		dir = "recv"
+	case reflect.SendDir:
+		dir = "send"
+	}
+	out := fmt.Sprintf("%s, %d/%d", dir, v.Len(), v.Cap())
+	if s.ShowPointerAddr {
+		out += fmt.Sprintf(", ptr: 0x%x", v.Pointer())
+	}
+	return out
+}
+
+// unsafePointerString returns the address of the unsafe.Pointer v, which
+// is all it holds, regardless of ShowPointerAddr.
+func (s *dumpState) unsafePointerString(v reflect.Value) string {
+	return fmt.Sprintf("0x%x", v.Pointer())
+}
@@ -0,0 +1,127 @@
+package gdump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func formatHandler(int) string { return "" }
+
+func TestKindStrings(t *testing.T) {
+	x := 1
+	c := make(chan int, 10)
+	c <- 1
+	c <- 2
+	addr := fmt.Sprintf("0x%x", uintptr(unsafe.Pointer(&x)))
+	tests := []struct {
+		name string
+		v    interface{}
+		opts Options
+		want []string
+	}{
+		{"func", formatHandler, Options{},
+			[]string{"func(int) string{name: github.com/neoul/gdump.formatHandler, at: format_test.go:10}"}},
+		{"closure", func() { x++ }, Options{},
+			[]string{"name: github.com/neoul/gdump.TestKindStrings.func1"}},
+		{"chan", c, Options{}, []string{"chan int{both, 2/10}"}},
+		{"recv", (<-chan int)(c), Options{}, []string{"<-chan int{recv, 2/10}"}},
+		{"send", (chan<- int)(c), Options{}, []string{"chan<- int{send, 2/10}"}},
+		{"chan addr", c, Options{ShowPointerAddr: true}, []string{"both, 2/10, ptr: 0x"}},
+		{"unsafe", unsafe.Pointer(&x), Options{}, []string{"unsafe.Pointer{" + addr + "}"}},
+		{"unsafe addr", unsafe.Pointer(&x), Options{ShowPointerAddr: true}, []string{"unsafe.Pointer{" + addr + "}"}},
+		{"complex", complex(1, -2.5), Options{}, []string{"complex128{(1-2.5i)}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.v, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %q, want it containing %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+type funcHandlers struct {
+	OnRead  func(int) string
+	OnClose func()
+	OnError func() error
+}
+
+// TestFuncs names the functions of the fields, the closures by their
+// enclosing functions and the method values by their receivers, with the
+// address only if ShowPointerAddr is set.
+func TestFuncs(t *testing.T) {
+	var b strings.Builder
+	v := funcHandlers{OnRead: formatHandler, OnError: func() error { return nil }}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  []string
+	}{
+		{"fields", v, Options{Depth: 2}, []string{
+			"• OnRead:func(int) string{name: github.com/neoul/gdump.formatHandler, at: format_test.go:10}\n",
+			"• OnClose:func(){nil}\n",
+			"• OnError:func() error{name: github.com/neoul/gdump.TestFuncs.func1, at: format_test.go:",
+		}},
+		{"method value", b.String, Options{Depth: 2, ShowPointerAddr: true},
+			[]string{"func() string{name: strings.(*Builder).String-fm, at: ", ", ptr: 0x"}},
+		{"json", v, Options{Depth: 2, Format: FormatJSON},
+			[]string{`"OnRead": "name: github.com/neoul/gdump.formatHandler, at: format_test.go:10",`, `"OnClose": null,`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.value, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %q, want it containing %q", got, want)
+				}
+			}
+		})
+	}
+	if got := Sdump(v, Options{Depth: 2}); strings.Contains(got, "ptr:") {
+		t.Errorf("Sdump = %q without ShowPointerAddr, want no address", got)
+	}
+}
+
+// methodCalls - the number of the calls of the methods of methodSpy
+var methodCalls int
+
+type methodSpy struct{ N int }
+
+func (methodSpy) String() string { methodCalls++; return "spy" }
+
+func (methodSpy) Error() string { methodCalls++; return "spy" }
+
+func (methodSpy) MarshalText() ([]byte, error) { methodCalls++; return []byte("spy"), nil }
+
+type methodSpyKey int
+
+func (methodSpyKey) String() string { methodCalls++; return "key" }
+
+// TestNoMethodCalls dumps the values in every format without calling any
+// of their methods, while UseStringer and UnwrapErrors ask for them.
+func TestNoMethodCalls(t *testing.T) {
+	v := struct {
+		S methodSpy
+		P *methodSpy
+		K methodSpyKey
+		E error
+		M map[methodSpyKey]methodSpy
+	}{methodSpy{1}, &methodSpy{2}, 3, methodSpy{4}, map[methodSpyKey]methodSpy{5: {6}}}
+	for _, format := range []Format{FormatText, FormatSpewCompat, FormatJSON, FormatYAML, FormatGo, FormatHTML} {
+		methodCalls = 0
+		out := Sdump(v, Options{Depth: 4, Format: format, UseStringer: true, UnwrapErrors: true, NoMethodCalls: true})
+		if methodCalls != 0 || strings.Contains(out, "spy") || strings.Contains(out, "key") {
+			t.Errorf("format %d: %d methods called, dump:\n%s", format, methodCalls, out)
+		}
+	}
+	methodCalls = 0
+	if Sdump(v, Options{Depth: 4, UseStringer: true}); methodCalls == 0 {
+		t.Error("no method called without NoMethodCalls")
+	}
+}
@@ -0,0 +1,57 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FormatterFunc - a function returning the value v printed instead of its
+// fields or elements, e.g. a time.Time in RFC 3339
+type FormatterFunc func(v reflect.Value) string
+
+// formatterString returns v printed by the formatter registered to its type
+// in Options.Formatters. The pointers are printed by the formatter of their
+// element type unless their type is registered. A panic of the formatter is
+// returned as the string.
+func (s *dumpState) formatterString(v reflect.Value) (str string, ok bool) {
+	if len(s.Formatters) == 0 {
+		return "", false
+	}
+	fn := s.Formatters[v.Type()]
+	for fn == nil && v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+		fn = s.Formatters[v.Type()]
+	}
+	if fn == nil {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			str, ok = fmt.Sprintf("<panic: %v>", r), true
+		}
+	}()
+	return fn(v), true
+}
+
+// RegisterFormatter registers fn to print the values of the type t dumped
+// by d at any depth. It takes precedence over the methods called by
+// UseStringer and over the fields and elements of the values.
+func (d *Dumper) RegisterFormatter(t reflect.Type, fn FormatterFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// replaced rather than modified, as the dumps in progress read the map
+	formatters := make(map[reflect.Type]FormatterFunc, len(d.opts.Formatters)+1)
+	for k, f := range d.opts.Formatters {
+		formatters[k] = f
+	}
+	formatters[t] = fn
+	d.opts.Formatters = formatters
+}
+
+// WithFormatter - registers fn to print the values of the type t like
+// Dumper.RegisterFormatter
+func WithFormatter(t reflect.Type, fn FormatterFunc) Option {
+	return func(d *Dumper) {
+		d.RegisterFormatter(t, fn)
+	}
+}
@@ -0,0 +1,47 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type money struct{ Cents int64 }
+
+func (m money) String() string { return "money" }
+
+type invoice struct {
+	Total money
+	Tip   *money
+	At    time.Time
+	Lines []money
+	N     int
+}
+
+func formatMoney(v reflect.Value) string {
+	return fmt.Sprintf("$%.2f", float64(v.Field(0).Int())/100)
+}
+
+// TestFormatters prints the values by the formatters of their types or the
+// types they point to, before their methods, in all the formats.
+func TestFormatters(t *testing.T) {
+	v := invoice{Total: money{150}, Tip: &money{5}, At: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Lines: []money{{1}}}
+	d := New(WithDepth(3), WithStringer(true), WithFormatter(reflect.TypeOf(money{}), formatMoney),
+		WithFormatter(reflect.TypeOf(time.Time{}), func(v reflect.Value) string { return v.Interface().(time.Time).Format("2006") }),
+		WithFormatter(reflect.TypeOf(0), func(v reflect.Value) string { panic("boom") }))
+	want := "gdump.invoice{\n• Total:gdump.money{$1.50}\n• Tip:*gdump.money{$0.05}\n• At:time.Time{2020}\n• Lines:[]gdump.money{\n• • gdump.money{$0.01}}\n• N:int{<panic: boom>}}\n"
+	if got := d.Sdump(v); got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+
+	d = New(WithDepth(3), WithFormat(FormatJSON))
+	other := New(WithOptions(d.Options()))
+	d.RegisterFormatter(reflect.TypeOf(money{}), func(v reflect.Value) string { return "m" })
+	if got, want := d.Sdump(v.Lines), "[\n  \"m\"\n]\n"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if got, want := other.Sdump(v.Lines), "[\n  \"money\"\n]\n"; got != want {
+		t.Errorf("Sdump by the other Dumper = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package gdump
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type fuzzNode struct {
+	Name   string
+	Value  interface{}
+	Items  []interface{}
+	Next   *fuzzNode
+	Ptr    unsafe.Pointer
+	hidden interface{}
+}
+
+// fuzzValue returns a value built from data, consuming a byte for each
+// part of the value, in the nesting up to depth 4.
+func fuzzValue(data []byte, i *int, depth int) interface{} {
+	if *i >= len(data) || depth > 4 {
+		return nil
+	}
+	c := data[*i]
+	*i++
+	switch c % 16 {
+	case 0:
+		return nil
+	case 1:
+		return int(c)
+	case 2:
+		return string(data[*i:])
+	case 3:
+		return math.NaN()
+	case 4:
+		return []interface{}{fuzzValue(data, i, depth+1), fuzzValue(data, i, depth+1)}
+	case 5:
+		// NaN keys are never equal to themselves.
+		return map[float64]interface{}{math.NaN(): fuzzValue(data, i, depth+1), math.NaN(): 1, 1: nil}
+	case 6:
+		return map[interface{}]interface{}{nil: 1, math.NaN(): fuzzValue(data, i, depth+1), "k": nil}
+	case 7:
+		s := make([]interface{}, 2)
+		s[0], s[1] = s, fuzzValue(data, i, depth+1)
+		return s
+	case 8:
+		n := &fuzzNode{Name: "n", Value: fuzzValue(data, i, depth+1)}
+		n.Next, n.hidden = n, n
+		return n
+	case 9:
+		n := &fuzzNode{Value: fuzzValue(data, i, depth+1)}
+		n.Ptr = unsafe.Pointer(n)
+		return *n
+	case 10:
+		if c&0x10 == 0 {
+			return unsafe.Pointer(nil)
+		}
+		return unsafe.Pointer(&data[0])
+	case 11:
+		v := fuzzValue(data, i, depth+1)
+		return &v
+	case 12:
+		return [2]interface{}{fuzzValue(data, i, depth+1), complex(math.Inf(1), math.NaN())}
+	case 13:
+		return time.Unix(int64(c), 0).UTC()
+	case 14:
+		return map[string]interface{}{"password": fuzzValue(data, i, depth+1), "": []byte(nil)}
+	default:
+		return func() {}
+	}
+}
+
+// fuzzConfig - the reader of the options from the fuzz bytes, reading 0
+// after the end of the bytes
+type fuzzConfig struct {
+	data []byte
+	i    int
+}
+
+func (c *fuzzConfig) byte() byte {
+	if c.i >= len(c.data) {
+		return 0
+	}
+	c.i++
+	return c.data[c.i-1]
+}
+
+// int returns the next number less than n.
+func (c *fuzzConfig) int(n int) int { return int(c.byte()) % n }
+
+func (c *fuzzConfig) bool() bool { return c.byte()&1 == 1 }
+
+// pick returns one of choices, the first one by 0.
+func pick[T any](c *fuzzConfig, choices ...T) T { return choices[c.int(len(choices))] }
+
+// fuzzOptions returns the options read from data, each field in turn. The
+// limits come first, so that the short seeds set them.
+func fuzzOptions(data []byte) Options {
+	c := &fuzzConfig{data: data}
+	timeType := reflect.TypeOf(time.Time{})
+	return Options{
+		Depth:                  c.int(6),
+		MaxItems:               c.int(4),
+		Format:                 Format(c.int(6)),
+		MaxOutputBytes:         c.int(256),
+		MaxNodes:               c.int(64),
+		MaxStringLen:           c.int(8),
+		TruncateStrategy:       TruncateStrategy(c.int(3)),
+		MinFullDepth:           c.int(3),
+		BytesFormat:            BytesFormat(c.int(4)),
+		RawStrings:             c.bool(),
+		MultilineStrings:       c.bool(),
+		UseStringer:            c.bool(),
+		UnwrapErrors:           c.bool(),
+		ShowUnexported:         c.bool(),
+		NoMethodCalls:          c.bool(),
+		DecodeBits:             c.bool(),
+		FlattenEmbedded:        c.bool(),
+		CollapseSingleField:    c.bool(),
+		SingleFieldAsValue:     c.bool(),
+		ShowOffsets:            c.bool(),
+		ShowFieldTags:          c.bool(),
+		ShowConstraints:        c.bool(),
+		CompactPrimitiveSlices: c.bool(),
+		ElementSeparator:       pick(c, "", ", ", " | "),
+		HashLongKeys:           c.int(8),
+		AlignMapValues:         c.bool(),
+		HighlightPaths:         pick(c, nil, []string{"Name"}, []string{"0", "Next.Value"}),
+		ExtractLargeValues:     c.int(64),
+		IndentUnit:             pick(c, "", "  ", "\t"),
+		Bullet:                 pick(c, "", "- "),
+		CompactWidth:           c.int(80),
+		NewlineAtEnd:           c.bool(),
+		KeepMapOrder:           c.bool(),
+		ShowPointerAddr:        c.bool(),
+		PointerMarker:          pick(c, "", "&", "*"),
+		InterfaceMarker:        pick(c, "", "○", "i:"),
+		FocusPath:              pick(c, "", "Next", "0", "Value.password"),
+		IncludeLegend:          c.bool(),
+		MarkdownFence:          c.bool(),
+		SnapshotFirst:          c.bool(),
+		DedupEqualSubtrees:     c.bool(),
+		PrettyJSON:             c.bool(),
+		OmitZero:               c.bool(),
+		CountZeroFields:        c.bool(),
+		BareLeavesAtMaxDepth:   c.bool(),
+		PlainEllipsis:          c.bool(),
+		RecognizeSets:          c.bool(),
+		RedactHighEntropy:      c.bool(),
+		EntropyThreshold:       float64(c.int(5)),
+		RawStdTypes:            c.bool(),
+		ExpandURLs:             c.bool(),
+		PerValueTimeout:        time.Duration(c.int(3)) * time.Millisecond,
+		Color:                  c.bool(),
+		TableSlices:            c.bool(),
+		MaxColumnWidth:         c.int(16),
+		JSONTypes:              c.bool(),
+		ShowValidation:         c.bool(),
+		ExcludedField:          pick(c, nil, []string{"Name"}, []string{"Next.Items", "*.Value"}),
+		IncludedField:          pick(c, nil, []string{"Value", "Next"}),
+		IncludedPaths:          pick(c, nil, []string{"Next.Name"}, []string{"0.password"}),
+		ExcludedTypes:          pick(c, nil, []reflect.Type{timeType}),
+		TypeDepth:              pick(c, nil, map[reflect.Type]int{reflect.TypeOf(&fuzzNode{}): 1}),
+		IgnoreStringer:         pick(c, nil, []reflect.Type{timeType}),
+		RendererPriority:       pick(c, nil, []RendererKind{RenderFormatter, RenderStringer}),
+		RedactFields:           pick(c, nil, []string{"Name", "k"}),
+		RedactPattern:          pick(c, nil, DefaultRedactPattern),
+		FieldFilter: pick(c, nil, func(_ reflect.Value, f reflect.StructField, _ reflect.Value) bool {
+			return f.Name != "Items"
+		}),
+		Formatters:  pick(c, nil, map[reflect.Type]FormatterFunc{timeType: func(v reflect.Value) string { return "t" }}),
+		MapKeyLess:  pick(c, nil, func(a, b reflect.Value) bool { return a.Kind() < b.Kind() }),
+		NewRenderer: pick(c, nil, NewDefaultRenderer),
+	}
+}
+
+// FuzzDump dumps the values built by fuzzValue with the options read by
+// fuzzOptions. The dumps are not longer than MaxOutputBytes and the marker
+// of the truncation. The seeds in testdata/fuzz/FuzzDump cover the NaN map
+// keys, the cyclic slices and pointers and the unsafe pointers.
+func FuzzDump(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, config []byte) {
+		i := 0
+		v := fuzzValue(data, &i, 0)
+		opts := fuzzOptions(config)
+		out := Sdump(v, opts)
+		if max := opts.MaxOutputBytes; max > 0 {
+			if marker := fmt.Sprintf("\n…(truncated at %d bytes)", max); len(out) > max+len(marker) {
+				t.Errorf("dump of %d bytes, want at most %d:\n%s", len(out), max+len(marker), out)
+			}
+		}
+		d := New(WithOptions(opts))
+		d.Sdump(v)
+		d.Diff(v, fuzzValue(data, new(int), 0))
+		ValueDumpInline(v, opts.Depth, nil)
+		Graph(v)
+		snapshot(v)
+	})
+}
@@ -0,0 +1,138 @@
+// Package gdumpproto dumps the protocol buffer messages generated by
+// protoc-gen-go by their set fields. The fields of the internal state of the
+// messages, i.e. state, sizeCache, unknownFields and the like, and the
+// fields not set are not printed, and the oneofs are printed as the fields
+// of their set cases.
+//
+// The messages are recognized by reflection on their ProtoReflect methods
+// and the protobuf struct tags of their fields, so that neither gdump nor
+// this package depends on google.golang.org/protobuf. The fields set are
+// then those not zero, i.e. the nil pointers of the optional scalars and the
+// nil interfaces of the oneofs are not set.
+//
+// Built with the protoreflect tag, this package depends on
+// google.golang.org/protobuf and the fields set are those populated by
+// protoreflect.Message.Has and WhichOneof, which also tells the fields set
+// to their zero values but not pointers, e.g. the proto3 optional scalars of
+// the opaque API, from the fields not set.
+//
+//	go build -tags protoreflect
+//
+//	fmt.Print(gdumpproto.Sdump(req, gdump.WithDepth(5)))
+package gdumpproto
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/neoul/gdump"
+)
+
+// maxDepth - the depth of the values searched for the messages
+const maxDepth = 64
+
+// messages - whether the struct types are the messages by the types
+var messages sync.Map
+
+// WithMessages - prints only the set fields of the protocol buffer messages
+// by the Filter. The messages found in values are printed by their fields
+// instead of their String methods if gdump.Options.UseStringer is set. The
+// other structs are printed as they are.
+func WithMessages(values ...interface{}) gdump.Option {
+	types := map[reflect.Type]bool{}
+	for _, v := range values {
+		messageTypes(reflect.ValueOf(v), types, maxDepth)
+	}
+	ignored := make([]reflect.Type, 0, len(types))
+	for t := range types {
+		ignored = append(ignored, t)
+	}
+	return func(d *gdump.Dumper) {
+		gdump.WithFieldFilter(Filter)(d)
+		gdump.WithIgnoreStringer(ignored...)(d)
+	}
+}
+
+// Sdump returns a string representation of the message m dumped with opts
+// and only its set fields printed.
+func Sdump(m interface{}, opts ...gdump.Option) string {
+	return gdump.New(append(opts, WithMessages(m))...).Sdump(m)
+}
+
+// Filter returns false for the field f of the value v not printed if owner
+// is a message: the internal fields having no protobuf tags and the fields
+// not set. The fields of the other structs are printed.
+func Filter(owner reflect.Value, f reflect.StructField, v reflect.Value) bool {
+	if !IsMessage(owner.Type()) {
+		return true
+	}
+	_, field := f.Tag.Lookup("protobuf")
+	_, oneof := f.Tag.Lookup("protobuf_oneof")
+	if !field && !oneof {
+		return false
+	}
+	if set, ok := isPopulated(owner, f); ok {
+		return set
+	}
+	return isSet(f, v)
+}
+
+// isSet returns true if the field f of a message having the value v is set
+// by the value, i.e. not zero.
+func isSet(f reflect.StructField, v reflect.Value) bool {
+	if _, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+		// the interface holding the wrapper of the case set
+		return !v.IsNil()
+	}
+	return !v.IsZero()
+}
+
+// IsMessage returns true if the struct type t is a message generated by
+// protoc-gen-go, i.e. the pointer to t has the ProtoReflect method.
+func IsMessage(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if ok, cached := messages.Load(t); cached {
+		return ok.(bool)
+	}
+	_, ok := reflect.PtrTo(t).MethodByName("ProtoReflect")
+	messages.Store(t, ok)
+	return ok
+}
+
+// messageTypes adds the types of the messages found in v to types. The
+// values are followed in depth.
+func messageTypes(v reflect.Value, types map[reflect.Type]bool, depth int) {
+	if depth < 0 || !v.IsValid() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			messageTypes(v.Elem(), types, depth)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			messageTypes(v.Index(i), types, depth-1)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			messageTypes(iter.Value(), types, depth-1)
+		}
+	case reflect.Struct:
+		message := IsMessage(v.Type())
+		if message {
+			types[v.Type()] = true
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if message && f.PkgPath != "" {
+				// the internal state
+				continue
+			}
+			messageTypes(v.Field(i), types, depth-1)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package gdumpproto
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neoul/gdump"
+)
+
+// testMessage - a message in the layout generated by protoc-gen-go
+type testMessage struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	Name  string       `protobuf:"bytes,1,opt,name=name,proto3"`
+	Count *int32       `protobuf:"varint,2,opt,name=count,proto3,oneof"`
+	Tags  []string     `protobuf:"bytes,3,rep,name=tags,proto3"`
+	Child *testMessage `protobuf:"bytes,4,opt,name=child,proto3"`
+	Kind  isTestKind   `protobuf_oneof:"kind"`
+}
+
+func (*testMessage) ProtoReflect() {}
+
+type isTestKind interface{ isTestKind() }
+
+type testMessageID struct {
+	ID int64 `protobuf:"varint,5,opt,name=id,proto3,oneof"`
+}
+
+func (*testMessageID) isTestKind() {}
+
+// testPlain - not a message
+type testPlain struct {
+	Name  string
+	count int
+}
+
+func TestFilter(t *testing.T) {
+	zero := int32(0)
+	tests := []struct {
+		name string
+		v    interface{}
+		want []string
+		not  []string
+	}{
+		{"unset", &testMessage{}, []string{"*gdumpproto.testMessage{}"}, []string{"Name", "Count", "Tags", "Kind"}},
+		{"set", &testMessage{Name: "a", Tags: []string{"x"}}, []string{"Name:string{a}", "Tags:"}, []string{"Count", "Child", "Kind", "sizeCache"}},
+		{"optional zero", &testMessage{Count: &zero}, []string{"Count:*int32{0}"}, []string{"Name"}},
+		{"oneof zero", &testMessage{Kind: &testMessageID{}}, []string{"Kind:", "ID:int64{0}"}, []string{"Name"}},
+		{"nested", &testMessage{Child: &testMessage{Name: "b"}}, []string{"Child:*gdumpproto.testMessage{", "Name:string{b}"}, []string{"Tags"}},
+		{"plain", testPlain{Name: "a"}, []string{"Name:string{a}"}, nil},
+		{"plain zero", testPlain{}, []string{"Name:string{}"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.v, gdump.WithDepth(5))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %s, want it containing %q", got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("Sdump = %s, want it not containing %q", got, not)
+				}
+			}
+		})
+	}
+}
+
+func TestIsMessage(t *testing.T) {
+	tests := []struct {
+		t    reflect.Type
+		want bool
+	}{
+		{reflect.TypeOf(testMessage{}), true},
+		{reflect.TypeOf(&testMessage{}), false},
+		{reflect.TypeOf(testMessageID{}), false},
+		{reflect.TypeOf(testPlain{}), false},
+	}
+	for _, tt := range tests {
+		if got := IsMessage(tt.t); got != tt.want {
+			t.Errorf("IsMessage(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
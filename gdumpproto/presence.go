@@ -0,0 +1,11 @@
+//go:build !protoreflect
+
+package gdumpproto
+
+import "reflect"
+
+// isPopulated returns false for ok, since the presence of the fields is
+// read by isSet without protoreflect.
+func isPopulated(owner reflect.Value, f reflect.StructField) (set, ok bool) {
+	return false, false
+}
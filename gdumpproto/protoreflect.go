@@ -0,0 +1,57 @@
+//go:build protoreflect
+
+package gdumpproto
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// isPopulated returns true for set if the field f is populated in the
+// message owner by protoreflect, i.e. it is a field visited by
+// protoreflect.Message.Range or the oneof of a case set. ok is false if the
+// message is not addressable, i.e. it is not dumped by its pointer.
+func isPopulated(owner reflect.Value, f reflect.StructField) (set, ok bool) {
+	m, ok := message(owner)
+	if !ok {
+		return false, false
+	}
+	md := m.Descriptor()
+	if name, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+		od := md.Oneofs().ByName(protoreflect.Name(name))
+		if od == nil {
+			return false, false
+		}
+		return m.WhichOneof(od) != nil, true
+	}
+	// e.g. `protobuf:"varint,1,opt,name=count,proto3"`
+	parts := strings.Split(f.Tag.Get("protobuf"), ",")
+	if len(parts) < 2 {
+		return false, false
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, false
+	}
+	fd := md.Fields().ByNumber(protoreflect.FieldNumber(n))
+	if fd == nil {
+		return false, false
+	}
+	return m.Has(fd), true
+}
+
+// message returns the protoreflect.Message of the message owner by its
+// pointer.
+func message(owner reflect.Value) (protoreflect.Message, bool) {
+	if !owner.CanAddr() || !owner.Addr().CanInterface() {
+		return nil, false
+	}
+	m, ok := owner.Addr().Interface().(protoreflect.ProtoMessage)
+	if !ok {
+		return nil, false
+	}
+	return m.ProtoReflect(), true
+}
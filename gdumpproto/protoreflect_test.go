@@ -0,0 +1,56 @@
+//go:build protoreflect
+
+package gdumpproto
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neoul/gdump"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestFilterProtoreflect(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []string
+		not  []string
+	}{
+		{"scalar set", wrapperspb.Int32(5), []string{"Value:int32{5}"}, []string{"state", "sizeCache", "unknownFields"}},
+		{"scalar zero", wrapperspb.Int32(0), []string{"*wrapperspb.Int32Value{}"}, []string{"Value"}},
+		{"oneof zero", structpb.NewNullValue(), []string{"Kind:", "NullValue:"}, []string{"state"}},
+		{"oneof unset", &structpb.Value{}, []string{"*structpb.Value{}"}, []string{"Kind"}},
+		{"nested", &structpb.ListValue{Values: []*structpb.Value{structpb.NewBoolValue(true)}}, []string{"Values:", "BoolValue:bool{true}"}, []string{"NullValue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.v, gdump.WithDepth(6))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %s, want it containing %q", got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("Sdump = %s, want it not containing %q", got, not)
+				}
+			}
+		})
+	}
+}
+
+// TestFilterNotAddressable filters the fields of a message not addressable,
+// whose fields set are then told by isSet without protoreflect.Message.
+func TestFilterNotAddressable(t *testing.T) {
+	owner := reflect.Zero(reflect.TypeOf((*structpb.Value)(nil)).Elem())
+	f, _ := owner.Type().FieldByName("Kind")
+	if _, ok := isPopulated(owner, f); ok {
+		t.Error("isPopulated ok for a message not addressable")
+	}
+	if Filter(owner, f, owner.FieldByIndex(f.Index)) {
+		t.Error("Filter = true for the oneof not set")
+	}
+}
@@ -0,0 +1,157 @@
+// Package gdumptest provides the golden file snapshot testing of the values
+// dumped by gdump.
+package gdumptest
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/neoul/gdump"
+)
+
+// UpdateEnv - the environment variable rewriting the golden files with the
+// current dumps if set to a non-empty value, e.g. GDUMP_UPDATE=1 go test
+const UpdateEnv = "GDUMP_UPDATE"
+
+// Dir - the directory of the golden files relative to the package tested
+var Dir = "testdata"
+
+// MatchDump dumps value and fails the test unless the dump matches the
+// regular expression pattern. The full dump is reported on failure.
+func MatchDump(t testing.TB, value interface{}, pattern string) {
+	t.Helper()
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.Fatalf("gdumptest: invalid pattern %q: %v", pattern, err)
+	}
+	s := gdump.Sdump(value, gdump.Options{Depth: gdump.DefaultPrintDepth})
+	if !re.MatchString(s) {
+		t.Errorf("gdumptest: dump does not match %q:\n%s", pattern, s)
+	}
+}
+
+// MatchSnapshot dumps value and fails the test unless the dump is equal to
+// the golden file named after the test, e.g. testdata/TestUser.golden. The
+// golden file is written instead if UpdateEnv is set. The dump is
+// deterministic: it reads no package-level variables but DefaultPrintDepth
+// and IndentUnit, the map keys are sorted, and the addresses and colors are
+// not printed. opts are applied after them, e.g. gdump.WithDepth(5).
+// A test has one snapshot, so the subtests are used for more.
+func MatchSnapshot(t testing.TB, value interface{}, opts ...gdump.Option) {
+	t.Helper()
+	opts = append([]gdump.Option{
+		gdump.WithOptions(gdump.Options{
+			Depth:                  gdump.DefaultPrintDepth,
+			IndentUnit:             gdump.IndentUnit,
+			NewlineAtEnd:           true,
+			UseStringer:            true,
+			CompactPrimitiveSlices: true,
+		}),
+		gdump.WithSortMapKeys(true),
+		gdump.WithAddresses(false),
+		gdump.WithColor(false),
+	}, opts...)
+	got := gdump.New(opts...).Sdump(value)
+	path := filepath.Join(Dir, goldenName(t.Name())+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("gdumptest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("gdumptest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("gdumptest: %v (run the test with %s=1 to write it)", err, UpdateEnv)
+	}
+	if string(want) != got {
+		t.Errorf("gdumptest: dump does not match %s (-want +got):\n%s", path, lineDiff(string(want), got))
+	}
+}
+
+// goldenName returns the file name of the golden file of the test name.
+// The subtests are separated by double underscores.
+func goldenName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, strings.ReplaceAll(name, "/", "__"))
+}
+
+// lineDiff returns the lines of want and got compared by the shortest edit
+// script found by the Myers algorithm in O((n+m)d) for d lines changed. The
+// removed lines are prefixed with -, the added lines with + and the common
+// lines with a space.
+func lineDiff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	// v[offset+k] - the furthest x reached on the diagonal k = x-y
+	v := make([]int, 2*offset+1)
+	// trace[d] - v before the d-th edit
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+	// the lines are collected backward from the end of the edit script.
+	var lines []string
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prev := k - 1
+		if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+			prev = k + 1
+		}
+		px := v[offset+prev]
+		py := px - prev
+		for x > px && y > py && x > 0 && y > 0 {
+			x--
+			y--
+			lines = append(lines, "  "+a[x])
+		}
+		if d == 0 {
+			break
+		}
+		if x == px {
+			lines = append(lines, "+ "+b[py])
+		} else {
+			lines = append(lines, "- "+a[px])
+		}
+		x, y = px, py
+	}
+	var out strings.Builder
+	for i := len(lines) - 1; i >= 0; i-- {
+		out.WriteString(lines[i] + "\n")
+	}
+	return out.String()
+}
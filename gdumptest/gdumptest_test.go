@@ -0,0 +1,145 @@
+package gdumptest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder - a testing.TB recording the failures instead of failing
+type recorder struct {
+	testing.TB
+	name     string
+	failures []string
+}
+
+func (r *recorder) Helper()      {}
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs fn with a recorder named name and returns its failures.
+func record(name string, fn func(tb testing.TB)) []string {
+	r := &recorder{name: name}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn(r)
+	}()
+	wg.Wait()
+	return r.failures
+}
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestMatchDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		fail    string
+	}{
+		{"match", `Name:string\{alice\}`, ""},
+		{"mismatch", `Age:int\{7\}`, "dump does not match"},
+		{"invalid", `(`, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := record(t.Name(), func(tb testing.TB) {
+				MatchDump(tb, user{Name: "alice", Age: 30}, tt.pattern)
+			})
+			switch {
+			case tt.fail == "" && len(failures) > 0:
+				t.Errorf("MatchDump failed: %v", failures)
+			case tt.fail != "" && (len(failures) != 1 || !strings.Contains(failures[0], tt.fail)):
+				t.Errorf("MatchDump failures = %v, want %q", failures, tt.fail)
+			}
+		})
+	}
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = t.TempDir()
+	golden := filepath.Join(Dir, "TestUser__v1.golden")
+	t.Setenv(UpdateEnv, "1")
+	if failures := record("TestUser/v1", func(tb testing.TB) { MatchSnapshot(tb, user{Name: "alice"}) }); len(failures) > 0 {
+		t.Fatalf("MatchSnapshot with %s failed: %v", UpdateEnv, failures)
+	}
+	t.Setenv(UpdateEnv, "")
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	if failures := record("TestUser/v1", func(tb testing.TB) { MatchSnapshot(tb, user{Name: "alice"}) }); len(failures) > 0 {
+		t.Errorf("MatchSnapshot failed: %v", failures)
+	}
+	failures := record("TestUser/v1", func(tb testing.TB) { MatchSnapshot(tb, user{Name: "bob"}) })
+	if len(failures) != 1 || !strings.Contains(failures[0], "+ ") || !strings.Contains(failures[0], "- ") {
+		t.Errorf("MatchSnapshot failures = %v, want a line diff", failures)
+	}
+	if failures := record("TestMissing", func(tb testing.TB) { MatchSnapshot(tb, 1) }); len(failures) != 1 {
+		t.Errorf("MatchSnapshot failures = %v, want the missing golden file", failures)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		want, got string
+		diff      string
+	}{
+		{"a\nb", "a\nb", "  a\n  b\n"},
+		{"a\nb", "a\nc", "  a\n- b\n+ c\n"},
+		{"a", "a\nb", "  a\n+ b\n"},
+		{"a\nb", "b", "- a\n  b\n"},
+		{"", "", "  \n"},
+		{"a\nb\nc\nd", "x\nb\ny\nd\ne", "- a\n+ x\n  b\n- c\n+ y\n  d\n+ e\n"},
+		{"a\na\nb", "b\na\na", "+ b\n  a\n  a\n- b\n"},
+	}
+	for _, tt := range tests {
+		if diff := lineDiff(tt.want, tt.got); diff != tt.diff {
+			t.Errorf("lineDiff(%q, %q) = %q, want %q", tt.want, tt.got, diff, tt.diff)
+		}
+	}
+}
+
+// TestLineDiffLarge diffs the dumps too long for the table of their common
+// subsequences, which would take 20000x20000 ints.
+func TestLineDiffLarge(t *testing.T) {
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i)
+	}
+	want := strings.Join(lines, "\n")
+	lines[10000] = "changed"
+	diff := lineDiff(want, strings.Join(lines, "\n"))
+	if !strings.Contains(diff, "- 10000\n+ changed\n") || strings.Count(diff, "\n") != 20001 {
+		t.Errorf("lineDiff = %d lines, want one line changed", strings.Count(diff, "\n"))
+	}
+}
+
+func TestGoldenName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"TestUser", "TestUser"},
+		{"TestUser/admin user", "TestUser__admin_user"},
+		{"TestUser/a:b", "TestUser__a_b"},
+	}
+	for _, tt := range tests {
+		if got := goldenName(tt.name); got != tt.want {
+			t.Errorf("goldenName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
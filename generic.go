@@ -0,0 +1,143 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// anyKey - the segment of the field paths standing for any slice index or
+// map key
+const anyKey = "*"
+
+// Dump returns a string representation of v dumped with the options like
+// the Sdump of the Dumper created by New. The paths in the options can be
+// checked against T by ValidateOptions.
+func Dump[T any](v T, opts ...Option) string {
+	return New(opts...).Sdump(v)
+}
+
+// Fields returns the dotted paths of the struct fields of T printed by the
+// dump in the declaration order, e.g. "Name", "Users", "Users.*.Token",
+// where * stands for any slice index or map key. The fields of the pointed,
+// embedded and nested structs are listed after their parent fields. The
+// fields of the types already on the path and of the values held by the
+// interfaces are not listed.
+func Fields[T any]() []string {
+	return fieldPaths(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// ValidateOptions returns an error if any of the paths of the excluded,
+// included and highlighted fields set by opts matches no field of T. The
+// names without dots match the fields and the string map keys at any depth.
+// The values held by the interfaces may have any field.
+func ValidateOptions[T any](opts ...Option) error {
+	o := New(opts...).Options()
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	var invalid []string
+	for _, list := range [][]string{o.ExcludedField, o.IncludedField, o.IncludedPaths, o.HighlightPaths} {
+		for _, path := range list {
+			pattern := strings.Split(path, ".")
+			if len(pattern) == 1 {
+				pattern = []string{"**", path}
+			}
+			if !matchType(pattern, t, map[typeMatch]bool{}) {
+				invalid = append(invalid, path)
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("gdump: no field %s in %s", strings.Join(invalid, ", "), t)
+	}
+	return nil
+}
+
+// fieldPaths returns the paths of the struct fields of t listed by Fields.
+func fieldPaths(t reflect.Type) []string {
+	var paths []string
+	var walk func(t reflect.Type, prefix string, seen map[reflect.Type]bool)
+	walk = func(t reflect.Type, prefix string, seen map[reflect.Type]bool) {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if seen[t] || stdTypes[t] {
+			return
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			walk(t.Elem(), prefix+anyKey+".", seen)
+			return
+		case reflect.Struct:
+		default:
+			return
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		for _, info := range typeFields(t) {
+			if info.tag.skip {
+				continue
+			}
+			path := prefix + info.ft.Name
+			paths = append(paths, path)
+			walk(info.ft.Type, path+".", seen)
+		}
+	}
+	walk(t, "", map[reflect.Type]bool{})
+	return paths
+}
+
+// typeMatch - the type and the rest of the pattern being matched by
+// matchType, recorded to end the ** segments matched in the recursive types
+type typeMatch struct {
+	t reflect.Type
+	n int
+}
+
+// matchType returns true if the segments of the pattern match any path of
+// the fields, slice elements and map entries of t like MatchPath.
+func matchType(pattern []string, t reflect.Type, seen map[typeMatch]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(pattern) == 0 || t.Kind() == reflect.Interface {
+		return true
+	}
+	if pattern[0] == "**" {
+		m := typeMatch{t, len(pattern)}
+		if seen[m] {
+			return false
+		}
+		seen[m] = true
+		if matchType(pattern[1:], t, seen) {
+			return true
+		}
+	}
+	child := func(ct reflect.Type, ok bool) bool {
+		switch {
+		case pattern[0] == "**":
+			return matchType(pattern, ct, seen)
+		case ok:
+			return matchType(pattern[1:], ct, seen)
+		}
+		return false
+	}
+	p := pattern[0]
+	switch t.Kind() {
+	case reflect.Struct:
+		if stdTypes[t] {
+			return false
+		}
+		for _, info := range typeFields(t) {
+			if !info.tag.skip && child(info.ft.Type, p == "*" || p == info.ft.Name || p == info.tag.name) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		_, err := strconv.Atoi(p)
+		return child(t.Elem(), p == "*" || err == nil)
+	case reflect.Map:
+		return child(t.Elem(), true)
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type genericAccount struct {
+	Token  string `gdump:"tok"`
+	Secret string `gdump:"-"`
+}
+
+type genericUser struct {
+	Name     string
+	Account  *genericAccount
+	Accounts []genericAccount
+	ByID     map[string]genericAccount
+	Self     *genericUser
+}
+
+// TestFields lists the paths of the fields of the pointed, nested and
+// element structs, without the skipped fields and the fields of the types
+// already on the path.
+func TestFields(t *testing.T) {
+	want := []string{"Name", "Account", "Account.Token", "Accounts", "Accounts.*.Token", "ByID", "ByID.*.Token", "Self"}
+	if got := Fields[genericUser](); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields = %q, want %q", got, want)
+	}
+	if got := Fields[*genericUser](); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields of the pointer = %q, want %q", got, want)
+	}
+	if got, want := Fields[[]genericAccount](), []string{"*.Token"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields of the slice = %q, want %q", got, want)
+	}
+	if got := Fields[int](); len(got) != 0 {
+		t.Errorf("Fields of int = %q, want none", got)
+	}
+}
+
+// TestValidateOptions checks the paths by the field names and tags, the
+// indexes and the keys, and lets the interfaces have any field and the bare
+// names match the string map keys.
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"valid", []Option{WithExcludedFields("Token", "Accounts.*.tok"), WithIncludedFields("ByID.k.Token", "Accounts.0.Token", "Self.Self.Name", "**.Token"),
+			WithOptions(Options{HighlightPaths: []string{"Name"}})}, ""},
+		{"invalid", []Option{WithExcludedFields("Account.Nope", "Account.Secret"), WithIncludedFields("Accounts.x.Token", "Name.Token")},
+			"gdump: no field Account.Nope, Account.Secret, Accounts.x.Token, Name.Token in gdump.genericUser"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if err := ValidateOptions[genericUser](tt.opts...); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("ValidateOptions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if err := ValidateOptions[genericAccount](WithExcludedFields("Secret")); err == nil {
+		t.Error("ValidateOptions = nil for the skipped field, want an error")
+	}
+	if err := ValidateOptions[struct{ Any interface{} }](WithExcludedFields("Nope", "Any.X.Y")); err != nil {
+		t.Errorf("ValidateOptions = %v for the interface, want nil", err)
+	}
+}
+
+// TestDump dumps the values as the Dumper created with the same options.
+func TestDump(t *testing.T) {
+	v := genericUser{Name: "a", Account: &genericAccount{Token: "t"}}
+	opts := []Option{WithDepth(2), WithExcludedFields("Token")}
+	if got, want := Dump(v, opts...), New(opts...).Sdump(v); got != want {
+		t.Errorf("Dump = %q, want %q", got, want)
+	}
+	if got, want := Dump(3), "int{3}\n"; got != want {
+		t.Errorf("Dump = %q, want %q", got, want)
+	}
+}
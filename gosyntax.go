@@ -0,0 +1,292 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// goState renders values as Go composite literals that can be pasted back
+// into Go code, e.g. &main.User{Name: "x", Tags: []string{"a"}}. The values
+// that have no literal are printed as the zero values with a comment:
+//   - the values beyond Options.Depth and the pointers back to an ancestor
+//   - the funcs, chans and unsafe pointers
+//
+// The time.Time values are printed as the calls of time.Date, and the
+// errors held by interfaces having unexported fields, e.g. the errors of
+// errors.New and fmt.Errorf, as the calls of errors.New with their messages.
+// The unexported struct fields not zero are listed in a comment, e.g.
+// /* unexported: id */. The excluded and redacted fields and the fields
+// tagged with `gdump:"-"` are not printed.
+type goState struct {
+	*dumpState
+	ancestors map[uintptr]bool
+	w         strings.Builder
+}
+
+// goString returns value rendered as a Go literal.
+func (s *dumpState) goString(value interface{}) string {
+	g := &goState{dumpState: s, ancestors: map[uintptr]bool{}}
+	g.value(reflect.ValueOf(value), s.Depth, "", true)
+	return g.w.String()
+}
+
+// value writes v indented by indent. typed is false if v is held by an
+// interface, so that the constants need the conversion to their types.
+func (g *goState) value(v reflect.Value, depth int, indent string, typed bool) {
+	if !v.IsValid() {
+		g.w.WriteString("nil")
+		return
+	}
+	if depth < 0 {
+		g.w.WriteString(goZero(v.Type()) + " /* ... */")
+		return
+	}
+	if v.Type() == goTimeType && v.CanInterface() {
+		g.w.WriteString(goTime(v.Interface().(time.Time)))
+		return
+	}
+	if !typed && isOpaqueError(v) {
+		g.w.WriteString("errors.New(" + strconv.Quote(v.Interface().(error).Error()) + ")")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			g.w.WriteString("nil")
+			return
+		}
+		g.value(v.Elem(), depth, indent, false)
+	case reflect.Ptr:
+		g.pointer(v, depth, indent, typed)
+	case reflect.Struct:
+		g.structLiteral(v, depth, indent)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			g.w.WriteString(goConvert(v.Type(), "nil"))
+			return
+		}
+		g.w.WriteString(v.Type().String() + "{")
+		g.elements(v.Len(), depth, indent, isPrimitiveKind(v.Type().Elem().Kind()), false, func(i int) (string, reflect.Value) {
+			return strconv.Itoa(i), v.Index(i)
+		})
+		g.w.WriteString("}")
+	case reflect.Map:
+		if v.IsNil() {
+			g.w.WriteString(goConvert(v.Type(), "nil"))
+			return
+		}
+		entries := g.sortedEntries(v)
+		g.w.WriteString(v.Type().String() + "{")
+		g.elements(len(entries), depth, indent, false, true, func(i int) (string, reflect.Value) {
+			key := &goState{dumpState: g.dumpState, ancestors: g.ancestors}
+			key.value(entries[i].key, depth, indent+"\t", true)
+			if k := entries[i].key; k.Kind() == reflect.String && g.isRedactedName(k.String()) {
+				return key.w.String(), goRedacted(v.Type().Elem())
+			}
+			return key.w.String(), entries[i].value
+		})
+		g.w.WriteString("}")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			g.w.WriteString(goConvert(v.Type(), "nil"))
+			return
+		}
+		g.w.WriteString("nil /* " + v.Kind().String() + " */")
+	default:
+		g.w.WriteString(goConstant(v, typed))
+	}
+}
+
+// pointer writes the pointer v as the address of its value literal.
+func (g *goState) pointer(v reflect.Value, depth int, indent string, typed bool) {
+	if v.IsNil() {
+		if typed {
+			g.w.WriteString("nil")
+		} else {
+			g.w.WriteString(goConvert(v.Type(), "nil"))
+		}
+		return
+	}
+	if g.ancestors[v.Pointer()] {
+		g.w.WriteString("nil /* cycle */")
+		return
+	}
+	g.ancestors[v.Pointer()] = true
+	defer delete(g.ancestors, v.Pointer())
+	switch v.Elem().Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		if v.Elem().Type() != goTimeType {
+			g.w.WriteString("&")
+			g.value(v.Elem(), depth, indent, true)
+			return
+		}
+	}
+	// the constants and the calls are not addressable.
+	g.w.WriteString("func() " + v.Type().String() + " { v := ")
+	g.value(v.Elem(), depth, indent, true)
+	g.w.WriteString("; return &v }()")
+}
+
+// structLiteral writes the exported fields of the struct v not zero and
+// lists the unexported ones not zero in a comment.
+func (g *goState) structLiteral(v reflect.Value, depth int, indent string) {
+	t := v.Type()
+	var names, unexported []string
+	var fields []reflect.Value
+	for i, info := range typeFields(t) {
+		ft, tag := info.ft, info.tag
+		if v.Field(i).IsZero() || tag.skip || tag.redact || g.isRedactedName(ft.Name) || g.isOmittedField(ft.Name) || g.isExcludedType(ft.Type) {
+			continue
+		}
+		if ft.PkgPath != "" {
+			unexported = append(unexported, ft.Name)
+			continue
+		}
+		names = append(names, ft.Name)
+		fields = append(fields, v.Field(i))
+	}
+	g.w.WriteString(t.String() + "{")
+	if len(unexported) > 0 {
+		g.w.WriteString("/* unexported: " + strings.Join(unexported, ", ") + " */")
+	}
+	g.elements(len(names), depth, indent, false, true, func(i int) (string, reflect.Value) {
+		return names[i], fields[i]
+	})
+	g.w.WriteString("}")
+}
+
+// goTimeType - the type written as the call of time.Date
+var goTimeType = reflect.TypeOf(time.Time{})
+
+// goTime returns the call of time.Date returning t. The locations other than
+// time.UTC and time.Local are written as the fixed zones of t.
+func goTime(t time.Time) string {
+	if t.IsZero() {
+		return "time.Time{}"
+	}
+	var loc string
+	switch t.Location() {
+	case time.UTC:
+		loc = "time.UTC"
+	case time.Local:
+		loc = "time.Local"
+	default:
+		name, offset := t.Zone()
+		loc = fmt.Sprintf("time.FixedZone(%q, %d)", name, offset)
+	}
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, %s)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// isOpaqueError returns true if v is an error that has no literal as its
+// fields are unexported, e.g. the errors of errors.New.
+func isOpaqueError(v reflect.Value) bool {
+	if !v.CanInterface() || !v.Type().Implements(rendererTypes[RenderError]) {
+		return false
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// goRedacted returns the value of the type t printed for a redacted one,
+// i.e. the redaction marker for the strings and the zero value otherwise.
+func goRedacted(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(redactedValue).Convert(t)
+	}
+	return reflect.Zero(t)
+}
+
+// elements writes the n elements of a composite literal returned by entry,
+// which returns the key or the index and the value of the i-th element.
+// The elements are written in a line if inline is set and one by one in
+// the lines otherwise. The keys are written if keyed is set.
+func (g *goState) elements(n, depth int, indent string, inline, keyed bool, entry func(i int) (string, reflect.Value)) {
+	inner := indent + "\t"
+	for i := 0; i < n; i++ {
+		key, e := entry(i)
+		parent := g.enter(key)
+		if inline {
+			if i > 0 {
+				g.w.WriteString(", ")
+			}
+		} else {
+			g.w.WriteString("\n" + inner)
+		}
+		if keyed {
+			g.w.WriteString(key + ": ")
+		}
+		g.value(e, depth-1, inner, true)
+		if !inline {
+			g.w.WriteString(",")
+		}
+		g.leave(parent)
+	}
+	if !inline && n > 0 {
+		g.w.WriteString("\n" + indent)
+	}
+}
+
+// goConstant returns the bool, number or string v as a Go constant. The
+// constant is converted to the type of v if it is not typed by its context
+// and the type is not the default type of the constant.
+func goConstant(v reflect.Value, typed bool) string {
+	var lit, deftype string
+	switch v.Kind() {
+	case reflect.Bool:
+		lit, deftype = strconv.FormatBool(v.Bool()), "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lit, deftype = strconv.FormatInt(v.Int(), 10), "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		lit = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		lit = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		lit = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	case reflect.String:
+		lit, deftype = strconv.Quote(v.String()), "string"
+	default:
+		return goZero(v.Type()) + " /* " + v.Kind().String() + " */"
+	}
+	if typed || v.Type().String() == deftype {
+		return lit
+	}
+	return goConvert(v.Type(), lit)
+}
+
+// goConvert returns the conversion of the expression x to the type t.
+func goConvert(t reflect.Type, x string) string {
+	return "(" + t.String() + ")(" + x + ")"
+}
+
+// goZero returns the literal of the zero value of the type t.
+func goZero(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array:
+		return t.String() + "{}"
+	case reflect.Bool:
+		return "false"
+	case reflect.String:
+		return `""`
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return "nil"
+	}
+	return "0"
+}
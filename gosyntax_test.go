@@ -0,0 +1,107 @@
+package gdump
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+	"time"
+)
+
+type GoItem struct {
+	Name    string
+	Tags    []string
+	Created time.Time
+	Expires *time.Time
+	Err     error
+	Count   *int
+	Attrs   map[string]interface{}
+	id      int
+}
+
+// goItemSource - the declaration of GoItem type checked with the literals,
+// exported to be referred from package main
+const goItemSource = `package gdump
+
+import "time"
+
+type GoItem struct {
+	Name    string
+	Tags    []string
+	Created time.Time
+	Expires *time.Time
+	Err     error
+	Count   *int
+	Attrs   map[string]interface{}
+	id      int
+}
+`
+
+// sourceImporter imports the standard packages from the source and gdump
+// from goItemSource.
+type sourceImporter struct {
+	fset *token.FileSet
+	std  types.Importer
+}
+
+func (im sourceImporter) Import(path string) (*types.Package, error) {
+	if path != "github.com/neoul/gdump" {
+		return im.std.Import(path)
+	}
+	f, err := parser.ParseFile(im.fset, "gdump.go", goItemSource, 0)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: im.std}
+	return conf.Check(path, im.fset, []*ast.File{f}, nil)
+}
+
+// typeCheck type checks the Go literal lit assigned to a variable.
+func typeCheck(t *testing.T, lit string) {
+	t.Helper()
+	src := "package main\n\nimport (\n\t\"errors\"\n\t\"time\"\n\n\t\"github.com/neoul/gdump\"\n)\n\n" +
+		"var _ = errors.New\nvar _ = time.Now\nvar _ gdump.GoItem\n\nvar v interface{} = " + lit + "\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse error: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: sourceImporter{fset, importer.ForCompiler(fset, "source", nil)}}
+	if _, err := conf.Check("main", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("type error: %v\n%s", err, src)
+	}
+}
+
+func TestGoSyntax(t *testing.T) {
+	created := time.Date(2024, time.March, 1, 12, 30, 0, 5, time.UTC)
+	expires := time.Date(2025, time.January, 2, 3, 4, 5, 0, time.FixedZone("KST", 9*3600))
+	count := 3
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"time", created, "time.Date(2024, time.March, 1, 12, 30, 0, 5, time.UTC)"},
+		{"zero time", time.Time{}, "time.Time{}"},
+		{"zone", expires, `time.Date(2025, time.January, 2, 3, 4, 5, 0, time.FixedZone("KST", 32400))`},
+		{"time pointer", &created, "func() *time.Time { v := time.Date(2024, time.March, 1, 12, 30, 0, 5, time.UTC); return &v }()"},
+		{"error", []error{errors.New("boom"), fmt.Errorf("wrap: %w", errors.New("x"))}, "[]error{\n\terrors.New(\"boom\"),\n\terrors.New(\"wrap: x\"),\n}"},
+		{"unexported", GoItem{Name: "a", id: 7}, "gdump.GoItem{/* unexported: id */\n\tName: \"a\",\n}"},
+		{"struct", &GoItem{Name: "a", Tags: []string{"x"}, Created: created, Expires: &expires, Err: errors.New("e"), Count: &count,
+			Attrs: map[string]interface{}{"n": 1, "at": created, "err": errors.New("m")}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(WithFormat(FormatGo), WithDepth(5)).Sdump(tt.value)
+			got = got[:len(got)-1]
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Sdump =\n%s\nwant\n%s", got, tt.want)
+			}
+			typeCheck(t, got)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// graphState renders the object graph of a value as a Graphviz DOT digraph.
+// The structs, maps, slices and arrays are the nodes labeled with their types
+// and their scalar fields and entries. The other fields and entries and the
+// pointers are the edges, so that the values shared by the pointers, maps
+// and slices and the cycles are drawn as the edges converging to a node.
+type graphState struct {
+	*dumpState
+	ids   map[refKey]string // the nodes of the referenced values
+	nodes []string          // the nodes by their numbers from 1
+	edges strings.Builder
+}
+
+// Graph returns the object graph of value in the Graphviz DOT language, e.g.
+// to be rendered by `dot -Tsvg`. The values are followed in
+// DefaultPrintDepth.
+func Graph(value interface{}) string {
+	return graphString(value, globalOptions(DefaultPrintDepth))
+}
+
+// Graph returns the object graph of value in the DOT language like the
+// package-level Graph with the options of d.
+func (d *Dumper) Graph(value interface{}) string {
+	return graphString(value, d.optionsFor(nil))
+}
+
+// graphString returns the DOT digraph of value followed with opts.
+func graphString(value interface{}, opts Options) string {
+	opts.Color = false
+	opts.BareLeavesAtMaxDepth = true
+	g := &graphState{dumpState: &dumpState{Options: opts}, ids: map[refKey]string{}}
+	if _, ok := g.value(reflect.ValueOf(value), opts.Depth); !ok {
+		// the scalar value is drawn as a node.
+		g.label(g.newNode(), []string{g.leafLabel(reflect.ValueOf(value))})
+	}
+	return "digraph gdump {\n\tnode [shape=box fontname=monospace];\n" + strings.Join(g.nodes, "") + g.edges.String() + "}\n"
+}
+
+// value returns the node of v followed in depth and true, or the label of v
+// and false if v is printed in the node of its parent, e.g. a scalar value
+// or a value beyond the depth.
+func (g *graphState) value(v reflect.Value, depth int) (string, bool) {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	key, ref := refKeyOf(v)
+	if id, ok := g.ids[key]; ref && ok {
+		return id, true
+	}
+	target := v
+	for target.Kind() == reflect.Ptr && !target.IsNil() {
+		target = target.Elem()
+	}
+	switch target.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return g.leafLabel(v), false
+	}
+	if isNilable(target.Kind()) && target.IsNil() || depth < 0 || stdTypes[target.Type()] && !g.RawStdTypes {
+		return g.leafLabel(v), false
+	}
+	// the node is named before its children to end the cycles.
+	id := g.newNode()
+	if ref {
+		g.ids[key] = id
+	}
+	if key, ok := refKeyOf(target); ok && v.Kind() == reflect.Ptr {
+		g.ids[key] = id
+	}
+	g.composite(id, target, depth)
+	return id, true
+}
+
+// composite writes the node id of the struct, map, slice or array v and the
+// edges to its children.
+func (g *graphState) composite(id string, v reflect.Value, depth int) {
+	label := v.Type().String()
+	if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+		label += " len:" + strconv.Itoa(v.Len())
+	}
+	lines := []string{label}
+	var children []string
+	child := func(name string, cv reflect.Value, redact bool) {
+		parent := g.enter(name)
+		defer g.leave(parent)
+		if redact {
+			lines = append(lines, name+": "+redactedValue)
+			return
+		}
+		cid, ok := g.value(cv, g.childDepth(depth))
+		if !ok {
+			lines = append(lines, name+": "+cid)
+			return
+		}
+		children = append(children, cid, name)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range g.structFields(v) {
+			name := f.ft.Name
+			if f.tag.skip || g.isOmittedField(name) || g.isHiddenPath(name, f.ft.Type) || g.isExcludedType(f.ft.Type) || g.isFilteredField(f) {
+				continue
+			}
+			if f.ft.PkgPath != "" {
+				f.fv = exposeField(f.fv)
+			}
+			child(f.from+name, f.fv, f.tag.redact || g.isRedactedName(name))
+		}
+	case reflect.Map:
+		for _, e := range g.sortedEntries(v) {
+			k := e.key
+			name := g.leafLabel(k)
+			if k.Kind() == reflect.String && (g.isOmittedField(name) || g.isHiddenPath(name, v.Type().Elem())) {
+				continue
+			}
+			child(name, e.value, k.Kind() == reflect.String && g.isRedactedName(name))
+		}
+	default:
+		for i := 0; i < v.Len(); i++ {
+			child(strconv.Itoa(i), v.Index(i), false)
+		}
+	}
+	g.label(id, lines)
+	for i := 0; i < len(children); i += 2 {
+		fmt.Fprintf(&g.edges, "\t%s -> %s [label=%s];\n", id, children[i], dotQuote(children[i+1]))
+	}
+}
+
+// newNode returns the name of a new node.
+func (g *graphState) newNode() string {
+	g.nodes = append(g.nodes, "")
+	return "n" + strconv.Itoa(len(g.nodes))
+}
+
+// label writes the node id labeled with the lines.
+func (g *graphState) label(id string, lines []string) {
+	n, _ := strconv.Atoi(id[1:])
+	g.nodes[n-1] = "\t" + id + " [label=" + dotLabel(lines) + "];\n"
+}
+
+// leafLabel returns the value v printed in a node label.
+func (g *graphState) leafLabel(v reflect.Value) string {
+	if !v.IsValid() || isNilable(v.Kind()) && v.IsNil() || v.Kind() == reflect.Interface && v.IsNil() {
+		return "nil"
+	}
+	return g.valueString(v, 0, 0, 0, "", true, true)
+}
+
+// dotLabel returns the lines quoted as a left-justified DOT label.
+func dotLabel(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		q := dotQuote(line)
+		b.WriteString(q[1:len(q)-1] + `\l`)
+	}
+	return `"` + b.String() + `"`
+}
+
+// dotQuote returns str quoted as a DOT string.
+func dotQuote(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	str = strings.ReplaceAll(str, `"`, `\"`)
+	return `"` + strings.ReplaceAll(str, "\n", `\n`) + `"`
+}
@@ -0,0 +1,53 @@
+package gdump
+
+import "testing"
+
+type graphNode struct {
+	Name string
+	Next *graphNode
+	Kids []*graphNode
+	M    map[string]int
+}
+
+// TestGraph draws the containers as the nodes of their scalars, and the
+// pointers, the shared values and the cycles as the edges between them.
+func TestGraph(t *testing.T) {
+	shared := &graphNode{Name: "shared"}
+	root := &graphNode{Name: `say "hi"`, Kids: []*graphNode{shared, shared}, M: map[string]int{"a": 1}}
+	root.Next = root
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"graph", Graph(root), `digraph gdump {
+	node [shape=box fontname=monospace];
+	n1 [label="gdump.graphNode\lName: say \"hi\"\l"];
+	n2 [label="[]*gdump.graphNode len:2\l"];
+	n3 [label="gdump.graphNode\lName: shared\lNext: nil\lKids: nil\lM: nil\l"];
+	n4 [label="map[string]int len:1\la: 1\l"];
+	n2 -> n3 [label="0"];
+	n2 -> n3 [label="1"];
+	n1 -> n1 [label="Next"];
+	n1 -> n2 [label="Kids"];
+	n1 -> n4 [label="M"];
+}
+`},
+		{"depth", New(WithDepth(1)).Graph(root), `digraph gdump {
+	node [shape=box fontname=monospace];
+	n1 [label="gdump.graphNode\lName: say \"hi\"\l"];
+	n2 [label="[]*gdump.graphNode len:2\l0: *gdump.graphNode{4 fields …}\l1: *gdump.graphNode{→ Kids.0}\l"];
+	n3 [label="map[string]int len:1\la: 1\l"];
+	n1 -> n1 [label="Next"];
+	n1 -> n2 [label="Kids"];
+	n1 -> n3 [label="M"];
+}
+`},
+		{"scalar", Graph(5), "digraph gdump {\n\tnode [shape=box fontname=monospace];\n\tn1 [label=\"5\\l\"];\n}\n"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: Graph =\n%s\nwant\n%s", tt.name, tt.got, tt.want)
+		}
+	}
+}
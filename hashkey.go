@@ -0,0 +1,36 @@
+package gdump
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"unicode/utf8"
+)
+
+// DumpWithKeyTable returns a string representation of value dumped with opts
+// and the table of the map keys hashed by opts.HashLongKeys. The table maps
+// the printed hashes, e.g. "#1a2b3c4d", to the full keys.
+func DumpWithKeyTable(value interface{}, opts Options) (string, map[string]string) {
+	if opts.SnapshotFirst {
+		value = snapshot(value)
+	}
+	ds := &dumpState{Options: opts, keyTable: map[string]string{}}
+	ds.startTimer()
+	s := ds.valueString(reflect.ValueOf(value), opts.Depth, 0, 0, "", false, false)
+	return ds.decorate(value, s), ds.keyTable
+}
+
+// hashKey returns the formatted map key or its hash if it is longer than
+// HashLongKeys.
+func (s *dumpState) hashKey(key string) string {
+	if s.HashLongKeys <= 0 || utf8.RuneCountInString(key) <= s.HashLongKeys {
+		return key
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	hashed := fmt.Sprintf("#%08x", h.Sum32())
+	if s.keyTable != nil {
+		s.keyTable[hashed] = key
+	}
+	return hashed
+}
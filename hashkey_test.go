@@ -0,0 +1,42 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestHashLongKeys prints the keys longer than the threshold in runes as
+// their hashes and recovers the full keys from the table.
+func TestHashLongKeys(t *testing.T) {
+	long := strings.Repeat("k", 20)
+	m := map[string]int{long: 1, long + "é": 3, "short": 2, "ééééé": 4}
+	opts := Options{Depth: 2, HashLongKeys: 10}
+	out, table := DumpWithKeyTable(m, opts)
+	if want := "map[string]int{\n• #178dd149:int{1}\n• #b71b41b5:int{3}\n• short:int{2}\n• ééééé:int{4}}"; out != want {
+		t.Errorf("DumpWithKeyTable = %q, want %q", out, want)
+	}
+	if want := map[string]string{"#178dd149": long, "#b71b41b5": long + "é"}; !reflect.DeepEqual(table, want) {
+		t.Errorf("key table = %q, want %q", table, want)
+	}
+	// the same hashes without the table
+	if got := Sdump(m, opts); got != out {
+		t.Errorf("Sdump = %q, want %q", got, out)
+	}
+
+	// the formatted keys of the other kinds are hashed as printed
+	_, table = DumpWithKeyTable(map[[2]string]int{{long, "x"}: 1}, opts)
+	if want := map[string]string{"#8876e069": "[" + long + " x]"}; !reflect.DeepEqual(table, want) {
+		t.Errorf("key table = %q, want %q", table, want)
+	}
+
+	// the values are aligned to the hashes printed
+	aligned := Sdump(map[string]int{long: 1, "short": 2}, Options{Depth: 2, HashLongKeys: 10, AlignMapValues: true})
+	if want := "map[string]int{\n• #178dd149:int{1}\n• short:    int{2}}"; aligned != want {
+		t.Errorf("Sdump aligned = %q, want %q", aligned, want)
+	}
+
+	if out, table := DumpWithKeyTable(m, Options{Depth: 2}); strings.Contains(out, "#") || len(table) != 0 {
+		t.Errorf("DumpWithKeyTable = %q, %q without HashLongKeys, want the full keys", out, table)
+	}
+}
@@ -0,0 +1,61 @@
+package gdump
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+)
+
+// htmlStyle - the style sheet embedded in the documents of FormatHTML
+const htmlStyle = `<style>
+.gdump{font-family:monospace;font-size:13px;line-height:1.4}
+.gdump details{margin-left:1.2em}
+.gdump summary{cursor:pointer;margin-left:-1.2em}
+.gdump .gd-leaf{margin-left:1.2em}
+.gdump .gd-name{color:#0550ae}
+.gdump .gd-type{color:#6e7781}
+.gdump .gd-value{color:#0a3069}
+</style>`
+
+// htmlString returns value dumped as a self-contained HTML fragment of the
+// collapsible tree of the dumped fields and entries. The top-level value is
+// expanded and the others are collapsed.
+func (s *dumpState) htmlString(value interface{}) string {
+	var b bytes.Buffer
+	b.WriteString(htmlStyle + "\n<div class=\"gdump\">\n")
+	s.writeHTML(&b, s.documentTree(value), true)
+	b.WriteString("</div>")
+	return b.String()
+}
+
+// writeHTML writes the node n as a <details> element if n has children, or
+// as a leaf <div> otherwise. The names, types and values are escaped.
+func (s *dumpState) writeHTML(b *bytes.Buffer, n *DumpNode, open bool) {
+	label := ""
+	if n.Name != "" {
+		label = `<span class="gd-name">` + html.EscapeString(n.Name) + "</span>: "
+	}
+	if n.Type != "" {
+		label += `<span class="gd-type">` + html.EscapeString(n.Type) + "</span>"
+	}
+	if len(n.Children) == 0 {
+		if n.Value != "" {
+			if n.Type != "" {
+				label += " "
+			}
+			label += `<span class="gd-value">` + html.EscapeString(n.Value) + "</span>"
+		}
+		b.WriteString(`<div class="gd-leaf">` + label + "</div>\n")
+		return
+	}
+	if open {
+		b.WriteString("<details open>")
+	} else {
+		b.WriteString("<details>")
+	}
+	b.WriteString("<summary>" + label + ` <span class="gd-type">(` + strconv.Itoa(len(n.Children)) + ")</span></summary>\n")
+	for _, c := range n.Children {
+		s.writeHTML(b, c, false)
+	}
+	b.WriteString("</details>\n")
+}
@@ -0,0 +1,38 @@
+package gdump
+
+import (
+	"strings"
+	"testing"
+)
+
+type htmlPage struct {
+	Title string
+	Tags  []string
+	Attrs map[string]string
+}
+
+// TestHTML dumps the values as the collapsible trees of the escaped names,
+// types and values, only the top-level node expanded.
+func TestHTML(t *testing.T) {
+	v := htmlPage{Title: "<b>&</b>", Tags: []string{"x"}, Attrs: map[string]string{"a<": "1"}}
+	got := Sdump(v, Options{Depth: 3, Format: FormatHTML})
+	want := htmlStyle + "\n<div class=\"gdump\">\n" +
+		"<details open><summary><span class=\"gd-type\">gdump.htmlPage</span> <span class=\"gd-type\">(3)</span></summary>\n" +
+		"<div class=\"gd-leaf\"><span class=\"gd-name\">Title</span>: <span class=\"gd-type\">string</span> <span class=\"gd-value\">&lt;b&gt;&amp;&lt;/b&gt;</span></div>\n" +
+		"<details><summary><span class=\"gd-name\">Tags</span>: <span class=\"gd-type\">[]string</span> <span class=\"gd-type\">(1)</span></summary>\n" +
+		"<div class=\"gd-leaf\"><span class=\"gd-name\">0</span>: <span class=\"gd-type\">string</span> <span class=\"gd-value\">x</span></div>\n" +
+		"</details>\n" +
+		"<details><summary><span class=\"gd-name\">Attrs</span>: <span class=\"gd-type\">map[string]string</span> <span class=\"gd-type\">(1)</span></summary>\n" +
+		"<div class=\"gd-leaf\"><span class=\"gd-name\">a&lt;</span>: <span class=\"gd-type\">string</span> <span class=\"gd-value\">1</span></div>\n" +
+		"</details>\n" +
+		"</details>\n" +
+		"</div>"
+	if got != want {
+		t.Errorf("Sdump =\n%s\nwant\n%s", got, want)
+	}
+
+	got = Sdump(nil, Options{Depth: 1, Format: FormatHTML})
+	if !strings.HasSuffix(got, "<div class=\"gdump\">\n<div class=\"gd-leaf\"><span class=\"gd-value\">nil</span></div>\n</div>") {
+		t.Errorf("Sdump = %s, want the nil leaf", got)
+	}
+}
@@ -0,0 +1,147 @@
+package gdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonTypeKey - the key of the type name added by Options.JSONTypes
+const jsonTypeKey = "__type"
+
+// jsonString returns value dumped as a JSON document.
+func (s *dumpState) jsonString(value interface{}) string {
+	var b bytes.Buffer
+	s.writeJSON(&b, s.documentTree(value), "")
+	return b.String()
+}
+
+// documentTree returns the tree of value rendered by the document formats
+// such as FormatJSON. The zero structs are dumped field by field, so that
+// they are printed as the objects of their fields, or {} if they have none.
+func (s *dumpState) documentTree(value interface{}) *DumpNode {
+	s.expandZero = true
+	return s.walkTree(value)
+}
+
+// walkTree returns the tree of value dumped without the compact slices and
+// the tables, so that the depth, the excluded fields and the other options
+// are applied as the text format. The values dumped before are referred to
+// by their paths, e.g. → Spec.Config, as the tree has no numbers of them.
+func (s *dumpState) walkTree(value interface{}) *DumpNode {
+	s.node = &DumpNode{}
+	s.refPaths = true
+	s.CompactPrimitiveSlices = false
+	s.TableSlices = false
+	s.valueString(reflect.ValueOf(value), s.Depth, 0, 0, "", false, false)
+	return s.node
+}
+
+// isDocumentLeaf returns true if n is printed as a scalar by the document
+// formats. The structs, maps, slices and arrays without a value printed,
+// e.g. the empty ones, are the containers without the children.
+func isDocumentLeaf(n *DumpNode) bool {
+	if n.Value == "..." {
+		return true
+	}
+	if len(n.Children) > 0 {
+		return false
+	}
+	switch n.base {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return n.Value != ""
+	}
+	return true
+}
+
+// isDocumentList returns true if n is printed as a list by the document formats.
+func isDocumentList(n *DumpNode) bool {
+	return n.base == reflect.Slice || n.base == reflect.Array
+}
+
+// documentEntries returns the keys and the nodes of the entries of the
+// object n printed by the document formats.
+func (s *dumpState) documentEntries(n *DumpNode) ([]string, []*DumpNode) {
+	keys := make([]string, 0, len(n.Children)+1)
+	values := make([]*DumpNode, 0, len(n.Children)+1)
+	if s.JSONTypes && n.Type != "" {
+		keys = append(keys, jsonTypeKey)
+		values = append(values, &DumpNode{Value: n.Type, base: reflect.String})
+	}
+	for _, c := range n.Children {
+		keys = append(keys, c.Name)
+		values = append(values, c)
+	}
+	return keys, values
+}
+
+// writeJSON writes the node n as a JSON value indented by indent.
+func (s *dumpState) writeJSON(b *bytes.Buffer, n *DumpNode, indent string) {
+	if isDocumentLeaf(n) {
+		b.WriteString(jsonLeaf(n))
+		return
+	}
+	inner := indent + "  "
+	if isDocumentList(n) {
+		if len(n.Children) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[")
+		for i, c := range n.Children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n" + inner)
+			s.writeJSON(b, c, inner)
+		}
+		b.WriteString("\n" + indent + "]")
+		return
+	}
+	keys, values := s.documentEntries(n)
+	if len(keys) == 0 {
+		b.WriteString("{}")
+		return
+	}
+	b.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n" + inner + jsonQuote(key) + ": ")
+		s.writeJSON(b, values[i], inner)
+	}
+	b.WriteString("\n" + indent + "}")
+}
+
+// jsonLeaf returns the value of the leaf node n in JSON. The nils are null,
+// the numbers and booleans are printed as they are if valid in JSON, and the
+// others including the bytes are strings.
+func jsonLeaf(n *DumpNode) string {
+	switch n.base {
+	case reflect.Invalid, reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Func, reflect.Chan:
+		if n.Value == "nil" || n.Value == "" {
+			return "null"
+		}
+		if str, err := strconv.Unquote(n.Value); err == nil {
+			// the bytes printed as a quoted string
+			return jsonQuote(str)
+		}
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if json.Valid([]byte(n.Value)) && !strings.ContainsAny(n.Value, "\"{[") {
+			return n.Value
+		}
+	}
+	return jsonQuote(n.Value)
+}
+
+// jsonQuote returns str quoted as a JSON string.
+func jsonQuote(str string) string {
+	b, _ := json.Marshal(str)
+	return string(b)
+}
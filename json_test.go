@@ -0,0 +1,64 @@
+package gdump
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type jsonEmpty struct{}
+
+type jsonInner struct {
+	A int
+	B string
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     interface{}
+		types bool
+		want  string
+	}{
+		{"empty struct", struct{}{}, false, `{}`},
+		{"empty struct typed", jsonEmpty{}, true, `{"__type":"gdump.jsonEmpty"}`},
+		{"empty fields", struct {
+			E jsonEmpty
+			P *jsonEmpty
+			M map[string]int
+			N map[string]int
+			L []int
+		}{P: &jsonEmpty{}, M: map[string]int{}, L: []int{}}, false,
+			`{"E":{},"P":{},"M":{},"N":null,"L":[]}`},
+		{"zero struct", struct {
+			I jsonInner
+			X int
+		}{X: 1}, false, `{"I":{"A":0,"B":""},"X":1}`},
+		{"values", struct {
+			S  string
+			F  float64
+			Ok bool
+			L  []string
+			M  map[string]jsonInner
+		}{"s", 1.5, true, []string{"a"}, map[string]jsonInner{"k": {1, "b"}}}, false,
+			`{"S":"s","F":1.5,"Ok":true,"L":["a"],"M":{"k":{"A":1,"B":"b"}}}`},
+		{"nil", nil, false, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Sdump(tt.v, Options{Depth: 4, Format: FormatJSON, JSONTypes: tt.types})
+			var got interface{}
+			if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", out, err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("Sdump = %s, want %s", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// bullets returns out with the last indentation unit of each line replaced
+// by Options.Bullet, e.g. "  - " for the second level of "  " and "- ".
+func (s *dumpState) bullets(out string) string {
+	unit := s.indentUnit()
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		n := 0
+		for strings.HasPrefix(line[n*len(unit):], unit) {
+			n++
+		}
+		if n > 0 {
+			lines[i] = strings.Repeat(unit, n-1) + s.Bullet + line[n*len(unit):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fitsWidth returns true if the struct, slice, array or map v dumped in
+// depth is printed in a line within Options.CompactWidth runes. It is dumped
+// once by another state to be measured, so that the side effects such as
+// the tree nodes are made only when it is dumped for real.
+func (s *dumpState) fitsWidth(v reflect.Value, depth, level int) bool {
+	if s.CompactWidth <= 0 || depth <= 0 || s.ElementSeparator != "" {
+		return false
+	}
+	e := v
+	for (e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface) && !e.IsNil() {
+		e = e.Elem()
+	}
+	switch e.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return false
+	}
+	sub := &dumpState{Options: s.Options, path: s.path}
+	sub.CompactWidth = 0
+	out := sub.valueString(v, depth, level, 0, "", true, true)
+	return utf8.RuneCountInString(out) <= s.CompactWidth
+}
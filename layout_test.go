@@ -0,0 +1,64 @@
+package gdump
+
+import (
+	"strings"
+	"testing"
+)
+
+type layoutItem struct {
+	A int
+	M map[string]int
+	S struct{ X, Y int }
+}
+
+// TestIndentUnit nests the levels by the package-level and per-call
+// indentation units without the default bullets left.
+func TestIndentUnit(t *testing.T) {
+	v := layoutItem{1, map[string]int{"k": 4}, struct{ X, Y int }{5, 6}}
+	const want = "gdump.layoutItem{\n_A:int{1}\n_M:map[string]int{\n__k:int{4}}\n_S:struct { X int; Y int }{\n__X:int{5}\n__Y:int{6}}}"
+	for _, unit := range []string{"  ", "\t", "--"} {
+		got := Sdump(v, Options{Depth: 3, IndentUnit: unit})
+		if want := strings.ReplaceAll(want, "_", unit); got != want {
+			t.Errorf("Sdump with %q = %q, want %q", unit, got, want)
+		}
+	}
+
+	defer func(unit string) { IndentUnit = unit }(IndentUnit)
+	IndentUnit = "  "
+	got := ValueDump(v, 3, nil)
+	if want := strings.ReplaceAll(want, "_", "  ") + "\n"; got != want {
+		t.Errorf("ValueDump = %q, want %q", got, want)
+	}
+	// the options of a call are not changed by the package-level unit
+	if got := Sdump(v, Options{Depth: 3, IndentUnit: "\t"}); strings.Contains(got, "  ") || strings.Contains(got, "•") {
+		t.Errorf("Sdump = %q, want only the tabs", got)
+	}
+}
+
+// TestBulletAndCompactWidth puts the bullets in place of the last units and
+// prints the nested values fitting in the width in a line.
+func TestBulletAndCompactWidth(t *testing.T) {
+	v := layoutItem{1, map[string]int{"k": 4, "long key": 5}, struct{ X, Y int }{5, 6}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"markdown", []Option{WithIndent("  "), WithBullet("- ")},
+			"gdump.layoutItem{\n- A:int{1}\n- M:map[string]int{\n  - k:int{4}\n  - long key:int{5}}\n- S:struct { X int; Y int }{\n  - X:int{5}\n  - Y:int{6}}}\n"},
+		{"default unit", []Option{WithBullet("* ")},
+			"gdump.layoutItem{\n* A:int{1}\n* M:map[string]int{\n• * k:int{4}\n• * long key:int{5}}\n* S:struct { X int; Y int }{\n• * X:int{5}\n• * Y:int{6}}}\n"},
+		// S does not fit in 41 runes with its type
+		{"compact", []Option{WithCompactWidth(41)},
+			"gdump.layoutItem{\n• A:int{1}\n• M:map[string]int{k:int{4} long key:int{5}}\n• S:struct { X int; Y int }{\n• • X:int{5}\n• • Y:int{6}}}\n"},
+		{"compact markdown", []Option{WithIndent("  "), WithBullet("- "), WithCompactWidth(42)},
+			"gdump.layoutItem{\n- A:int{1}\n- M:map[string]int{k:int{4} long key:int{5}}\n- S:struct { X int; Y int }{X:int{5} Y:int{6}}}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(append([]Option{WithDepth(3)}, tt.opts...)...).Sdump(v); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
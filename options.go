@@ -0,0 +1,410 @@
+package gdump
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Options - the options of a dump
+type Options struct {
+	// Format - the output format of Sdump
+	Format Format
+	// Depth - the print depth of the dumped value
+	Depth int
+	// ExcludedField - the names of the struct fields and string map keys not
+	// printed. The names having dots are the dotted paths from the top, e.g.
+	// User.Credentials.Password or Users.*.Password, where ** matches any
+	// number of the segments, e.g. **.InternalState.
+	ExcludedField []string
+	// ExcludedTypes - the types of the struct fields not printed, e.g.
+	// sync.Mutex
+	ExcludedTypes []reflect.Type
+	// TypeDepth - the print depth of the values of the types and the pointers
+	// to them, replacing the depth left at the values, e.g. 0 for a client
+	// connection embedded in a config. The struct tag `gdump:"depth=N"` sets
+	// the depth of a field instead.
+	TypeDepth map[reflect.Type]int
+	// FieldFilter - the function selecting the struct fields printed. The
+	// fields it returns false for are not printed. The zero structs are
+	// printed by their fields selected if set.
+	FieldFilter FieldFilterFunc
+	// IncludedField - the names of the struct fields and string map keys only
+	// printed if set. ExcludedField is applied first.
+	IncludedField []string
+	// IncludedPaths - the dotted paths of the struct fields and string map
+	// keys only printed if set, e.g. Spec.Replicas or Items.*.Name. Their
+	// ancestors are printed to reach them and their descendants are fully
+	// printed. The others are elided as …(+N more). A name without dots is
+	// the field or key of the name at any level reached.
+	IncludedPaths []string
+	// MaxItems - the maximum number of the slice and map entries printed.
+	// The rest of the entries are elided. 0 means unlimited.
+	MaxItems int
+	// MaxStringLen - the maximum number of the runes of the strings and the
+	// bytes of the byte slices printed. The rest is elided. 0 means unlimited.
+	MaxStringLen int
+	// RawStrings - prints the strings as they are. By default, the strings
+	// and the map keys having newlines, control characters such as the ANSI
+	// escapes, or invalid UTF-8 are quoted like strconv.Quote.
+	RawStrings bool
+	// MultilineStrings - prints the lines of the strings having newlines in
+	// the indented lines instead of quoting them
+	MultilineStrings bool
+	// BytesFormat - the format of the byte slices and arrays
+	BytesFormat BytesFormat
+	// TruncateStrategy - the entries kept when truncated by MaxItems
+	TruncateStrategy TruncateStrategy
+	// MinFullDepth - the entries of the values shallower than the level
+	// are fully printed regardless of the item limits such as MaxItems.
+	// The top-level value is at level 0.
+	MinFullDepth int
+	// UseStringer - prints the values implementing error, fmt.Stringer and
+	// the other interfaces of RendererPriority via their methods instead of
+	// their fields
+	UseStringer bool
+	// UnwrapErrors - prints the errors wrapping other errors as the chains
+	// of the errors unwrapped like errors.Unwrap, e.g. *fs.PathError{...} →
+	// syscall.Errno{2}. The errors of the chains are printed by Error() if
+	// UseStringer is set, otherwise by their fields in a line.
+	UnwrapErrors bool
+	// IgnoreStringer - the types printed by their fields even if UseStringer
+	// is set. The pointers to the types are also ignored.
+	IgnoreStringer []reflect.Type
+	// ShowUnexported - dumps the unexported struct fields like the exported
+	// ones by reading them via unsafe. The fields of non-addressable structs
+	// that can't be copied are printed as T{unexported} unless primitive.
+	ShowUnexported bool
+	// NoMethodCalls - never calls the methods of the dumped values, so that
+	// the dump has no side effect. It disables UseStringer, the rendering of
+	// context.Context and url.URL and the formatting of values via fmt.
+	NoMethodCalls bool
+	// RendererPriority - the interfaces checked in order by UseStringer.
+	// DefaultRendererPriority is used if nil.
+	RendererPriority []RendererKind
+	// DecodeBits - prints the bit ranges of the integer fields tagged with
+	// `gdump:"bits=hi:lo:name,..."`, e.g. `gdump:"bits=7:7:ready,6:4:mode"`
+	DecodeBits bool
+	// FlattenEmbedded - prints the fields of the embedded structs and the
+	// pointers to them in place of the embedded fields at the level of the
+	// embedding struct, prefixed by the embedded fields, e.g. Base.ID:int{1},
+	// as are the keys of FormatJSON and FormatYAML
+	FlattenEmbedded bool
+	// CollapseSingleField - prints the structs having a single field in
+	// the line of the struct, e.g. main.ID{V:string{x}}
+	CollapseSingleField bool
+	// SingleFieldAsValue - prints the structs collapsed by
+	// CollapseSingleField as their field values, e.g. main.ID(string{x})
+	SingleFieldAsValue bool
+	// ShowOffsets - prints the byte offset and size of the struct fields,
+	// e.g. Name@0+16:string{...}
+	ShowOffsets bool
+	// ShowFieldTags - prints the struct tags of the fields, whether they are
+	// exported, and their offsets and sizes after the field names, e.g.
+	// Name(`json:"name"` exported @0+16):string{...}
+	ShowFieldTags bool
+	// ShowConstraints - prints the type constraints registered by
+	// RegisterConstraint before the values of the struct fields
+	ShowConstraints bool
+	// CompactPrimitiveSlices - prints the slices of numbers, strings and
+	// booleans in a line, e.g. []int{1, 2, 3}
+	CompactPrimitiveSlices bool
+	// ElementSeparator - the separator of the slice and map entries printed
+	// in the line of the slice or map, e.g. ", ". The entries are printed
+	// in lines if empty.
+	ElementSeparator string
+	// HashLongKeys - the length in runes above which the map keys are
+	// printed as #<8-hex-hash>. The full keys are returned by
+	// DumpWithKeyTable. 0 means no hashing.
+	HashLongKeys int
+	// AlignMapValues - aligns the values of the map entries printed in lines
+	// by padding the keys. Numeric keys are right-aligned like a table.
+	AlignMapValues bool
+	// HighlightPaths - the dotted paths of the struct fields and map entries
+	// marked with ▶, e.g. "Spec.Replicas" or "Labels.app". The elements of
+	// slices are addressed by their indices, e.g. "Items.0.Name".
+	HighlightPaths []string
+	// ExtractLargeValues - the size in bytes above which the dumped child
+	// values are moved to the attachments of DumpWithAttachments and
+	// replaced with <see attachment #N>. 0 means no extraction.
+	ExtractLargeValues int
+	// IndentUnit - the indentation added to each nested level ("• " if empty)
+	IndentUnit string
+	// Bullet - the marker put in place of the last indentation unit of the
+	// lines, e.g. "- " for the lists of markdown with IndentUnit "  "
+	Bullet string
+	// CompactWidth - prints the structs, slices and maps nested in a value in
+	// a line if they fit in the number of runes. 0 means disabled.
+	CompactWidth int
+	// NewlineAtEnd - inserts a newline after the dump of Dump
+	NewlineAtEnd bool
+	// KeepMapOrder - prints the map entries in the iteration order of the
+	// maps instead of the order of their keys
+	KeepMapOrder bool
+	// ShowPointerAddr - prints the addresses of the values referenced by
+	// pointers, e.g. *0xc000012345 main.T{...}, and the addresses of the
+	// functions and channels. The unsafe pointers always print theirs.
+	ShowPointerAddr bool
+	// PointerMarker - the marker of the values referenced by pointers ("*" if empty)
+	PointerMarker string
+	// InterfaceMarker - the marker of the dynamic values of interfaces ("○" if empty)
+	InterfaceMarker string
+	// FocusPath - the dotted path of the value dumped with Depth wherever it
+	// is. Its ancestors are printed with it and the other values are
+	// collapsed to "...".
+	FocusPath string
+	// IncludeLegend - prepends a line explaining the markers used in the dump
+	IncludeLegend bool
+	// MarkdownFence - wraps the dump in a markdown code fence, with the
+	// format name as the language hint, after a line summarizing the value
+	MarkdownFence bool
+	// SnapshotFirst - dumps a deep copy of the value made before the dump
+	// to reduce the torn state seen while the value is updated by another
+	// goroutine. The copy is not atomic and costs the allocation of the
+	// whole value. The unexported fields, channels and functions are not
+	// copied deeply.
+	SnapshotFirst bool
+	// DedupEqualSubtrees - prints the values having nested values equal to
+	// the value dumped before as a back-reference to its path, e.g.
+	// main.Config{(same as above: Web.Config)}
+	DedupEqualSubtrees bool
+	// PrettyJSON - re-indents the JSON of json.RawMessage printed as its text
+	PrettyJSON bool
+	// OmitZero - omits the struct fields having zero values
+	OmitZero bool
+	// CountZeroFields - omits the struct fields having zero values like
+	// OmitZero and prints the numbers of the set and zero fields after the
+	// type of the struct, e.g. main.Config(3 set, 9 zero){...}
+	CountZeroFields bool
+	// BareLeavesAtMaxDepth - prints the values other than structs, maps,
+	// slices and arrays at the depth limit without their types, e.g. 1
+	// instead of int{1}
+	BareLeavesAtMaxDepth bool
+	// PlainEllipsis - prints the children of the structs, maps, slices and
+	// arrays at the depth limit as "..." instead of the summaries of them,
+	// e.g. map[string]*main.User{len:42 …} or main.Config{12 fields …}
+	PlainEllipsis bool
+	// RecognizeSets - prints the maps of empty structs, e.g.
+	// map[string]struct{}, as the sets of their keys, e.g. set[string]{a b c}
+	RecognizeSets bool
+	// RedactHighEntropy - prints the strings looking like secrets, i.e.
+	// having no space and at least 16 characters of the entropy above
+	// EntropyThreshold, as ***REDACTED***. It may also redact hashes, ids and
+	// other random but not secret strings, and miss secrets such as short
+	// or hex-encoded ones below the threshold.
+	RedactHighEntropy bool
+	// EntropyThreshold - the entropy in bits per character above which the
+	// strings are redacted. DefaultEntropyThreshold is used if 0.
+	EntropyThreshold float64
+	// RawStdTypes - prints time.Time, time.Duration, url.URL and the IP
+	// addresses and networks of net and net/netip like the other types, i.e.
+	// by their methods if UseStringer is set or as their fields or elements,
+	// instead of their text forms, e.g. RFC 3339 of time.Time
+	RawStdTypes bool
+	// ExpandURLs - prints the fields of url.URL instead of the URL string,
+	// e.g. url.URL{https://host/path?q=1}
+	ExpandURLs bool
+	// PerValueTimeout - the time limit of dumping a value. The values not
+	// dumped within the limit are printed as …(timeout). It is applied to
+	// each value of SdumpAll independently. 0 means unlimited.
+	PerValueTimeout time.Duration
+	// MaxNodes - the number of the values dumped in total. The entries of
+	// the containers beyond the limit are printed as
+	// …(truncated, N of M shown). 0 means unlimited.
+	MaxNodes int
+	// MaxOutputBytes - the maximum number of the bytes of the output. The
+	// dump stops once the leaves printed exceed it, and the output is cut at
+	// it with the mark …(truncated at N bytes). 0 means unlimited.
+	MaxOutputBytes int
+	// Color - colors the types, the field names and map keys and the values
+	// of FormatText by the ANSI escape codes. The nil and zero values are
+	// dimmed.
+	Color bool
+	// RedactFields - the names of the struct fields and string map keys
+	// printed as ***REDACTED***, compared case-insensitively. The fields tagged
+	// with `gdump:"redact"` are also redacted.
+	RedactFields []string
+	// RedactPattern - the pattern of the names of the struct fields and
+	// string map keys printed as ***REDACTED***, e.g. DefaultRedactPattern
+	RedactPattern *regexp.Regexp
+	// Formatters - the functions printing the values of the types instead
+	// of their fields or elements at any depth
+	Formatters map[reflect.Type]FormatterFunc
+	// TableSlices - prints the slices and arrays of structs as the tables of
+	// their fields in the columns, one row per element
+	TableSlices bool
+	// MaxColumnWidth - the maximum number of the runes of the cells of the
+	// tables printed by TableSlices. The rest is elided. 0 means unlimited.
+	MaxColumnWidth int
+	// JSONTypes - adds the type names of the structs and maps as "__type"
+	// to the objects of FormatJSON and FormatYAML
+	JSONTypes bool
+	// MapKeyLess - the function reporting whether the map key a is printed
+	// before b. The keys are sorted in the natural order if nil.
+	MapKeyLess func(a, b reflect.Value) bool
+	// ShowValidation - marks the struct fields violating the rules tagged
+	// with `gdump:"validate=rule,..."`, e.g. `gdump:"validate=required,min:1"`,
+	// with ⚠ and the rules. The rules are required, nonempty, min:N and max:N.
+	ShowValidation bool
+	// NewRenderer - the function returning the Renderer of each dump, which
+	// renders the DumpNode tree of the value in place of the Format if set
+	NewRenderer func() Renderer
+
+	// rules - the decisions on the struct fields cached for the options of
+	// a Dumper, or nil to make them for each dump
+	rules *fieldRules
+}
+
+// clone returns a copy of o not sharing the slices and maps of o, nor the
+// decisions cached for o.
+func (o Options) clone() Options {
+	o.rules = nil
+	o.ExcludedField = slices.Clone(o.ExcludedField)
+	o.ExcludedTypes = slices.Clone(o.ExcludedTypes)
+	o.IncludedField = slices.Clone(o.IncludedField)
+	o.IncludedPaths = slices.Clone(o.IncludedPaths)
+	o.IgnoreStringer = slices.Clone(o.IgnoreStringer)
+	o.RendererPriority = slices.Clone(o.RendererPriority)
+	o.HighlightPaths = slices.Clone(o.HighlightPaths)
+	o.RedactFields = slices.Clone(o.RedactFields)
+	o.TypeDepth = maps.Clone(o.TypeDepth)
+	o.Formatters = maps.Clone(o.Formatters)
+	return o
+}
+
+// Sdump returns a string representation of value dumped with opts.
+func Sdump(value interface{}, opts Options) string {
+	return sdump(context.Background(), value, opts)
+}
+
+// sdump returns a string representation of value dumped with opts until ctx
+// is done.
+func sdump(ctx context.Context, value interface{}, opts Options) string {
+	if opts.SnapshotFirst {
+		value = snapshot(value)
+	}
+	ds := &dumpState{Options: opts}
+	ds.startTimer()
+	if ctx.Done() != nil {
+		ds.ctx = ctx
+	}
+	ds.methods = contextMethods(ctx)
+	var out string
+	switch {
+	case opts.NewRenderer != nil:
+		out = ds.renderedString(value, opts.NewRenderer())
+	case opts.Format == FormatSpewCompat:
+		out = ds.spewString(value)
+	case opts.Format == FormatJSON:
+		out = ds.jsonString(value)
+	case opts.Format == FormatYAML:
+		out = ds.yamlString(value)
+	case opts.Format == FormatGo:
+		out = ds.goString(value)
+	case opts.Format == FormatHTML:
+		out = ds.htmlString(value)
+	default:
+		out = ds.valueString(reflect.ValueOf(value), opts.Depth, 0, 0, "", false, false)
+	}
+	return ds.clip(ds.decorate(value, out))
+}
+
+// Dump returns a string representation of value dumped with o. Unlike the
+// package-level functions, it doesn't read the package-level variables, so
+// the callers can dump with different options concurrently.
+func (o Options) Dump(value interface{}) string {
+	return o.dump(context.Background(), value)
+}
+
+// dump returns a string representation of value dumped with o until ctx is
+// done.
+func (o Options) dump(ctx context.Context, value interface{}) string {
+	out := sdump(ctx, value, o)
+	// the spew format ends with a newline like spew.Sdump
+	if o.NewlineAtEnd && o.Format != FormatSpewCompat {
+		out += "\n"
+	}
+	return out
+}
+
+// decorate returns the string out dumped from value with the additions
+// configured in the options.
+func (s *dumpState) decorate(value interface{}, out string) string {
+	if s.Bullet != "" && s.Format == FormatText {
+		out = s.bullets(out)
+	}
+	if s.IncludeLegend && s.Format == FormatText {
+		out = s.legend() + "\n" + out
+	}
+	if s.MarkdownFence {
+		out = Summarize(value) + "\n```" + s.formatName() + "\n" + out + "\n```"
+	}
+	return out
+}
+
+// formatName returns the name of the output format used as the language
+// hint of the markdown fence.
+func (s *dumpState) formatName() string {
+	switch s.Format {
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	case FormatGo:
+		return "go"
+	case FormatHTML:
+		return "html"
+	}
+	return "text"
+}
+
+// legend returns a line explaining the markers used in the dump.
+func (s *dumpState) legend() string {
+	l := "legend: " + s.pointerMarker() + "=pointer " + s.interfaceMarker() + "=interface "
+	if unit := strings.TrimSpace(s.indentUnit()); unit != "" {
+		l += unit + "=nesting "
+	}
+	l += "{nil}=nil ...=depth limit #N=shared " + cycleMarker + " #N=cycle " + sharedMarker + " #N=shared again"
+	if s.MaxItems > 0 {
+		l += " …(+N more)=elided entries"
+	}
+	return l
+}
+
+// Format - the output format of a dump
+type Format int
+
+const (
+	// FormatText - the type{value} format of gdump
+	FormatText Format = iota
+	// FormatSpewCompat - the format of Sdump of davecgh/go-spew
+	FormatSpewCompat
+	// FormatJSON - the JSON document of the dumped fields and entries
+	FormatJSON
+	// FormatYAML - the YAML document of the dumped fields and entries
+	FormatYAML
+	// FormatGo - the Go composite literal of the value
+	FormatGo
+	// FormatHTML - the collapsible HTML tree of the dumped fields and entries
+	FormatHTML
+)
+
+// TruncateStrategy - the strategy to select the entries printed from a
+// slice or map truncated by Options.MaxItems
+type TruncateStrategy int
+
+// highlightMarker - the marker of the values listed in Options.HighlightPaths
+const highlightMarker = "▶"
+
+const (
+	// TruncHead - prints the first entries
+	TruncHead TruncateStrategy = iota
+	// TruncTail - prints the last entries
+	TruncTail
+	// TruncMiddle - prints both the first and last entries and elides the middle
+	TruncMiddle
+)
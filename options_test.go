@@ -0,0 +1,112 @@
+package gdump
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIncludeLegend(t *testing.T) {
+	x := 1
+	const markers = "{nil}=nil ...=depth limit #N=shared ↩ #N=cycle → #N=shared again"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default", Options{Depth: 2}, "legend: *=pointer ○=interface •=nesting " + markers + "\n*int{&1}"},
+		{"markers", Options{Depth: 2, PointerMarker: "^", InterfaceMarker: "@"}, "legend: ^=pointer @=interface •=nesting " + markers + "\n^int{&1}"},
+		// no nesting marker to explain for the blank indentation
+		{"blank indent", Options{Depth: 2, IndentUnit: "  "}, "legend: *=pointer ○=interface " + markers + "\n*int{&1}"},
+		{"max items", Options{Depth: 2, MaxItems: 3}, "legend: *=pointer ○=interface •=nesting " + markers + " …(+N more)=elided entries\n*int{&1}"},
+		{"json", Options{Depth: 2, Format: FormatJSON}, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.IncludeLegend = true
+			if got := Sdump(&x, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	fenced := Sdump(&x, Options{Depth: 2, IncludeLegend: true, MarkdownFence: true})
+	if !strings.HasPrefix(fenced, "*int (int)\n```text\nlegend: ") {
+		t.Errorf("Sdump = %q, want the legend in the fence", fenced)
+	}
+}
+
+// TestMarkdownFence wraps the dump in a fence hinted with the format name
+// after the summary line, as written to a writer.
+func TestMarkdownFence(t *testing.T) {
+	v := []int{1}
+	const summary = "[]int (slice, 1 elements, depth 1)\n"
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"text", FormatText, summary + "```text\n[]int{\n• int{1}}\n```"},
+		{"json", FormatJSON, summary + "```json\n[\n  1\n]\n```"},
+		{"yaml", FormatYAML, summary + "```yaml\n- 1\n```"},
+		{"go", FormatGo, summary + "```go\n[]int{1}\n```"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Depth: 2, Format: tt.format, MarkdownFence: true}
+			if got := Sdump(v, opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+			var b strings.Builder
+			if _, err := New(WithOptions(opts)).DumpTo(&b, v); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("DumpTo = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+
+	if got, want := Sdump(nil, Options{Depth: 2, MarkdownFence: true}), "nil\n```text\nnil{nil}\n```"; got != want {
+		t.Errorf("Sdump(nil) = %q, want %q", got, want)
+	}
+	if got := Sdump(v, Options{Depth: 2}); strings.Contains(got, "```") {
+		t.Errorf("Sdump = %q, want no fence by default", got)
+	}
+}
+
+// TestOptionsDump dumps with the options of each call rather than the
+// package-level variables, which only the package-level functions read,
+// so that the goroutines dump with different options at the same time.
+func TestOptionsDump(t *testing.T) {
+	v := map[string][]int{"b": {1, 2}, "a": {3}}
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Depth: 3}, "map[string][]int{\n• a:[]int{\n• • int{3}}\n• b:[]int{\n• • int{1}\n• • int{2}}}"},
+		{Options{Depth: 3, NewlineAtEnd: true, IndentUnit: "  "}, "map[string][]int{\n  a:[]int{\n    int{3}}\n  b:[]int{\n    int{1}\n    int{2}}}\n"},
+		{Options{Depth: 3, CompactPrimitiveSlices: true}, "map[string][]int{\n• a:[]int{3}\n• b:[]int{1, 2}}"},
+		{Options{Depth: 1}, "map[string][]int{\n• a:[]int{len:1 …}\n• b:[]int{len:2 …}}"},
+	}
+
+	defer func(unit string, newline bool) { IndentUnit, NewlineAtEnd = unit, newline }(IndentUnit, NewlineAtEnd)
+	IndentUnit, NewlineAtEnd = "--", true
+	if got, want := ValueDump(v, 3, nil), "map[string][]int{\n--a:[]int{3}\n--b:[]int{1, 2}}\n"; got != want {
+		t.Errorf("ValueDump = %q, want %q", got, want)
+	}
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		wg.Add(1)
+		go func(opts Options, want string) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if got := opts.Dump(v); got != want {
+					t.Errorf("Dump = %q, want %q", got, want)
+					return
+				}
+			}
+		}(tt.opts, tt.want)
+	}
+	wg.Wait()
+}
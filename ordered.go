@@ -0,0 +1,34 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DumpOrderedMap returns a string representation of the map m dumped with
+// opts. The map entries are printed in the order of keys. The keys not
+// present in m are skipped and the map keys not listed in keys are printed
+// at the end in the sorted order.
+func DumpOrderedMap[K comparable, V any](keys []K, m map[K]V, opts Options) string {
+	v := reflect.ValueOf(m)
+	if m == nil || opts.Depth < 0 {
+		return Sdump(m, opts)
+	}
+	ordered := make([]mapEntry, 0, len(m))
+	listed := make(map[K]bool, len(keys))
+	for _, k := range keys {
+		if _, ok := m[k]; !ok || listed[k] {
+			continue
+		}
+		listed[k] = true
+		key := reflect.ValueOf(k)
+		ordered = append(ordered, mapEntry{key: key, value: v.MapIndex(key)})
+	}
+	ds := &dumpState{Options: opts}
+	for _, e := range ds.sortedEntries(v) {
+		if !listed[e.key.Interface().(K)] {
+			ordered = append(ordered, e)
+		}
+	}
+	return ds.decorate(m, fmt.Sprintf("%s{%s}", v.Type(), ds.mapEntries(ordered, opts.Depth, 0, "", false)))
+}
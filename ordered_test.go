@@ -0,0 +1,28 @@
+package gdump
+
+import "testing"
+
+func TestDumpOrderedMap(t *testing.T) {
+	m := map[int]string{3: "c", 1: "a", 2: "b", 4: "d"}
+	tests := []struct {
+		name string
+		keys []int
+		m    map[int]string
+		opts Options
+		want string
+	}{
+		// the keys missing and repeated are skipped, the keys not listed follow sorted
+		{"listed first", []int{4, 9, 2, 4}, m, Options{Depth: 2}, "map[int]string{\n• 4:string{d}\n• 2:string{b}\n• 1:string{a}\n• 3:string{c}}"},
+		{"max items", []int{4, 2}, m, Options{Depth: 2, MaxItems: 3}, "map[int]string{\n• 4:string{d}\n• 2:string{b}\n• 1:string{a}\n• …(+1 more)}"},
+		{"depth", []int{4}, m, Options{Depth: 0}, "map[int]string{ 4: ... 1: ... 2: ... 3: ...}"},
+		{"empty", []int{4}, map[int]string{}, Options{Depth: 2}, "map[int]string{}"},
+		{"nil", []int{4}, nil, Options{Depth: 2}, "map[int]string{nil}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DumpOrderedMap(tt.keys, tt.m, tt.opts); got != tt.want {
+				t.Errorf("DumpOrderedMap = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
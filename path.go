@@ -0,0 +1,213 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DumpPath returns a string representation of the value at path in value
+// dumped in depth like ValueDump. The path is the dotted struct fields and
+// the map keys and slice indices in brackets, e.g.
+// Spec.Containers[0].Env["PATH"]. A string map key can be also a dotted
+// segment, e.g. Labels.app. The fields are named by their Go names or the
+// names of their tags.
+func DumpPath(value interface{}, path string, depth int) (string, error) {
+	v, err := Lookup(value, path)
+	if err != nil {
+		return "", err
+	}
+	return Sdump(v, globalOptions(depth)), nil
+}
+
+// DumpPath returns a string representation of the value at path in value
+// dumped with the options of d like the package-level DumpPath.
+func (d *Dumper) DumpPath(value interface{}, path string) (string, error) {
+	v, err := Lookup(value, path)
+	if err != nil {
+		return "", err
+	}
+	return d.Sdump(v), nil
+}
+
+// Lookup returns the value at path in value. See DumpPath for the path.
+func Lookup(value interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(value)
+	for i, seg := range segments {
+		if v, err = lookupSegment(v, seg); err != nil {
+			return nil, fmt.Errorf("gdump: %s: %w", joinSegments(segments[:i+1]), err)
+		}
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if !v.CanInterface() {
+		if v = exposeField(v); !v.CanInterface() {
+			return nil, fmt.Errorf("gdump: %s: unexported field", path)
+		}
+	}
+	return v.Interface(), nil
+}
+
+// pathSegment - a struct field or a string map key named by a dotted
+// segment, or a slice index or a map key in brackets
+type pathSegment struct {
+	name    string
+	bracket bool
+}
+
+// parsePath returns the segments of path.
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		switch c := path[i]; {
+		case c == '.':
+			if i == 0 || i+1 == len(path) || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, fmt.Errorf("gdump: %s: empty segment", path)
+			}
+			i++
+		case c == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if i+1 < len(path) && path[i+1] == '"' {
+				// the quoted key may have ] in it.
+				end = -1
+				for j := i + 2; j < len(path); j++ {
+					if path[j] == '\\' {
+						j++
+					} else if path[j] == '"' {
+						if j+1 < len(path) && path[j+1] == ']' {
+							end = j + 1 - i
+						}
+						break
+					}
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("gdump: %s: unterminated [", path)
+			}
+			key := path[i+1 : i+end]
+			if strings.HasPrefix(key, `"`) {
+				unquoted, err := strconv.Unquote(key)
+				if err != nil {
+					return nil, fmt.Errorf("gdump: %s: invalid key %s", path, key)
+				}
+				key = unquoted
+			}
+			segments = append(segments, pathSegment{name: key, bracket: true})
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, pathSegment{name: path[i : i+end]})
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+// joinSegments returns the path of the segments.
+func joinSegments(segments []pathSegment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		switch {
+		case seg.bracket:
+			b.WriteString("[" + seg.name + "]")
+		case b.Len() > 0:
+			b.WriteString("." + seg.name)
+		default:
+			b.WriteString(seg.name)
+		}
+	}
+	return b.String()
+}
+
+// lookupSegment returns the child value of v at the segment seg. The
+// pointers and interfaces are followed to the values they hold.
+func lookupSegment(v reflect.Value, seg pathSegment) (reflect.Value, error) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("nil value")
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if !seg.bracket {
+			for i, info := range typeFields(v.Type()) {
+				if info.ft.Name == seg.name || info.tag.name == seg.name {
+					return v.Field(i), nil
+				}
+			}
+		}
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), seg.name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if e := v.MapIndex(key); e.IsValid() {
+			return e, nil
+		}
+		return reflect.Value{}, fmt.Errorf("no key %q in %s", seg.name, v.Type())
+	case reflect.Slice, reflect.Array, reflect.String:
+		if seg.bracket {
+			i, err := strconv.Atoi(seg.name)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid index %q", seg.name)
+			}
+			if i < 0 || i >= v.Len() {
+				return reflect.Value{}, fmt.Errorf("index %d out of range of %d", i, v.Len())
+			}
+			return v.Index(i), nil
+		}
+	}
+	if seg.bracket {
+		return reflect.Value{}, fmt.Errorf("no index %s in %s", seg.name, v.Type())
+	}
+	return reflect.Value{}, fmt.Errorf("no field %s in %s", seg.name, v.Type())
+}
+
+// mapKey returns the map key of the type t written as str.
+func mapKey(t reflect.Type, str string) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(str, 0, t.Bits()); err == nil {
+			key.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(str, 0, t.Bits()); err == nil {
+			key.SetUint(u)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(str); err == nil {
+			key.SetBool(b)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(str, t.Bits()); err == nil {
+			key.SetFloat(f)
+		}
+	default:
+		return key, fmt.Errorf("unsupported key type %s", t)
+	}
+	if err != nil {
+		return key, fmt.Errorf("invalid key %q of %s", str, t)
+	}
+	return key, nil
+}
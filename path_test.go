@@ -0,0 +1,84 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type pathItem struct {
+	Name string `gdump:"name"`
+	Env  map[string]string
+}
+
+type pathRoot struct {
+	Items []pathItem
+	Ptr   *pathItem
+	Codes map[int]string
+	Any   interface{}
+	label string
+}
+
+func TestLookup(t *testing.T) {
+	v := &pathRoot{
+		Items: []pathItem{{"a", map[string]string{"PATH": "/bin", "a]b": "q", "x.y": "d"}}},
+		Codes: map[int]string{3: "three"},
+		Any:   pathItem{Name: "i"},
+		label: "l",
+	}
+	tests := []struct {
+		path string
+		want interface{}
+		err  string
+	}{
+		{"Items[0].Name", "a", ""},
+		{"Items[0].name", "a", ""},
+		{`Items[0].Env["PATH"]`, "/bin", ""},
+		{"Items[0].Env.PATH", "/bin", ""},
+		{`Items[0].Env["a]b"]`, "q", ""},
+		{`Items[0].Env["x.y"]`, "d", ""},
+		{"Codes.3", "three", ""},
+		{"Codes[0x3]", "three", ""},
+		{"Any.Name", "i", ""},
+		{"label", "l", ""},
+		{"Items.0", nil, "gdump: Items.0: no field 0 in []gdump.pathItem"},
+		{"Items[1]", nil, "gdump: Items[1]: index 1 out of range of 1"},
+		{"Items[x]", nil, `gdump: Items[x]: invalid index "x"`},
+		{"Ptr.Name", nil, "gdump: Ptr.Name: nil *gdump.pathItem"},
+		{"Codes.4", nil, `gdump: Codes.4: no key "4" in map[int]string`},
+		{"Codes.x", nil, `gdump: Codes.x: invalid key "x" of int`},
+		{"Items..Name", nil, "gdump: Items..Name: empty segment"},
+		{"Items[0", nil, "gdump: Items[0: unterminated ["},
+		{"Nope", nil, "gdump: Nope: no field Nope in gdump.pathRoot"},
+	}
+	for _, tt := range tests {
+		got, err := Lookup(v, tt.path)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Lookup(%q) = %v, %v, want the error %q", tt.path, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q) = %v, %v, want %v", tt.path, got, err, tt.want)
+		}
+	}
+	if got, err := Lookup(v, ""); err != nil || got != v {
+		t.Errorf("Lookup of the empty path = %v, %v, want the value", got, err)
+	}
+}
+
+// TestDumpPath dumps the values looked up in the depth from them.
+func TestDumpPath(t *testing.T) {
+	v := pathRoot{Items: []pathItem{{"a", map[string]string{"k": "v"}}}}
+	got, err := DumpPath(v, "Items[0]", 1)
+	if want := "gdump.pathItem{\n• name:string{a}\n• Env:map[string]string{len:1 …}}"; err != nil || got != want {
+		t.Errorf("DumpPath = %q, %v, want %q", got, err, want)
+	}
+	got, err = New(WithDepth(2)).DumpPath(v, "Items")
+	if want := "[]gdump.pathItem{\n• gdump.pathItem{\n• • name:string{a}\n• • Env:map[string]string{len:1 …}}}\n"; err != nil || got != want {
+		t.Errorf("Dumper.DumpPath = %q, %v, want %q", got, err, want)
+	}
+	if got, err := DumpPath(v, "Ptr.Name", 1); err == nil || got != "" {
+		t.Errorf("DumpPath = %q, %v, want an error", got, err)
+	}
+}
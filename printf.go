@@ -0,0 +1,48 @@
+package gdump
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// printfValue - the value dumped when formatted by the fmt package
+type printfValue struct {
+	value interface{}
+}
+
+// Formatter returns the fmt.Formatter of value dumped only when formatted,
+// e.g. log.Printf("state: %v", gdump.Formatter(s)). The verbs are:
+//   - %v and %s: dumped like ValueDump in DefaultPrintDepth
+//   - %+v: dumped in twice DefaultPrintDepth
+//   - %#v: dumped in a line like ValueDumpInline
+//
+// The precision sets the depth instead, e.g. %.5v. No newline is added at
+// the end.
+func Formatter(value interface{}) fmt.Formatter {
+	return printfValue{value: value}
+}
+
+// Format implements fmt.Formatter.
+func (p printfValue) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+	default:
+		fmt.Fprintf(f, "%%!%c(gdump)", verb)
+		return
+	}
+	depth := DefaultPrintDepth
+	if f.Flag('+') {
+		depth *= 2
+	}
+	if prec, ok := f.Precision(); ok {
+		depth = prec
+	}
+	opts := globalOptions(depth)
+	if f.Flag('#') {
+		ds := &dumpState{Options: opts}
+		io.WriteString(f, ds.inline(reflect.ValueOf(p.value)))
+		return
+	}
+	io.WriteString(f, Sdump(p.value, opts))
+}
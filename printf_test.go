@@ -0,0 +1,44 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type printfChain struct {
+	N    int
+	Next *printfChain
+}
+
+// TestFormatter dumps the value formatted by the verbs in the depths of the
+// flags and the precisions.
+func TestFormatter(t *testing.T) {
+	var v *printfChain
+	for i := 8; i > 0; i-- {
+		v = &printfChain{i, v}
+	}
+	inline := func(depth int) string {
+		return (&dumpState{Options: globalOptions(depth)}).inline(reflect.ValueOf(v))
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", Sdump(v, globalOptions(DefaultPrintDepth))},
+		{"%s", Sdump(v, globalOptions(DefaultPrintDepth))},
+		{"%+v", Sdump(v, globalOptions(2*DefaultPrintDepth))},
+		{"%.1v", "*gdump.printfChain{\n• N:int{1}\n• Next:*gdump.printfChain{2 fields …}}"},
+		{"%#v", inline(DefaultPrintDepth)},
+		{"%#.1v", "*gdump.printfChain{N:int{1} Next:*gdump.printfChain{2 fields …}}"},
+		{"%d", "%!d(gdump)"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, Formatter(v)); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got, want := fmt.Sprintf("v=%v.", Formatter(1)), "v=int{1}."; got != want {
+		t.Errorf("Sprintf = %q, want %q without a newline", got, want)
+	}
+}
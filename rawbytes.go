@@ -0,0 +1,47 @@
+package gdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+var (
+	textBytesMutex sync.RWMutex
+	// textBytes - the []byte types printed as their content
+	textBytes = map[reflect.Type]bool{rawMessageType: true}
+)
+
+// RegisterTextBytes registers the byte slice type t, e.g. a []byte holding
+// XML or YAML, to print its values as their text instead of the bytes, like
+// json.RawMessage.
+func RegisterTextBytes(t reflect.Type) {
+	textBytesMutex.Lock()
+	defer textBytesMutex.Unlock()
+	textBytes[t] = true
+}
+
+// textBytesString returns the content of v of the registered byte slice
+// types. json.RawMessage is re-indented with the indent if PrettyJSON is set.
+func (s *dumpState) textBytesString(v reflect.Value, indent string) (string, bool) {
+	if v.Kind() != reflect.Slice || v.IsNil() || v.Type().Elem().Kind() != reflect.Uint8 {
+		return "", false
+	}
+	textBytesMutex.RLock()
+	ok := textBytes[v.Type()]
+	textBytesMutex.RUnlock()
+	if !ok {
+		return "", false
+	}
+	b := v.Bytes()
+	if s.PrettyJSON && v.Type() == rawMessageType {
+		var buf bytes.Buffer
+		if json.Indent(&buf, b, indent, "  ") == nil {
+			b = buf.Bytes()
+		}
+	}
+	return string(b), true
+}
@@ -0,0 +1,47 @@
+package gdump
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type rawXML []byte
+
+type rawEnvelope struct {
+	ID   int
+	Body json.RawMessage
+	Doc  rawXML
+	Raw  []byte
+}
+
+// TestTextBytes prints json.RawMessage and the registered byte slice types
+// as their text, and re-indents only the valid JSON in the line of the
+// field with PrettyJSON.
+func TestTextBytes(t *testing.T) {
+	RegisterTextBytes(reflect.TypeOf(rawXML(nil)))
+	raw := reflect.TypeOf(json.RawMessage(nil)).String()
+	v := rawEnvelope{1, json.RawMessage(`{"a":[1,2],"b":"x"}`), rawXML("<a/>"), []byte("hi")}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"text", v, Options{Depth: 3},
+			"gdump.rawEnvelope{\n• ID:int{1}\n• Body:" + raw + `{{"a":[1,2],"b":"x"}}` + "\n• Doc:gdump.rawXML{<a/>}\n• Raw:[]uint8{\"hi\"}}"},
+		{"pretty", v, Options{Depth: 3, PrettyJSON: true},
+			"gdump.rawEnvelope{\n• ID:int{1}\n• Body:" + raw + "{{\n•   \"a\": [\n•     1,\n•     2\n•   ],\n•   \"b\": \"x\"\n• }}\n• Doc:gdump.rawXML{<a/>}\n• Raw:[]uint8{\"hi\"}}"},
+		{"invalid", json.RawMessage(`{bad`), Options{Depth: 3, PrettyJSON: true}, raw + "{{bad}"},
+		{"nil", rawEnvelope{Body: json.RawMessage(`1`)}, Options{Depth: 3}, "gdump.rawEnvelope{\n• ID:int{0}\n• Body:" + raw + "{1}\n• Doc:gdump.rawXML{nil}\n• Raw:[]uint8{nil}}"},
+		{"json", v, Options{Depth: 3, Format: FormatJSON},
+			"{\n  \"ID\": 1,\n  \"Body\": \"{\\\"a\\\":[1,2],\\\"b\\\":\\\"x\\\"}\",\n  \"Doc\": \"\\u003ca/\\u003e\",\n  \"Raw\": \"hi\"\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactPattern - the pattern of the names of the secrets, e.g.
+// Password, Token, Secret and APIKey, set to Options.RedactPattern by
+// RedactSecrets
+var DefaultRedactPattern = regexp.MustCompile(`(?i)password|passwd|token|secret|api_?key`)
+
+// isRedactedName returns true if the struct field or string map key named
+// name is redacted by Options.RedactFields or Options.RedactPattern.
+func (s *dumpState) isRedactedName(name string) bool {
+	for _, f := range s.RedactFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return s.RedactPattern != nil && s.RedactPattern.MatchString(name)
+}
+
+// redacted returns the redacted value v printed in its dynamic type.
+func (s *dumpState) redacted(v reflect.Value, depth int) string {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	s.setNode(v, redactedValue)
+	return s.leaf(v, depth, redactedValue)
+}
+
+// keyString returns the map key k formatted like %v, except that the fields
+// of the struct keys are redacted like the fields of the structs dumped and
+// their unexported fields not of the primitive kinds are printed as T{…}
+// unless ShowUnexported is set, e.g. {1 ***REDACTED***}.
+func (s *dumpState) keyString(k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.Interface:
+		if !k.IsNil() {
+			return s.keyString(k.Elem())
+		}
+	case reflect.Array:
+		items := make([]string, k.Len())
+		for i := range items {
+			items[i] = s.keyString(k.Index(i))
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	case reflect.Struct:
+		if !s.NoMethodCalls && k.CanInterface() {
+			switch k.Interface().(type) {
+			case fmt.Formatter, fmt.Stringer, error:
+				// printed by its method as by fmt
+				return s.format(k)
+			}
+		}
+		fields := typeFields(k.Type())
+		items := make([]string, len(fields))
+		for i, f := range fields {
+			fv := k.Field(i)
+			switch {
+			case f.tag.redact || s.isRedactedName(f.ft.Name):
+				items[i] = redactedValue
+			case f.ft.PkgPath != "" && !s.ShowUnexported && !isPrimitiveKind(fv.Kind()):
+				items[i] = fmt.Sprintf("%s{…}", fv.Type())
+			default:
+				items[i] = s.keyString(fv)
+			}
+		}
+		return "{" + strings.Join(items, " ") + "}"
+	}
+	return s.format(k)
+}
@@ -0,0 +1,128 @@
+package gdump
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type redactConfig struct {
+	User     string
+	Password string
+	APIKey   string
+	Token    string
+	Secret   string `gdump:"redact"`
+	Note     string `gdump:"redact"`
+	Headers  map[string]string
+}
+
+func TestRedaction(t *testing.T) {
+	v := redactConfig{
+		User: "alice", Password: "pw1", APIKey: "key1", Token: "tok1", Secret: "s1", Note: "n1",
+		Headers: map[string]string{"Authorization": "Bearer x", "X-Auth-Token": "tok2", "Accept": "json"},
+	}
+	tests := []struct {
+		name   string
+		opts   []Option
+		hidden []string
+		shown  []string
+	}{
+		{"default", nil, []string{"pw1", "key1", "tok1", "tok2", "s1", "n1"}, []string{"alice", "Bearer x", "json"}},
+		{"names", []Option{WithRedaction(nil, "authorization", "User")}, []string{"pw1", "Bearer x", "alice"}, []string{"json"}},
+		{"pattern", []Option{WithRedaction(regexp.MustCompile(`^Acc`))}, []string{"json", "n1"}, []string{"pw1", "alice"}},
+		{"disabled", []Option{WithDefaultRedaction(false)}, []string{"s1", "n1"}, []string{"pw1", "key1", "tok1", "tok2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := New(append([]Option{WithDepth(3)}, tt.opts...)...).Sdump(v)
+			for _, h := range tt.hidden {
+				if strings.Contains(out, "{"+h+"}") {
+					t.Errorf("%s not redacted:\n%s", h, out)
+				}
+			}
+			for _, s := range tt.shown {
+				if !strings.Contains(out, "{"+s+"}") {
+					t.Errorf("%s not printed:\n%s", s, out)
+				}
+			}
+			if !strings.Contains(out, "string{***REDACTED***}") {
+				t.Errorf("no redaction marker:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestRedactionPackageLevel(t *testing.T) {
+	out := ValueDump(redactConfig{Password: "pw1"}, 2, nil)
+	if strings.Contains(out, "pw1") {
+		t.Errorf("password printed by ValueDump:\n%s", out)
+	}
+	defer func(enabled bool) { RedactSecrets = enabled }(RedactSecrets)
+	RedactSecrets = false
+	if out := ValueDump(redactConfig{Password: "pw1"}, 2, nil); !strings.Contains(out, "pw1") {
+		t.Errorf("password redacted without RedactSecrets:\n%s", out)
+	}
+}
+
+func TestRedactionFormats(t *testing.T) {
+	v := map[string]interface{}{"password": "pw1", "cfg": redactConfig{Token: "tok1"}, "secrets": map[string]string{"api_key": "key1"}}
+	for _, format := range []Format{FormatText, FormatJSON, FormatYAML, FormatGo, FormatHTML, FormatSpewCompat} {
+		out := New(WithDepth(4), WithFormat(format)).Sdump(v)
+		for _, secret := range []string{"pw1", "tok1", "key1"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("format %d prints %s:\n%s", format, secret, out)
+			}
+		}
+	}
+	if out := Graph(v); strings.Contains(out, "pw1") || strings.Contains(out, "tok1") {
+		t.Errorf("graph prints the secrets:\n%s", out)
+	}
+}
+
+type redactLogin struct {
+	ID       int
+	Password string
+}
+
+type redactKey struct {
+	ID    int
+	Token string
+}
+
+type redactAccount struct {
+	Name string
+	redactLogin
+	login  *redactLogin
+	Logins map[redactKey]int
+}
+
+func TestRedactionUnexported(t *testing.T) {
+	v := redactAccount{
+		Name:        "alice",
+		redactLogin: redactLogin{1, "pw1"},
+		login:       &redactLogin{2, "pw2"},
+		Logins:      map[redactKey]int{{3, "tok1"}: 1},
+	}
+	for _, unexported := range []bool{false, true} {
+		for _, format := range []Format{FormatText, FormatJSON, FormatYAML, FormatGo, FormatHTML, FormatSpewCompat} {
+			out := New(WithDepth(4), WithUnexported(unexported), WithFormat(format)).Sdump(v)
+			for _, secret := range []string{"pw1", "pw2", "tok1"} {
+				if strings.Contains(out, secret) {
+					t.Errorf("format %d with unexported %v prints %s:\n%s", format, unexported, secret, out)
+				}
+			}
+		}
+	}
+	out := New(WithDepth(4)).Sdump(v)
+	for _, want := range []string{"redactLogin:gdump.redactLogin{…}", "login:*gdump.redactLogin{…}", "{3 ***REDACTED***}:int{1}"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Sdump has no %s:\n%s", want, out)
+		}
+	}
+	w := v
+	w.login = &redactLogin{2, "pw3"}
+	w.Logins = map[redactKey]int{{3, "tok1"}: 2}
+	if out := New(WithDepth(4), WithUnexported(true)).Diff(v, w); strings.Contains(out, "pw") || strings.Contains(out, "tok1") {
+		t.Errorf("Diff prints the secrets:\n%s", out)
+	}
+}
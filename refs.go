@@ -0,0 +1,187 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// cycleMarker - the marker of the back-reference to an ancestor
+const cycleMarker = "↩"
+
+// sharedMarker - the marker of the back-reference to a value dumped before
+const sharedMarker = "→"
+
+// refKey - the identity of the value referenced by a pointer, map or slice
+type refKey struct {
+	ptr uintptr
+	t   reflect.Type
+	n   int // the length of the slice
+}
+
+// refKeyOf returns the identity of the value referenced by v. It returns
+// false if v is not a reference or the address of the referenced value is
+// not unique, e.g. a pointer to a zero-size value or an empty slice.
+func refKeyOf(v reflect.Value) (refKey, bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.Pointer(), t: v.Type()}, true
+	case reflect.Map:
+		if v.IsNil() {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.Pointer(), t: v.Type()}, true
+	case reflect.Slice:
+		if v.Len() == 0 || v.Type().Elem().Size() == 0 {
+			return refKey{}, false
+		}
+		return refKey{ptr: v.Pointer(), t: v.Type(), n: v.Len()}, true
+	}
+	return refKey{}, false
+}
+
+// refCounter counts the references to the values walked before the dump.
+type refCounter struct {
+	*dumpState
+	counts map[refKey]int
+	left   int // the values left to walk, or -1 if unlimited
+}
+
+// sharedRefs returns the values referenced more than once in v dumped in
+// depth at level, which are numbered as they are dumped first. The fields
+// not printed by their tags and types and the entries elided by MaxItems are
+// not followed. The walk stops at MaxNodes or MaxOutputBytes values and at
+// PerValueTimeout or the end of the context, leaving the values not reached
+// referred to by their paths.
+func (s *dumpState) sharedRefs(v reflect.Value, depth, level int) map[refKey]int {
+	c := &refCounter{dumpState: s, counts: map[refKey]int{}, left: -1}
+	for _, max := range []int{s.MaxNodes, s.MaxOutputBytes} {
+		if max > 0 && (c.left < 0 || max < c.left) {
+			c.left = max
+		}
+	}
+	c.count(v, depth, level)
+	shared := map[refKey]int{}
+	for key, n := range c.counts {
+		if n > 1 {
+			shared[key] = 0
+		}
+	}
+	return shared
+}
+
+// count counts the references to the values in v dumped in depth at level.
+// The values referenced are followed only once.
+func (c *refCounter) count(v reflect.Value, depth, level int) {
+	if depth < 0 || !v.IsValid() || c.left == 0 || c.isTimedOut() {
+		return
+	}
+	if c.left > 0 {
+		c.left--
+	}
+	if key, ok := refKeyOf(v); ok {
+		c.counts[key]++
+		if c.counts[key] > 1 {
+			return
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			c.count(v.Elem(), depth, level)
+		}
+	case reflect.Slice, reflect.Array:
+		if !hasRefs(v.Type().Elem()) {
+			break
+		}
+		indices, _ := c.itemIndices(level, v.Len())
+		for _, i := range indices {
+			if i >= 0 {
+				c.count(v.Index(i), depth-1, level+1)
+			}
+		}
+	case reflect.Map:
+		if !hasRefs(v.Type().Elem()) {
+			break
+		}
+		if c.MaxItems > 0 && v.Len() > c.MaxItems {
+			// the entries shown depend on their order.
+			entries := c.sortedEntries(v)
+			indices, _ := c.itemIndices(level, len(entries))
+			for _, i := range indices {
+				if i >= 0 {
+					c.count(entries[i].value, depth-1, level+1)
+				}
+			}
+			break
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			c.count(iter.Value(), depth-1, level+1)
+		}
+	case reflect.Struct:
+		infos := typeFields(v.Type())
+		for i, f := range infos {
+			if !f.refs || f.ft.PkgPath != "" && !c.ShowUnexported || c.fieldRule(structField{owner: v.Type(), fieldInfo: &infos[i]}).omitted {
+				continue
+			}
+			c.count(v.Field(i), depth-1, level+1)
+		}
+	}
+}
+
+// visit records the value referenced by v being dumped. If it is already
+// dumped, visit returns the back-reference to its number, e.g. ↩ #1 to the
+// ancestor dumping the value or → #2 to the value dumped before. The values
+// not numbered, i.e. not found shared by sharedRefs or dumped into the
+// trees of the documents, are referred to by their paths instead, e.g.
+// ↩ Parent or → Spec.Config.
+func (s *dumpState) visit(v reflect.Value) (string, bool) {
+	key, ok := refKeyOf(v)
+	if !ok {
+		return "", false
+	}
+	if path, ok := s.visited[key]; ok {
+		marker := sharedMarker
+		if s.ancestors[key] {
+			marker = cycleMarker
+		}
+		if n := s.shared[key]; n > 0 {
+			return fmt.Sprintf("%s #%d", marker, n), true
+		}
+		if path == "" {
+			path = "(top)"
+		}
+		return marker + " " + path, true
+	}
+	if s.visited == nil {
+		s.visited = map[refKey]string{}
+		s.ancestors = map[refKey]bool{}
+	}
+	s.visited[key] = strings.Join(s.path, ".")
+	s.ancestors[key] = true
+	if _, ok := s.shared[key]; ok {
+		s.refs++
+		s.shared[key] = s.refs
+	}
+	return "", false
+}
+
+// anchor returns the number of the value referenced by v dumped first, e.g.
+// #1, if it is referenced again.
+func (s *dumpState) anchor(v reflect.Value) string {
+	if key, ok := refKeyOf(v); ok && s.shared[key] > 0 {
+		return s.paint(ansiDim, fmt.Sprintf("#%d", s.shared[key])) + " "
+	}
+	return ""
+}
+
+// unvisit marks the value referenced by v dumped.
+func (s *dumpState) unvisit(v reflect.Value) {
+	if key, ok := refKeyOf(v); ok {
+		delete(s.ancestors, key)
+	}
+}
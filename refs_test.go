@@ -0,0 +1,156 @@
+package gdump
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type refNode struct {
+	V    int
+	Next *refNode
+	Prev *refNode
+}
+
+type refPair struct{ X, Y *refNode }
+
+func TestSharedRefs(t *testing.T) {
+	a := &refNode{V: 1}
+	b := &refNode{V: 2, Prev: a}
+	a.Next = b
+	shared := &refNode{V: 9}
+	other := &refNode{V: 8}
+	m := map[string]interface{}{}
+	m["self"] = m
+	s := []int{1, 2}
+	tests := []struct {
+		name string
+		v    interface{}
+		opts Options
+		want []string
+		not  []string
+	}{
+		{"cycle", a, Options{Depth: 5},
+			[]string{"#1 *gdump.refNode{\n• V:int{1}", "Prev:*gdump.refNode{↩ #1}"}, []string{"#2"}},
+		{"shared", refPair{shared, shared}, Options{Depth: 5},
+			[]string{"X:#1 *gdump.refNode{", "Y:*gdump.refNode{→ #1}"}, []string{"↩"}},
+		{"not shared", refPair{shared, other}, Options{Depth: 5},
+			[]string{"X:*gdump.refNode{", "Y:*gdump.refNode{"}, []string{"#", "→"}},
+		{"numbered in order", struct{ A, B, C, D *refNode }{other, shared, shared, other}, Options{Depth: 5},
+			[]string{"A:#1 *gdump.refNode{", "B:#2 *gdump.refNode{", "C:*gdump.refNode{→ #2}", "D:*gdump.refNode{→ #1}"}, nil},
+		{"map", m, Options{Depth: 5}, []string{"#1 map[string]interface {}{", "self:○map[string]interface {}{↩ #1}"}, nil},
+		{"slice", struct{ S, T []int }{s, s}, Options{Depth: 5}, []string{"S:#1 []int{", "T:[]int{→ #1}"}, nil},
+		{"addresses", refPair{shared, shared}, Options{Depth: 5, ShowPointerAddr: true},
+			[]string{"X:#1 *0x", "Y:*0x", "{→ #1}"}, nil},
+		{"beyond depth", refPair{shared, shared}, Options{Depth: 0}, nil, []string{"#1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.v, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump =\n%s\nwant it containing %q", got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("Sdump =\n%s\nwant it not containing %q", got, not)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedRefsBounded(t *testing.T) {
+	rows := make([][]int, 20000)
+	for i := range rows {
+		rows[i] = make([]int, 500)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		s    *dumpState
+		max  int
+	}{
+		{"MaxItems", &dumpState{Options: Options{MaxItems: 2}}, 3},
+		{"MaxNodes", &dumpState{Options: Options{MaxNodes: 10}}, 10},
+		{"MaxOutputBytes", &dumpState{Options: Options{MaxOutputBytes: 100}}, 100},
+		{"canceled", &dumpState{ctx: canceled}, 64},
+		{"unlimited", &dumpState{}, len(rows) + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &refCounter{dumpState: tt.s, counts: map[refKey]int{}, left: -1}
+			if tt.s.MaxNodes > 0 || tt.s.MaxOutputBytes > 0 {
+				c.left = tt.s.MaxNodes + tt.s.MaxOutputBytes
+			}
+			c.count(reflect.ValueOf(rows), 5, 0)
+			if len(c.counts) > tt.max {
+				t.Errorf("%d values walked, want at most %d", len(c.counts), tt.max)
+			}
+		})
+	}
+
+	// the value shared only through the elements elided is not numbered.
+	shared := &refNode{V: 1}
+	v := []*refNode{shared, {V: 2}, {V: 3}, shared}
+	got := Sdump(v, Options{Depth: 3, MaxItems: 2})
+	if strings.Contains(got, "#1") {
+		t.Errorf("Sdump =\n%s\nwant no numbered values", got)
+	}
+}
+
+func TestSharedRefsDocuments(t *testing.T) {
+	shared := &refNode{V: 9}
+	v := struct{ A, B *refNode }{shared, shared}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatText, "B:*gdump.refNode{→ #1}"},
+		{FormatJSON, `"B": "→ A"`},
+		{FormatYAML, `B: "→ A"`},
+		{FormatHTML, "→ A"},
+	}
+	for _, tt := range tests {
+		got := New(WithDepth(3), WithFormat(tt.format)).Sdump(v)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("format %d =\n%s\nwant it containing %q", tt.format, got, tt.want)
+		}
+		if tt.format != FormatText && strings.Contains(got, "#1") {
+			t.Errorf("format %d =\n%s\nwant no numbers not defined", tt.format, got)
+		}
+	}
+	a := &refNode{V: 1}
+	a.Next = &refNode{V: 2, Prev: a}
+	tree := BuildTree(a, Options{Depth: 5})
+	refs := tree.Find(func(n *DumpNode) bool { return strings.HasPrefix(n.Value, cycleMarker) })
+	if len(refs) != 1 || refs[0].Value != "↩ (top)" {
+		t.Errorf("BuildTree refers back by %d nodes, want one by ↩ (top)", len(refs))
+	}
+}
+
+type addrNode struct {
+	A, B *int
+	N    *int
+	Self *addrNode
+}
+
+// TestShowPointerAddr prints the addresses of the non-nil pointers, the
+// same for the shared ones and the back-references.
+func TestShowPointerAddr(t *testing.T) {
+	x := 1
+	v := &addrNode{A: &x, B: &x}
+	v.Self = v
+	want := fmt.Sprintf("#1 *%p gdump.addrNode{\n• A:#2 *%p int{&1}\n• B:*%p int{→ #2}\n• N:*int{nil}\n• Self:*%p gdump.addrNode{↩ #1}}", v, &x, &x, v)
+	if got := Sdump(v, Options{Depth: 3, ShowPointerAddr: true}); got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	want = "#1 *gdump.addrNode{\n• A:#2 *int{&1}\n• B:*int{→ #2}\n• N:*int{nil}\n• Self:*gdump.addrNode{↩ #1}}"
+	if got := Sdump(v, Options{Depth: 3}); got != want {
+		t.Errorf("Sdump = %q without ShowPointerAddr, want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Renderer - a presentation of a dump set by WithRenderer to add an output
+// format, e.g. logfmt or CSV, without changing the walker. A Renderer is
+// not called by the walker while it dumps: the values are dumped into the
+// tree of DumpNode first like BuildTree, with the depth, the excluded fields
+// and the other options applied, and then the nodes are passed to the
+// Renderer in the depth-first order:
+//
+//   - a struct by BeginStruct, Field and its value for each field, EndStruct
+//   - a slice or array by BeginSlice, Element and its value, EndSlice
+//   - a map by BeginMap, Entry and its value for each entry, EndMap
+//   - any other value, a nil, or a value beyond the depth by Scalar
+//
+// The empty structs, slices and maps are passed by their Begin and End
+// calls without children. String returns the output rendered. The built-in
+// formats are not rendered by Renderers.
+type Renderer interface {
+	BeginStruct(n *DumpNode)
+	Field(name string)
+	EndStruct(n *DumpNode)
+	BeginSlice(n *DumpNode)
+	Element(i int)
+	EndSlice(n *DumpNode)
+	BeginMap(n *DumpNode)
+	Entry(key string)
+	EndMap(n *DumpNode)
+	Scalar(n *DumpNode)
+	String() string
+}
+
+// renderedString returns value dumped into a tree and rendered by r.
+func (s *dumpState) renderedString(value interface{}, r Renderer) string {
+	Render(s.walkTree(value), r)
+	return r.String()
+}
+
+// Render passes the nodes of the tree n built by BuildTree to r.
+func Render(n *DumpNode, r Renderer) {
+	switch {
+	case isDocumentLeaf(n):
+		r.Scalar(n)
+	case isDocumentList(n):
+		r.BeginSlice(n)
+		for i, c := range n.Children {
+			r.Element(i)
+			Render(c, r)
+		}
+		r.EndSlice(n)
+	case n.base == reflect.Map:
+		r.BeginMap(n)
+		for _, c := range n.Children {
+			r.Entry(c.Name)
+			Render(c, r)
+		}
+		r.EndMap(n)
+	default:
+		r.BeginStruct(n)
+		for _, c := range n.Children {
+			r.Field(c.Name)
+			Render(c, r)
+		}
+		r.EndStruct(n)
+	}
+}
+
+// DefaultRenderer - a Renderer printing the nodes in the nested type{value}
+// layout, e.g. T{• A:int{1}}, to be used as the base of the other renderers.
+// It prints only what the nodes record: the pointers and interfaces by their
+// static types, the values referenced again by their paths, and no tables,
+// compact slices, aligned keys or colors.
+type DefaultRenderer struct {
+	// IndentUnit - the indentation added to each nested level ("• " if empty)
+	IndentUnit string
+
+	b     strings.Builder
+	level int
+}
+
+// NewDefaultRenderer returns a new DefaultRenderer, e.g. to be set by
+// WithRenderer(NewDefaultRenderer).
+func NewDefaultRenderer() Renderer {
+	return &DefaultRenderer{}
+}
+
+func (r *DefaultRenderer) indent() string {
+	unit := r.IndentUnit
+	if unit == "" {
+		unit = "• "
+	}
+	return "\n" + strings.Repeat(unit, r.level)
+}
+
+func (r *DefaultRenderer) begin(n *DumpNode) {
+	r.b.WriteString(n.Type + "{")
+	r.level++
+}
+
+func (r *DefaultRenderer) end(*DumpNode) {
+	r.level--
+	r.b.WriteString("}")
+}
+
+// BeginStruct starts printing the struct n.
+func (r *DefaultRenderer) BeginStruct(n *DumpNode) { r.begin(n) }
+
+// Field starts printing the struct field name.
+func (r *DefaultRenderer) Field(name string) { r.b.WriteString(r.indent() + name + ":") }
+
+// EndStruct ends printing the struct n.
+func (r *DefaultRenderer) EndStruct(n *DumpNode) { r.end(n) }
+
+// BeginSlice starts printing the slice or array n.
+func (r *DefaultRenderer) BeginSlice(n *DumpNode) { r.begin(n) }
+
+// Element starts printing the i-th element.
+func (r *DefaultRenderer) Element(i int) { r.b.WriteString(r.indent()) }
+
+// EndSlice ends printing the slice or array n.
+func (r *DefaultRenderer) EndSlice(n *DumpNode) { r.end(n) }
+
+// BeginMap starts printing the map n.
+func (r *DefaultRenderer) BeginMap(n *DumpNode) { r.begin(n) }
+
+// Entry starts printing the map entry of key.
+func (r *DefaultRenderer) Entry(key string) { r.b.WriteString(r.indent() + key + ":") }
+
+// EndMap ends printing the map n.
+func (r *DefaultRenderer) EndMap(n *DumpNode) { r.end(n) }
+
+// Scalar prints the leaf value n.
+func (r *DefaultRenderer) Scalar(n *DumpNode) {
+	if n.Type == "" {
+		r.b.WriteString("nil")
+		return
+	}
+	r.b.WriteString(n.Type + "{" + n.Value + "}")
+}
+
+// String returns the output printed.
+func (r *DefaultRenderer) String() string { return r.b.String() }
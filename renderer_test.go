@@ -0,0 +1,86 @@
+package gdump
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// logfmtRenderer renders the leaves as path=value pairs.
+type logfmtRenderer struct {
+	path  []string
+	pairs []string
+}
+
+func (r *logfmtRenderer) BeginStruct(*DumpNode) {}
+func (r *logfmtRenderer) Field(name string)     { r.path = append(r.path, name) }
+func (r *logfmtRenderer) EndStruct(*DumpNode)   { r.pop() }
+func (r *logfmtRenderer) BeginSlice(*DumpNode)  {}
+func (r *logfmtRenderer) Element(i int)         { r.path = append(r.path, strconv.Itoa(i)) }
+func (r *logfmtRenderer) EndSlice(*DumpNode)    { r.pop() }
+func (r *logfmtRenderer) BeginMap(*DumpNode)    {}
+func (r *logfmtRenderer) Entry(key string)      { r.path = append(r.path, key) }
+func (r *logfmtRenderer) EndMap(*DumpNode)      { r.pop() }
+func (r *logfmtRenderer) String() string        { return strings.Join(r.pairs, " ") }
+
+func (r *logfmtRenderer) Scalar(n *DumpNode) {
+	r.pairs = append(r.pairs, strings.Join(r.path, ".")+"="+n.Value)
+	r.pop()
+}
+
+// pop leaves the child ended, if any.
+func (r *logfmtRenderer) pop() {
+	if len(r.path) > 0 {
+		r.path = r.path[:len(r.path)-1]
+	}
+}
+
+type renderUser struct {
+	Name  string
+	Tags  []string
+	Attrs map[string]int
+	Empty struct{}
+}
+
+func TestRenderer(t *testing.T) {
+	v := renderUser{Name: "alice", Tags: []string{"a", "b"}, Attrs: map[string]int{"x": 1}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"logfmt", []Option{WithRenderer(func() Renderer { return &logfmtRenderer{} })},
+			"Name=alice Tags.0=a Tags.1=b Attrs.x=1 Empty={}"},
+		{"default", []Option{WithRenderer(NewDefaultRenderer)},
+			"gdump.renderUser{\n• Name:string{alice}\n• Tags:[]string{\n• • string{a}\n• • string{b}}\n" +
+				"• Attrs:map[string]int{\n• • x:int{1}}\n• Empty:struct {}{{}}}"},
+		{"excluded", []Option{WithRenderer(NewDefaultRenderer), WithExcludedFields("Tags", "Attrs", "Empty")},
+			"gdump.renderUser{\n• Name:string{alice}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(append([]Option{WithDepth(3)}, tt.opts...)...).Sdump(v)
+			if got = strings.TrimSuffix(got, "\n"); got != tt.want {
+				t.Errorf("Sdump =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRendererLayout(t *testing.T) {
+	type inner struct{ N int }
+	type outer struct {
+		A string
+		B []inner
+		C map[string]bool
+		P *int
+		I interface{}
+	}
+	n := 3
+	v := outer{A: "a", B: []inner{{1}, {2}}, C: map[string]bool{"k": true}, P: &n, I: 5}
+	want := "gdump.outer{\n• A:string{a}\n• B:[]gdump.inner{\n• • gdump.inner{\n• • • N:int{1}}\n• • gdump.inner{\n• • • N:int{2}}}\n" +
+		"• C:map[string]bool{\n• • k:bool{true}}\n• P:*int{3}\n• I:interface {}{5}}"
+	if got := New(WithDepth(4), WithRenderer(NewDefaultRenderer)).Sdump(v); strings.TrimSuffix(got, "\n") != want {
+		t.Errorf("DefaultRenderer =\n%s\nwant\n%s", got, want)
+	}
+}
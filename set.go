@@ -0,0 +1,30 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isSetType returns true if t is a map type whose values are empty structs,
+// e.g. map[string]struct{}, i.e. a set of the keys.
+func isSetType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
+
+// setString returns the keys of the set v printed in the order of the
+// entries, e.g. set[string]{a b c}.
+func (s *dumpState) setString(v reflect.Value, entries []mapEntry, level int) string {
+	indices, elided := s.itemIndices(level, len(entries))
+	items := make([]string, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 {
+			items = append(items, fmt.Sprintf("…(+%d more)", elided))
+			continue
+		}
+		items = append(items, s.keyString(entries[i].key))
+	}
+	value := strings.Join(items, " ")
+	s.setNode(v, value)
+	return fmt.Sprintf("set[%s]{%s}", v.Type().Key(), value)
+}
@@ -0,0 +1,38 @@
+package gdump
+
+import "testing"
+
+type setNames map[string]struct{}
+
+// TestRecognizeSets prints the maps of the empty struct values as the sorted
+// keys, elided by MaxItems, and leaves the other maps.
+func TestRecognizeSets(t *testing.T) {
+	v := struct {
+		S map[string]struct{}
+		N setNames
+		I map[int]struct{}
+		M map[string]bool
+	}{map[string]struct{}{"c": {}, "a": {}, "b": {}}, setNames{}, map[int]struct{}{10: {}, 2: {}}, map[string]bool{"a": true}}
+	const top = "struct { S map[string]struct {}; N gdump.setNames; I map[int]struct {}; M map[string]bool }{\n"
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"sets", v, Options{Depth: 3, RecognizeSets: true},
+			top + "• S:set[string]{a b c}\n• N:set[string]{}\n• I:set[int]{2 10}\n• M:map[string]bool{\n• • a:bool{true}}}"},
+		{"max items", map[int]struct{}{1: {}, 2: {}, 3: {}, 4: {}}, Options{Depth: 3, RecognizeSets: true, MaxItems: 2}, "set[int]{1 2 …(+2 more)}"},
+		{"nil", map[string]struct{}(nil), Options{Depth: 3, RecognizeSets: true}, "map[string]struct {}{nil}"},
+		{"maps", v, Options{Depth: 3},
+			top + "• S:map[string]struct {}{\n• • a:struct {}{{}}\n• • b:struct {}{{}}\n• • c:struct {}{{}}}\n• N:gdump.setNames{}\n" +
+				"• I:map[int]struct {}{\n• • 2:struct {}{{}}\n• • 10:struct {}{{}}}\n• M:map[string]bool{\n• • a:bool{true}}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
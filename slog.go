@@ -0,0 +1,87 @@
+package gdump
+
+import (
+	"log/slog"
+	"reflect"
+	"strconv"
+)
+
+// slogValue - the value dumped when logged by log/slog
+type slogValue struct {
+	value interface{}
+	opts  Options
+}
+
+// Slog returns the slog.LogValuer of value dumped in depth when logged, e.g.
+// slog.Info("state", "s", gdump.Slog(s, 3)). The structs and maps are the
+// groups of their fields and entries, and the slices are the groups keyed
+// by their indices, so that the JSON handlers print them nested.
+func Slog(value interface{}, depth int) slog.LogValuer {
+	return slogValue{value: value, opts: globalOptions(depth)}
+}
+
+// Slog returns the slog.LogValuer of value dumped with the options of d.
+func (d *Dumper) Slog(value interface{}) slog.LogValuer {
+	return slogValue{value: value, opts: d.optionsFor(nil)}
+}
+
+// LogValue implements slog.LogValuer.
+func (v slogValue) LogValue() slog.Value {
+	ds := &dumpState{Options: v.opts}
+	ds.startTimer()
+	return ds.slogNode(ds.documentTree(v.value))
+}
+
+// slogNode returns the node n as a slog value.
+func (s *dumpState) slogNode(n *DumpNode) slog.Value {
+	if isDocumentLeaf(n) {
+		return slogLeaf(n)
+	}
+	var attrs []slog.Attr
+	if isDocumentList(n) {
+		attrs = make([]slog.Attr, len(n.Children))
+		for i, c := range n.Children {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: s.slogNode(c)}
+		}
+		return slog.GroupValue(attrs...)
+	}
+	keys, values := s.documentEntries(n)
+	attrs = make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		attrs[i] = slog.Attr{Key: key, Value: s.slogNode(values[i])}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// slogLeaf returns the leaf node n as a slog value. The nils are nil any
+// values, the numbers and booleans are typed as they are, and the others
+// are strings.
+func slogLeaf(n *DumpNode) slog.Value {
+	switch n.base {
+	case reflect.Invalid, reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Func, reflect.Chan:
+		if n.Value == "nil" || n.Value == "" {
+			return slog.AnyValue(nil)
+		}
+		if str, err := strconv.Unquote(n.Value); err == nil {
+			// the bytes printed as a quoted string
+			return slog.StringValue(str)
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(n.Value); err == nil {
+			return slog.BoolValue(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(n.Value, 10, 64); err == nil {
+			return slog.Int64Value(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(n.Value, 10, 64); err == nil {
+			return slog.Uint64Value(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(n.Value, 64); err == nil {
+			return slog.Float64Value(f)
+		}
+	}
+	return slog.StringValue(n.Value)
+}
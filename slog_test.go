@@ -0,0 +1,48 @@
+package gdump
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+type slogRecord struct {
+	Name  string
+	Port  uint16
+	Ratio float64
+	On    bool
+	Tags  []string
+	Env   map[string]string
+	Next  *slogRecord
+	Key   []byte
+}
+
+// TestSlog logs the structs, maps and slices as the nested groups of the
+// typed leaves by the JSON and the text handlers.
+func TestSlog(t *testing.T) {
+	v := slogRecord{Name: "a", Port: 80, Ratio: 0.5, On: true, Tags: []string{"x", "y"}, Env: map[string]string{"K": "V"}, Key: []byte("k")}
+	// the time, the level and the message are left out
+	onlyValue := func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key != "v" {
+			return slog.Attr{}
+		}
+		return a
+	}
+	var b bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{ReplaceAttr: onlyValue}))
+	logger.Info("", "v", Slog(v, 3))
+	want := `{"v":{"Name":"a","Port":80,"Ratio":0.5,"On":true,"Tags":{"0":"x","1":"y"},"Env":{"K":"V"},"Next":null,"Key":"k"}}` + "\n"
+	if b.String() != want {
+		t.Errorf("JSON log = %s, want %s", b.String(), want)
+	}
+
+	b.Reset()
+	logger = slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{ReplaceAttr: onlyValue}))
+	logger.Info("", "v", New(WithDepth(1), WithExcludedFields("Env", "Next", "Key")).Slog(v))
+	if want := "v.Name=a v.Port=80 v.Ratio=0.5 v.On=true v.Tags=\"len:2 …\"\n"; b.String() != want {
+		t.Errorf("text log = %q, want %q", b.String(), want)
+	}
+	if got := Slog(nil, 1).LogValue(); got.Any() != nil {
+		t.Errorf("LogValue = %v, want nil", got)
+	}
+}
@@ -0,0 +1,93 @@
+package gdump
+
+import "reflect"
+
+// snapshot returns a deep copy of value made by reflection, so that the
+// dump is not affected by the concurrent updates of value after the copy.
+// The copy costs the allocations of the whole value and is not atomic
+// itself, so it only shortens the time value is read without a lock.
+// The unexported fields, channels and functions are shared with value,
+// i.e. copied shallowly, since they cannot be set by reflection.
+func snapshot(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(value), map[refKey]reflect.Value{}).Interface()
+}
+
+// deepCopy returns a deep copy of v. seen holds the copies of the pointers,
+// maps and slices already copied by their identities to keep the shared and
+// cyclic references. The identities have the types, since a pointer to a
+// struct and a pointer to its first field have the same address.
+func deepCopy(v reflect.Value, seen map[refKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key, ref := refKeyOf(v)
+		if c, ok := seen[key]; ref && ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		if ref {
+			seen[key] = c
+		}
+		c.Elem().Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i), seen))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key, ref := refKeyOf(v)
+		if c, ok := seen[key]; ref && ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		if ref {
+			seen[key] = c
+		}
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key, _ := refKeyOf(v)
+		if c, ok := seen[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		seen[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type snapNode struct {
+	Name string
+	Next *snapNode
+}
+
+type snapFirst struct {
+	Inner snapNode
+	P     *snapNode
+}
+
+func TestSnapshot(t *testing.T) {
+	cyclic := []interface{}{1, nil}
+	cyclic[1] = cyclic
+	ring := &snapNode{Name: "a"}
+	ring.Next = &snapNode{Name: "b", Next: ring}
+	first := &snapFirst{Inner: snapNode{Name: "inner"}}
+	first.P = &first.Inner
+	m := map[string]interface{}{"k": 1}
+	m["self"] = m
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"cyclic slice", cyclic, "↩"},
+		{"ring", ring, "Name:string{b}"},
+		{"pointer to first field", first, "Name:string{inner}"},
+		{"cyclic map", m, "↩"},
+		{"nil", nil, "nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Sdump(tt.value, Options{Depth: 6, SnapshotFirst: true})
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("%q not in\n%s", tt.want, out)
+			}
+			if want := Sdump(tt.value, Options{Depth: 6}); out != want {
+				t.Errorf("snapshot dumped\n%s\nwant\n%s", out, want)
+			}
+		})
+	}
+}
+
+func TestSnapshotShared(t *testing.T) {
+	first := &snapFirst{Inner: snapNode{Name: "inner"}}
+	first.P = &first.Inner
+	c := snapshot(first).(*snapFirst)
+	if c == first || c.P == &first.Inner {
+		t.Fatal("not copied")
+	}
+	if c.P.Name != "inner" {
+		t.Errorf("the pointer to the first field is copied as %+v", c.P)
+	}
+}
+
+// TestSnapshotConcurrent checks the copy is dumped while the value is
+// updated. Run with -race.
+func TestSnapshotConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	value := &snapNode{Name: "a", Next: &snapNode{Name: "b"}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			mu.Lock()
+			value.Next = &snapNode{Name: strings.Repeat("x", i%10)}
+			mu.Unlock()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		c := snapshot(value)
+		mu.Unlock()
+		if out := Sdump(c, Options{Depth: 4}); !strings.Contains(out, "Name:string{a}") {
+			t.Fatalf("unexpected dump\n%s", out)
+		}
+	}
+	<-done
+}
+
+type snapLimits struct {
+	Items  []int
+	Labels map[string]string
+	Ch     chan int
+	Fn     func() int
+	hidden []int
+}
+
+// TestSnapshotLimits copies the exported slices and maps and shares the
+// channels, functions and unexported fields with the value.
+func TestSnapshotLimits(t *testing.T) {
+	v := &snapLimits{
+		Items:  []int{1},
+		Labels: map[string]string{"a": "x"},
+		Ch:     make(chan int),
+		Fn:     func() int { return 1 },
+		hidden: []int{2},
+	}
+	c := snapshot(v).(*snapLimits)
+	v.Items[0] = 10
+	v.Labels["a"] = "y"
+	v.hidden[0] = 20
+	if c.Items[0] != 1 || c.Labels["a"] != "x" {
+		t.Errorf("the exported fields not copied: %v %v", c.Items, c.Labels)
+	}
+	if c.Ch != v.Ch || reflect.ValueOf(c.Fn).Pointer() != reflect.ValueOf(v.Fn).Pointer() {
+		t.Error("the channel and the function are not shared")
+	}
+	if c.hidden[0] != 20 {
+		t.Errorf("the unexported field copied: %v", c.hidden)
+	}
+}
@@ -0,0 +1,151 @@
+package gdump
+
+import (
+	"reflect"
+	"sort"
+)
+
+// mapEntry - a key and its value of a map
+type mapEntry struct {
+	key, value reflect.Value
+}
+
+// sortedEntries returns the entries of the map v in the order of sortKeys.
+// The entries are read by MapRange, so that the keys not found by MapIndex,
+// e.g. NaN, are read with their values.
+func (s *dumpState) sortedEntries(v reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, v.Len())
+	keys := make([]reflect.Value, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, mapEntry{key: iter.Key(), value: iter.Value()})
+		keys = append(keys, iter.Key())
+	}
+	order := s.keyOrder(keys)
+	if order == nil {
+		return entries
+	}
+	sorted := make([]mapEntry, len(entries))
+	for i, o := range order {
+		sorted[i] = entries[o]
+	}
+	return sorted
+}
+
+// sortKeys sorts the map keys in the natural order of numbers, strings and
+// booleans, falling back to the order of their formatted strings and types
+// for the other keys. The keys of different kind families, e.g. the ints and
+// strings of an interface key, are grouped by kind. Options.MapKeyLess is used
+// instead if set. The keys are not sorted if KeepMapOrder is set.
+func (s *dumpState) sortKeys(keys []reflect.Value) {
+	order := s.keyOrder(keys)
+	if order == nil {
+		return
+	}
+	reordered := make([]reflect.Value, len(keys))
+	for i, o := range order {
+		reordered[i] = keys[o]
+	}
+	copy(keys, reordered)
+}
+
+// keyOrder returns the indices of the keys in the order of sortKeys, or nil
+// if the keys are not sorted.
+func (s *dumpState) keyOrder(keys []reflect.Value) []int {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	if s.MapKeyLess != nil {
+		sort.SliceStable(order, func(i, j int) bool { return s.MapKeyLess(keys[order[i]], keys[order[j]]) })
+		return order
+	}
+	if s.KeepMapOrder {
+		return nil
+	}
+	type sortKey struct {
+		v    reflect.Value
+		rank int
+		str  string
+		typ  string
+	}
+	sorted := make([]sortKey, len(keys))
+	for i, k := range keys {
+		for k.Kind() == reflect.Interface && !k.IsNil() {
+			k = k.Elem()
+		}
+		sorted[i] = sortKey{v: k, rank: kindRank(k), str: s.format(k), typ: k.Type().String()}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := sorted[order[i]], sorted[order[j]]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if less, ok := naturalLess(a.v, b.v); ok && less {
+			return true
+		}
+		if less, ok := naturalLess(b.v, a.v); ok && less {
+			return false
+		}
+		if a.str != b.str {
+			return a.str < b.str
+		}
+		return a.typ < b.typ
+	})
+	return order
+}
+
+// naturalLess returns whether a is less than b if both are numbers, strings
+// or booleans of the same kind family. It returns false as ok otherwise.
+func naturalLess(a, b reflect.Value) (less bool, ok bool) {
+	switch {
+	case isInt(a) && isInt(b):
+		return a.Int() < b.Int(), true
+	case isUint(a) && isUint(b):
+		return a.Uint() < b.Uint(), true
+	case isFloat(a) && isFloat(b):
+		return a.Float() < b.Float(), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return a.String() < b.String(), true
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return !a.Bool() && b.Bool(), true
+	}
+	return false, false
+}
+
+// kindRank returns the order of the kind family of v among the map keys.
+func kindRank(v reflect.Value) int {
+	switch {
+	case v.Kind() == reflect.Bool:
+		return 0
+	case isInt(v):
+		return 1
+	case isUint(v):
+		return 2
+	case isFloat(v):
+		return 3
+	case v.Kind() == reflect.String:
+		return 4
+	}
+	return 5
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
@@ -0,0 +1,106 @@
+package gdump
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNaNMapKeys(t *testing.T) {
+	m := map[float64]int{math.NaN(): 1, 2: 2, math.NaN(): 3}
+	tests := []struct {
+		name string
+		dump func() string
+	}{
+		{"Sdump", func() string { return Sdump(m, globalOptions(2)) }},
+		{"KeepMapOrder", func() string { return Sdump(m, Options{Depth: 2, KeepMapOrder: true}) }},
+		{"ValueDump", func() string { return ValueDump(m, 2, nil) }},
+		{"Diff", func() string { return ValueDiff(m, map[float64]int{math.NaN(): 1}, 2) }},
+		{"Slog", func() string { return Slog(m, 2).LogValue().String() }},
+		{"DumpBoth", func() string { out, _ := DumpBoth(m, globalOptions(2)); return out }},
+		{"Walk", func() string {
+			var paths []string
+			Walk(m, globalOptions(2), func(path string, node *DumpNode) bool {
+				paths = append(paths, path+"="+node.Value)
+				return true
+			})
+			return strings.Join(paths, " ")
+		}},
+		{"FormatGo", func() string { return Sdump(m, Options{Depth: 2, Format: FormatGo}) }},
+		{"FormatSpewCompat", func() string { return Sdump(m, Options{Depth: 2, Format: FormatSpewCompat}) }},
+		{"Graph", func() string { return Graph(m) }},
+		{"DumpOrderedMap", func() string { return DumpOrderedMap([]float64{2, math.NaN()}, m, globalOptions(2)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.dump()
+			if strings.Contains(out, "invalid") || strings.Count(out, "NaN") < 1 {
+				t.Errorf("NaN keys not printed with their values:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestNaNMapKeysSorted(t *testing.T) {
+	m := map[float64]string{math.NaN(): "x", 1: "a", math.Inf(-1): "b"}
+	out := Sdump(m, Options{Depth: 2, IndentUnit: "  "})
+	want := "map[float64]string{\n  -Inf:string{b}\n  1:string{a}\n  NaN:string{x}}"
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestMixedMapKeysSorted(t *testing.T) {
+	m := map[interface{}]int{nil: 1, 0: 2, "": 3, false: 4, 0.0: 5, uint(0): 6, math.NaN(): 7, -1: 8, "a": 9, [1]int{0}: 10}
+	want := "map[interface {}]int{\n  false:int{4}\n  -1:int{8}\n  0:int{2}\n  0:int{6}\n  0:int{5}\n  NaN:int{7}\n" +
+		"  :int{3}\n  a:int{9}\n  <nil>:int{1}\n  [0]:int{10}}"
+	for i := 0; i < 20; i++ {
+		if got := Sdump(m, Options{Depth: 2, IndentUnit: "  "}); got != want {
+			t.Fatalf("run %d:\n%s\nwant\n%s", i, got, want)
+		}
+	}
+}
+
+func TestSortMapKeysDisabled(t *testing.T) {
+	defer func(enabled bool) { SortMapKeys = enabled }(SortMapKeys)
+	SortMapKeys = false
+	m := map[float64]int{math.NaN(): 1, 1: 2, math.NaN(): 3}
+	out := ValueDump(m, 2, nil)
+	if strings.Count(out, "NaN:") != 2 || !strings.Contains(out, "1:int{2}") {
+		t.Errorf("entries not printed in the map order:\n%s", out)
+	}
+}
+
+type priorityKey struct {
+	Name string
+	Prio int
+}
+
+// TestMapKeyLess orders the struct keys by the comparator in every format,
+// even if the map order is kept.
+func TestMapKeyLess(t *testing.T) {
+	m := map[priorityKey]string{{"a", 3}: "x", {"b", 1}: "y", {"c", 2}: "z"}
+	byPrio := func(a, b reflect.Value) bool { return a.FieldByName("Prio").Int() < b.FieldByName("Prio").Int() }
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default", Options{Depth: 2}, "map[gdump.priorityKey]string{\n• {a 3}:string{x}\n• {b 1}:string{y}\n• {c 2}:string{z}}"},
+		{"less", Options{Depth: 2, MapKeyLess: byPrio}, "map[gdump.priorityKey]string{\n• {b 1}:string{y}\n• {c 2}:string{z}\n• {a 3}:string{x}}"},
+		{"map order", Options{Depth: 2, MapKeyLess: byPrio, KeepMapOrder: true}, "map[gdump.priorityKey]string{\n• {b 1}:string{y}\n• {c 2}:string{z}\n• {a 3}:string{x}}"},
+		{"json", Options{Depth: 2, MapKeyLess: byPrio, Format: FormatJSON}, "{\n  \"{b 1}\": \"y\",\n  \"{c 2}\": \"z\",\n  \"{a 3}\": \"x\"\n}"},
+		{"go", Options{Depth: 2, MapKeyLess: byPrio, Format: FormatGo},
+			"map[gdump.priorityKey]string{\n\tgdump.priorityKey{\n\t\tName: \"b\",\n\t\tPrio: 1,\n\t}: \"y\",\n\tgdump.priorityKey{\n\t\tName: \"c\",\n\t\tPrio: 2,\n\t}: \"z\",\n\tgdump.priorityKey{\n\t\tName: \"a\",\n\t\tPrio: 3,\n\t}: \"x\",\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				if got := Sdump(m, tt.opts); got != tt.want {
+					t.Fatalf("run %d: Sdump = %q, want %q", i, got, tt.want)
+				}
+			}
+		})
+	}
+}
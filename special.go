@@ -0,0 +1,137 @@
+package gdump
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// specialTypes - the renderers of the types whose internals are not worth
+// dumping. The rendered value is printed in the braces after the type name.
+var specialTypes = map[reflect.Type]func(v reflect.Value) string{
+	// The state of timers and tickers is kept in the runtime and can't be
+	// read without stopping or resetting them.
+	reflect.TypeOf(time.Timer{}):   func(v reflect.Value) string { return "…" },
+	reflect.TypeOf(time.Ticker{}):  func(v reflect.Value) string { return "…" },
+	reflect.TypeOf(bufio.Reader{}): bufioReaderString,
+	reflect.TypeOf(bufio.Writer{}): bufioWriterString,
+}
+
+// stdTypes - the standard types printed in their usual text forms instead
+// of their fields or elements unless Options.RawStdTypes is set
+var stdTypes = map[reflect.Type]bool{
+	reflect.TypeOf(time.Time{}):        true,
+	reflect.TypeOf(time.Duration(0)):   true,
+	reflect.TypeOf(net.IP{}):           true,
+	reflect.TypeOf(net.IPNet{}):        true,
+	reflect.TypeOf(net.HardwareAddr{}): true,
+	reflect.TypeOf(netip.Addr{}):       true,
+	reflect.TypeOf(netip.AddrPort{}):   true,
+	reflect.TypeOf(netip.Prefix{}):     true,
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	urlType     = reflect.TypeOf(url.URL{})
+)
+
+// specialString returns the rendered value of v if v is a value of the
+// special types, the standard types, url.URL or implements context.Context.
+// The methods of the standard types, url.URL and context.Context are called
+// only if callMethods is true. The standard types and url.URL are printed as
+// their fields if raw is true, and url.URL also if expandURL is true.
+func specialString(v reflect.Value, callMethods, raw, expandURL bool) (string, bool) {
+	if render, ok := specialTypes[v.Type()]; ok {
+		return render(v), true
+	}
+	if !callMethods || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return "", false
+	}
+	if !raw && stdTypes[v.Type()] {
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			// printed as the other nil slices
+			return "", false
+		}
+		return stdString(v.Interface()), true
+	}
+	if !raw && v.Kind() == reflect.Ptr && !v.IsNil() && stdTypes[v.Type().Elem()] {
+		// not printed by the methods of the pointer
+		return "&" + stdString(v.Elem().Interface()), true
+	}
+	if v.Type() == urlType && !expandURL && !raw {
+		u := v.Interface().(url.URL)
+		return u.String(), true
+	}
+	if v.Type().Implements(contextType) {
+		return contextString(v), true
+	}
+	return "", false
+}
+
+// stdString returns the value of a standard type of stdTypes in its text
+// form, e.g. RFC 3339 of time.Time and 1m30s of time.Duration.
+func stdString(value interface{}) string {
+	switch x := value.(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case net.IPNet:
+		return x.String()
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// contextString renders the deadline, error and the values of the context v.
+// The values are read from the key and val fields of the context chain.
+func contextString(v reflect.Value) string {
+	ctx := v.Interface().(context.Context)
+	deadline := "none"
+	if d, ok := ctx.Deadline(); ok {
+		deadline = d.Format(time.RFC3339Nano)
+	}
+	var values []string
+	for i := 0; i < 64 && v.IsValid(); i++ {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			break
+		}
+		key, val := v.FieldByName("key"), v.FieldByName("val")
+		if key.IsValid() && val.IsValid() {
+			values = append(values, fmt.Sprintf("%v=%v", key, val))
+		}
+		v = v.FieldByName("Context")
+	}
+	return fmt.Sprintf("deadline: %s, err: %v, values: [%s]", deadline, ctx.Err(), strings.Join(values, " "))
+}
+
+// bufioReaderString renders the buffered state of bufio.Reader like
+// Buffered() and Size() from its fields.
+func bufioReaderString(v reflect.Value) string {
+	buf, r, w := v.FieldByName("buf"), v.FieldByName("r"), v.FieldByName("w")
+	if buf.Kind() != reflect.Slice || r.Kind() != reflect.Int || w.Kind() != reflect.Int {
+		return "…"
+	}
+	return fmt.Sprintf("buffered:%d size:%d", w.Int()-r.Int(), buf.Len())
+}
+
+// bufioWriterString renders the buffered state of bufio.Writer like
+// Buffered() and Available() from its fields.
+func bufioWriterString(v reflect.Value) string {
+	buf, n := v.FieldByName("buf"), v.FieldByName("n")
+	if buf.Kind() != reflect.Slice || n.Kind() != reflect.Int {
+		return "…"
+	}
+	return fmt.Sprintf("buffered:%d available:%d", n.Int(), int64(buf.Len())-n.Int())
+}
@@ -0,0 +1,341 @@
+// The rendering of spewState is derived from go-spew, which is distributed
+// under the following license:
+//
+// Copyright (c) 2012-2016 Dave Collins <dave@davec.name>
+//
+// Permission to use, copy, modify, and distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+// WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+// MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+// ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+// WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+// ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+// OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+
+package gdump
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// spewState renders values in the format of Sdump of davecgh/go-spew with
+// its default configuration, so that the golden files of spew can be reused.
+// The known differences are:
+//   - Options.Depth limits the depth like spew's MaxDepth, but 0 is not unlimited.
+//   - Error() and String() are called only if Options.UseStringer is set.
+//   - Map entries are printed in the sorted order of the keys like SortKeys.
+//   - C char arrays such as [N]C.char are not hex dumped.
+//   - The fields and entries left out by Sdump are not printed, and the
+//     redacted values are printed as ***REDACTED*** after their types.
+type spewState struct {
+	*dumpState
+	depth            int
+	pointers         map[uintptr]int
+	ignoreNextType   bool
+	ignoreNextIndent bool
+	w                strings.Builder
+}
+
+// spewString returns value rendered in the format of spew.Sdump.
+func (s *dumpState) spewString(value interface{}) string {
+	if value == nil {
+		return "(interface {}) <nil>\n"
+	}
+	d := &spewState{dumpState: s, pointers: map[uintptr]int{}}
+	d.dump(reflect.ValueOf(value))
+	d.w.WriteString("\n")
+	return d.w.String()
+}
+
+func (d *spewState) indent() {
+	if d.ignoreNextIndent {
+		d.ignoreNextIndent = false
+		return
+	}
+	d.w.WriteString(strings.Repeat(" ", d.depth))
+}
+
+func (d *spewState) unpack(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func (d *spewState) dumpPtr(v reflect.Value) {
+	for addr, depth := range d.pointers {
+		if depth >= d.depth {
+			delete(d.pointers, addr)
+		}
+	}
+	var chain []uintptr
+	nilFound, cycleFound := false, false
+	indirects := 0
+	ve := v
+	for ve.Kind() == reflect.Ptr {
+		if ve.IsNil() {
+			nilFound = true
+			break
+		}
+		indirects++
+		addr := ve.Pointer()
+		chain = append(chain, addr)
+		if depth, ok := d.pointers[addr]; ok && depth < d.depth {
+			cycleFound = true
+			indirects--
+			break
+		}
+		d.pointers[addr] = d.depth
+		ve = ve.Elem()
+		if ve.Kind() == reflect.Interface {
+			if ve.IsNil() {
+				nilFound = true
+				break
+			}
+			ve = ve.Elem()
+		}
+	}
+	d.w.WriteString("(" + strings.Repeat("*", indirects) + ve.Type().String() + ")")
+	if len(chain) > 0 {
+		addrs := make([]string, len(chain))
+		for i, addr := range chain {
+			addrs[i] = hexPtr(addr)
+		}
+		d.w.WriteString("(" + strings.Join(addrs, "->") + ")")
+	}
+	d.w.WriteString("(")
+	switch {
+	case nilFound:
+		d.w.WriteString("<nil>")
+	case cycleFound:
+		d.w.WriteString("<already shown>")
+	default:
+		d.ignoreNextType = true
+		d.dump(ve)
+	}
+	d.w.WriteString(")")
+}
+
+func (d *spewState) dump(v reflect.Value) {
+	kind := v.Kind()
+	if kind == reflect.Invalid {
+		d.w.WriteString("<invalid>")
+		return
+	}
+	if kind == reflect.Ptr {
+		d.indent()
+		d.dumpPtr(v)
+		return
+	}
+	if !d.ignoreNextType {
+		d.indent()
+		d.w.WriteString("(" + v.Type().String() + ") ")
+	}
+	d.ignoreNextType = false
+
+	length, capacity := 0, 0
+	switch kind {
+	case reflect.Array, reflect.Slice, reflect.Chan:
+		length, capacity = v.Len(), v.Cap()
+	case reflect.Map, reflect.String:
+		length = v.Len()
+	}
+	if length != 0 || capacity != 0 {
+		var lc []string
+		if length != 0 {
+			lc = append(lc, "len="+strconv.Itoa(length))
+		}
+		if capacity != 0 {
+			lc = append(lc, "cap="+strconv.Itoa(capacity))
+		}
+		d.w.WriteString("(" + strings.Join(lc, " ") + ") ")
+	}
+	if d.callsMethods(v) && kind != reflect.Interface {
+		if str, ok := d.methodString(v, []RendererKind{RenderError, RenderStringer}); ok {
+			d.w.WriteString(str)
+			return
+		}
+	}
+
+	switch kind {
+	case reflect.Bool:
+		d.w.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		d.w.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		d.w.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32:
+		d.w.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 32))
+	case reflect.Float64:
+		d.w.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		bits := 64
+		if kind == reflect.Complex64 {
+			bits = 32
+		}
+		c := v.Complex()
+		d.w.WriteString("(" + strconv.FormatFloat(real(c), 'g', -1, bits))
+		if imag(c) >= 0 {
+			d.w.WriteString("+")
+		}
+		d.w.WriteString(strconv.FormatFloat(imag(c), 'g', -1, bits) + "i)")
+	case reflect.Slice:
+		if v.IsNil() {
+			d.w.WriteString("<nil>")
+			break
+		}
+		fallthrough
+	case reflect.Array:
+		d.container(func() { d.dumpSlice(v) })
+	case reflect.String:
+		d.w.WriteString(strconv.Quote(v.String()))
+	case reflect.Interface:
+		if v.IsNil() {
+			d.w.WriteString("<nil>")
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			d.w.WriteString("<nil>")
+			break
+		}
+		d.container(func() {
+			var entries []mapEntry
+			for _, e := range d.sortedEntries(v) {
+				if e.key.Kind() != reflect.String || !d.isHiddenPath(e.key.String(), v.Type().Elem()) {
+					entries = append(entries, e)
+				}
+			}
+			for i, e := range entries {
+				d.dump(d.unpack(e.key))
+				d.w.WriteString(": ")
+				d.ignoreNextIndent = true
+				parent := d.enter(d.keyString(e.key))
+				if e.key.Kind() == reflect.String && d.isRedactedName(e.key.String()) {
+					d.redacted(e.value)
+				} else {
+					d.dump(d.unpack(e.value))
+				}
+				d.leave(parent)
+				d.separate(i, len(entries))
+			}
+		})
+	case reflect.Struct:
+		d.container(func() {
+			fields := d.spewFields(v)
+			for i, f := range fields {
+				d.indent()
+				d.w.WriteString(f.ft.Name + ": ")
+				d.ignoreNextIndent = true
+				parent := d.enter(f.ft.Name)
+				if f.tag.redact || d.isRedactedName(f.ft.Name) {
+					d.redacted(f.fv)
+				} else {
+					d.dump(d.unpack(f.fv))
+				}
+				d.leave(parent)
+				d.separate(i, len(fields))
+			}
+		})
+	case reflect.Uintptr:
+		d.w.WriteString(hexPtr(uintptr(v.Uint())))
+	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
+		d.w.WriteString(hexPtr(v.Pointer()))
+	default:
+		d.w.WriteString(rawString(v))
+	}
+}
+
+// spewFields returns the fields of the struct v printed, leaving out the
+// fields excluded, filtered or not on Options.IncludedPaths like Sdump.
+func (d *spewState) spewFields(v reflect.Value) []structField {
+	t := v.Type()
+	infos := typeFields(t)
+	fields := make([]structField, 0, len(infos))
+	for i := range infos {
+		f := structField{owner: t, parent: v, fieldInfo: &infos[i], fv: v.Field(i)}
+		ft, tag := f.ft, f.tag
+		name := ft.Name
+		if tag.name != "" {
+			name = tag.name
+		}
+		if tag.skip || d.isOmittedField(ft.Name) || d.isOmittedField(name) || d.isExcludedType(ft.Type) || d.isFilteredField(f) {
+			continue
+		}
+		if d.isHiddenPath(ft.Name, ft.Type) && d.isHiddenPath(name, ft.Type) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// redacted writes the type of the redacted value v and the redaction mark
+// in place of its length and value.
+func (d *spewState) redacted(v reflect.Value) {
+	d.ignoreNextIndent = false
+	v = d.unpack(v)
+	if !v.IsValid() {
+		d.w.WriteString("(interface {}) " + redactedValue)
+		return
+	}
+	d.w.WriteString("(" + v.Type().String() + ") " + redactedValue)
+}
+
+// container writes the entries written by entries in the braces, or the
+// max depth mark if the entries are deeper than Options.Depth.
+func (d *spewState) container(entries func()) {
+	d.w.WriteString("{\n")
+	d.depth++
+	if d.depth > d.Depth {
+		d.indent()
+		d.w.WriteString("<max depth reached>\n")
+	} else {
+		entries()
+	}
+	d.depth--
+	d.indent()
+	d.w.WriteString("}")
+}
+
+// separate writes the separator after the i-th entry of n entries.
+func (d *spewState) separate(i, n int) {
+	if i < n-1 {
+		d.w.WriteString(",\n")
+	} else {
+		d.w.WriteString("\n")
+	}
+}
+
+func (d *spewState) dumpSlice(v reflect.Value) {
+	n := v.Len()
+	if n > 0 && v.Type().Elem().Kind() == reflect.Uint8 {
+		buf := make([]byte, n)
+		for i := range buf {
+			buf[i] = byte(v.Index(i).Uint())
+		}
+		indent := strings.Repeat(" ", d.depth)
+		str := indent + hex.Dump(buf)
+		str = strings.ReplaceAll(str, "\n", "\n"+indent)
+		d.w.WriteString(strings.TrimRight(str, " "))
+		return
+	}
+	for i := 0; i < n; i++ {
+		d.dump(d.unpack(v.Index(i)))
+		d.separate(i, n)
+	}
+}
+
+// hexPtr returns the address p formatted like spew.
+func hexPtr(p uintptr) string {
+	if p == 0 {
+		return "<nil>"
+	}
+	return fmt.Sprintf("0x%x", p)
+}
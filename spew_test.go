@@ -0,0 +1,93 @@
+package gdump
+
+import (
+	"regexp"
+	"testing"
+)
+
+type spewItem struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Attrs map[string]int
+	Next  *spewItem
+	Raw   []byte
+	skip  bool
+}
+
+type spewSecret struct {
+	User     string
+	Password string
+	Key      string `gdump:"redact"`
+	Internal string `gdump:"-"`
+}
+
+// spewAddr matches the addresses printed by spew, which vary between runs.
+var spewAddr = regexp.MustCompile(`0x[0-9a-f]+`)
+
+func TestSpewCompat(t *testing.T) {
+	var nilItem *spewItem
+	cyclic := &spewItem{ID: 1}
+	cyclic.Next = cyclic
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  []Option
+		want  string // captured from spew.Sdump with spew.Config.SortKeys set
+	}{
+		{"nil", nil, nil, "(interface {}) <nil>\n"},
+		{"int", 42, nil, "(int) 42\n"},
+		{"string", "hi", nil, "(string) (len=2) \"hi\"\n"},
+		{"nil pointer", nilItem, nil, "(*gdump.spewItem)(<nil>)\n"},
+		{"slice", []int{1, 2}, nil, "([]int) (len=2 cap=2) {\n (int) 1,\n (int) 2\n}\n"},
+		{"map", map[string]int{"b": 2, "a": 1}, nil,
+			"(map[string]int) (len=2) {\n (string) (len=1) \"a\": (int) 1,\n (string) (len=1) \"b\": (int) 2\n}\n"},
+		{"bytes", []byte("ab"), nil,
+			"([]uint8) (len=2 cap=2) {\n 00000000  61 62                                             |ab|\n}\n"},
+		{"struct", spewItem{ID: 1, Name: "x", Tags: []string{"t"}}, nil,
+			"(gdump.spewItem) {\n" +
+				" ID: (int) 1,\n" +
+				" Name: (string) (len=1) \"x\",\n" +
+				" Tags: ([]string) (len=1 cap=1) {\n" +
+				"  (string) (len=1) \"t\"\n" +
+				" },\n" +
+				" Attrs: (map[string]int) <nil>,\n" +
+				" Next: (*gdump.spewItem)(<nil>),\n" +
+				" Raw: ([]uint8) <nil>,\n" +
+				" skip: (bool) false\n" +
+				"}\n"},
+		{"cycle", cyclic, nil,
+			"(*gdump.spewItem)(0x)({\n" +
+				" ID: (int) 1,\n" +
+				" Name: (string) \"\",\n" +
+				" Tags: ([]string) <nil>,\n" +
+				" Attrs: (map[string]int) <nil>,\n" +
+				" Next: (*gdump.spewItem)(0x)(<already shown>),\n" +
+				" Raw: ([]uint8) <nil>,\n" +
+				" skip: (bool) false\n" +
+				"})\n"},
+		{"max depth", [][]int{{1}}, []Option{WithDepth(1)},
+			"([][]int) (len=1 cap=1) {\n ([]int) (len=1 cap=1) {\n  <max depth reached>\n }\n}\n"},
+		{"excluded", spewItem{ID: 1}, []Option{WithExcludedFields("Name", "Tags", "Attrs", "Next", "Raw", "skip")},
+			"(gdump.spewItem) {\n ID: (int) 1\n}\n"},
+		{"included paths", map[string]spewItem{"a": {ID: 1}}, []Option{WithIncludedFields("a.ID")},
+			"(map[string]gdump.spewItem) (len=1) {\n (string) (len=1) \"a\": (gdump.spewItem) {\n  ID: (int) 1\n }\n}\n"},
+		{"redacted", spewSecret{User: "u", Password: "pw", Key: "k", Internal: "i"}, nil,
+			"(gdump.spewSecret) {\n" +
+				" User: (string) (len=1) \"u\",\n" +
+				" Password: (string) ***REDACTED***,\n" +
+				" Key: (string) ***REDACTED***\n" +
+				"}\n"},
+		{"redacted key", map[string]interface{}{"token": 1, "x": 2}, nil,
+			"(map[string]interface {}) (len=2) {\n (string) (len=5) \"token\": (int) ***REDACTED***,\n (string) (len=1) \"x\": (int) 2\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithFormat(FormatSpewCompat), WithDepth(5)}, tt.opts...)
+			got := spewAddr.ReplaceAllString(New(opts...).Sdump(tt.value), "0x")
+			if got != tt.want {
+				t.Errorf("Sdump =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
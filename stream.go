@@ -0,0 +1,105 @@
+package gdump
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// countingWriter - an io.StringWriter counting the bytes written to w
+type countingWriter struct {
+	w io.StringWriter
+	n int
+}
+
+func (c *countingWriter) WriteString(str string) (int, error) {
+	n, err := c.w.WriteString(str)
+	c.n += n
+	return n, err
+}
+
+// streamWriter - the buffered writer of a dump streamed to w, which stops
+// the dump at the first write error or at Options.MaxOutputBytes
+type streamWriter struct {
+	s     *dumpState
+	w     *bufio.Writer
+	n     int
+	err   error
+	limit int  // Options.MaxOutputBytes
+	cut   bool // true if the dump is cut at limit
+}
+
+func (sw *streamWriter) WriteString(str string) (int, error) {
+	if sw.err != nil || sw.cut {
+		return 0, sw.err
+	}
+	if sw.limit > 0 && sw.n+len(str) > sw.limit {
+		// cut like clip; a rune is never split between the writes.
+		cut := sw.limit - sw.n
+		for cut > 0 && !utf8.RuneStart(str[cut]) {
+			cut--
+		}
+		str = str[:cut] + fmt.Sprintf("\n…(truncated at %d bytes)", sw.limit)
+		sw.cut = true
+		sw.s.timedOut = true
+	}
+	n, err := sw.w.WriteString(str)
+	sw.n += n
+	if err != nil {
+		sw.err = err
+		// the rest of the dump is skipped like on a timeout.
+		sw.s.timedOut = true
+	}
+	return n, err
+}
+
+// streams returns true if the dump of o is written to a writer while it is
+// dumped, i.e. in FormatText without the additions made to the whole dump
+// by Bullet, IncludeLegend and MarkdownFence.
+func (o Options) streams() bool {
+	return o.Format == FormatText && o.NewRenderer == nil && o.Bullet == "" &&
+		!o.IncludeLegend && !o.MarkdownFence
+}
+
+// dumpTo writes the string representation of value dumped with o to w until
+// ctx is done. The dump is streamed to w if o.streams(), and written at once
+// otherwise. It returns the number of bytes written and any write error.
+func (o Options) dumpTo(ctx context.Context, w io.Writer, value interface{}) (int, error) {
+	if !o.streams() {
+		return io.WriteString(w, o.dump(ctx, value))
+	}
+	if o.SnapshotFirst {
+		value = snapshot(value)
+	}
+	ds := &dumpState{Options: o}
+	ds.startTimer()
+	if ctx.Done() != nil {
+		ds.ctx = ctx
+	}
+	ds.methods = contextMethods(ctx)
+	return ds.writeTo(w, reflect.ValueOf(value))
+}
+
+// writeTo streams v dumped in Depth to w. The dump is cut at
+// MaxOutputBytes with the mark of the bytes cut off like clip. It returns
+// the number of bytes written and the first write error.
+func (s *dumpState) writeTo(w io.Writer, v reflect.Value) (int, error) {
+	sw := &streamWriter{s: s, w: bufio.NewWriter(w), limit: s.MaxOutputBytes}
+	s.writeValue(sw, v, s.Depth, 0, 0, "", false, false)
+	if s.NewlineAtEnd {
+		sw.cut = false
+		sw.limit = 0
+		sw.WriteString("\n")
+	}
+	if sw.err != nil {
+		return sw.n - sw.w.Buffered(), sw.err
+	}
+	n := sw.n
+	if err := sw.w.Flush(); err != nil {
+		return n - sw.w.Buffered(), err
+	}
+	return n, nil
+}
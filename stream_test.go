@@ -0,0 +1,119 @@
+package gdump
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type streamItem struct {
+	ID   int
+	Name string
+	Tags map[string]int
+	Next *streamItem
+}
+
+func TestDumpToMatchesSdump(t *testing.T) {
+	v := []streamItem{
+		{ID: 1, Name: "a", Tags: map[string]int{"x": 1, "y": 2}},
+		{ID: 2, Name: "b\nc", Next: &streamItem{ID: 3}},
+	}
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"depth", []Option{WithDepth(1)}},
+		{"max items", []Option{WithMaxElements(1)}},
+		{"excluded", []Option{WithExcludedFields("Name")}},
+		{"sorted", []Option{WithSortMapKeys(true)}},
+		{"bullets", []Option{WithBullet("- ")}},
+		{"max output bytes", []Option{WithMaxOutputBytes(40)}},
+		{"json", []Option{WithFormat(FormatJSON)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(append([]Option{WithDepth(4)}, tt.opts...)...)
+			var b bytes.Buffer
+			n, err := d.DumpTo(&b, v)
+			if err != nil || n != b.Len() {
+				t.Fatalf("DumpTo = %d, %v, want %d, nil", n, err, b.Len())
+			}
+			if want := d.Sdump(v); b.String() != want {
+				t.Errorf("DumpTo =\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
+
+// progressStringer reports the bytes written to w when it is dumped.
+type progressStringer struct{ w *bytes.Buffer }
+
+func (p progressStringer) String() string {
+	return "written:" + strconv.Itoa(p.w.Len())
+}
+
+func TestDumpToStreams(t *testing.T) {
+	var b bytes.Buffer
+	v := make([]interface{}, 0, 1001)
+	for i := 0; i < 1000; i++ {
+		v = append(v, strings.Repeat("x", 64))
+	}
+	v = append(v, progressStringer{&b})
+	if _, err := New(WithDepth(2), WithStringer(true)).DumpTo(&b, v); err != nil {
+		t.Fatal(err)
+	}
+	// the last element is dumped after the first ones are written.
+	if strings.Contains(b.String(), "written:0") {
+		t.Errorf("DumpTo wrote nothing before the end of the dump")
+	}
+}
+
+// failingWriter fails the writes after n bytes.
+type failingWriter struct{ n int }
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.n {
+		n := f.n
+		f.n = 0
+		return n, errors.New("disk full")
+	}
+	f.n -= len(p)
+	return len(p), nil
+}
+
+// countingStringer counts the values dumped.
+type countingStringer struct{ calls *int }
+
+func (c countingStringer) String() string {
+	*c.calls++
+	return strings.Repeat("y", 100)
+}
+
+func TestDumpToWriteError(t *testing.T) {
+	calls := 0
+	v := make([]countingStringer, 10000)
+	for i := range v {
+		v[i] = countingStringer{&calls}
+	}
+	n, err := New(WithDepth(2), WithStringer(true)).DumpTo(&failingWriter{n: 100}, v)
+	if err == nil || n != 100 {
+		t.Errorf("DumpTo = %d, %v, want 100, disk full", n, err)
+	}
+	if calls >= len(v) {
+		t.Errorf("DumpTo dumped all %d values after the write error", calls)
+	}
+}
+
+func TestFdumpMatchesValueDump(t *testing.T) {
+	v := streamItem{ID: 1, Tags: map[string]int{"k": 1}}
+	var b bytes.Buffer
+	if _, err := Fdump(&b, v, 3); err != nil {
+		t.Fatal(err)
+	}
+	if want := ValueDump(v, 3, nil); b.String() != want {
+		t.Errorf("Fdump =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,195 @@
+package gdump
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// RendererKind - an interface whose method renders the values implementing it
+type RendererKind int
+
+const (
+	// RenderError - error rendered by Error()
+	RenderError RendererKind = iota
+	// RenderStringer - fmt.Stringer rendered by String()
+	RenderStringer
+	// RenderTextMarshaler - encoding.TextMarshaler rendered by MarshalText()
+	RenderTextMarshaler
+	// RenderValuer - driver.Valuer rendered by the result of Value()
+	RenderValuer
+	// RenderFormatter - fmt.Formatter rendered by Format() with %v
+	RenderFormatter
+)
+
+// DefaultRendererPriority - the order of the interfaces checked when
+// Options.RendererPriority is not set
+var DefaultRendererPriority = []RendererKind{
+	RenderError, RenderStringer, RenderTextMarshaler, RenderValuer, RenderFormatter,
+}
+
+var rendererTypes = map[RendererKind]reflect.Type{
+	RenderError:         reflect.TypeOf((*error)(nil)).Elem(),
+	RenderStringer:      reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+	RenderTextMarshaler: reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	RenderValuer:        reflect.TypeOf((*driver.Valuer)(nil)).Elem(),
+	RenderFormatter:     reflect.TypeOf((*fmt.Formatter)(nil)).Elem(),
+}
+
+// render returns the value rendered by the method of the interface kind.
+// ctx returns the context given to ContextStringer.
+func render(kind RendererKind, value interface{}, ctx func() context.Context) string {
+	switch kind {
+	case RenderError:
+		return value.(error).Error()
+	case RenderStringer:
+		if cs, ok := value.(ContextStringer); ok {
+			return cs.StringContext(ctx())
+		}
+		return value.(fmt.Stringer).String()
+	case RenderTextMarshaler:
+		text, err := value.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Sprintf("<error: %v>", err)
+		}
+		return string(text)
+	case RenderValuer:
+		dv, err := value.(driver.Valuer).Value()
+		if err != nil {
+			return fmt.Sprintf("<error: %v>", err)
+		}
+		return fmt.Sprint(dv)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ContextStringer - a fmt.Stringer printed by StringContext instead of String
+// by UseStringer, given the context of the dump calling it. The dumps made
+// by the method with the context, e.g. by DumpContext, share the guard of
+// the methods being called by the dump calling it, so that they call the
+// methods of the other values and print the receivers being called by
+// their fields.
+type ContextStringer interface {
+	fmt.Stringer
+	StringContext(ctx context.Context) string
+}
+
+// methodsKey - the context key of the methodGuard given to the methods
+type methodsKey struct{}
+
+// methodGuard - the values whose Error() or String() is being called, keyed
+// by the types and the addresses of the pointer receivers
+type methodGuard map[refKey]bool
+
+// renderFunc - the name of render, found on the stack of the dumps made by
+// the methods called
+var renderFunc = runtime.FuncForPC(reflect.ValueOf(render).Pointer()).Name()
+
+// inMethod returns true if the calling goroutine is in a method called by a
+// dump, i.e. render is on its stack.
+func inMethod() bool {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(3, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == renderFunc {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// contextMethods returns the guard given by ctx to the dumps made by a
+// ContextStringer, or nil.
+func contextMethods(ctx context.Context) methodGuard {
+	methods, _ := ctx.Value(methodsKey{}).(methodGuard)
+	return methods
+}
+
+// methodContext returns the context of the dump holding its guard given to
+// ContextStringer.
+func (s *dumpState) methodContext() context.Context {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, methodsKey{}, s.methods)
+}
+
+// enterMethod marks the method of the interface it of the receiver m being
+// called. It returns false if it is already being called by the dump or the
+// dumps calling it, i.e. the method dumps the receiver again. The values
+// are told by their addresses for the pointer receivers and by their types
+// otherwise, as the value receivers are copied for each call. A dump made
+// by a method without the context given to ContextStringer calls no
+// methods, as the receivers being called are not known to it.
+func (s *dumpState) enterMethod(m reflect.Value, it reflect.Type) (leave func(), ok bool) {
+	if s.methods == nil {
+		s.methods = methodGuard{}
+		s.inMethod = inMethod()
+	}
+	if s.inMethod {
+		return nil, false
+	}
+	key := refKey{t: getBaseType(m.Type())}
+	if m.Kind() == reflect.Ptr && !m.Type().Elem().Implements(it) {
+		key = refKey{ptr: m.Pointer(), t: m.Type()}
+	}
+	if s.methods[key] {
+		return nil, false
+	}
+	s.methods[key] = true
+	return func() { delete(s.methods, key) }, true
+}
+
+// methodString returns the value rendered by the method of the first
+// interface in kinds implemented by v or its addressable pointer.
+// A panic of the method is returned as the string. The method is not called
+// again for the same receiver while it is being called, so that a dump in
+// the method falls back to dumping the fields instead of recursing
+// infinitely. The guard is kept by the dump and shared only by the dumps
+// given the context by the methods, so the concurrent dumps do not affect
+// each other.
+func (s *dumpState) methodString(v reflect.Value, kinds []RendererKind) (str string, ok bool) {
+	if v.Kind() == reflect.Interface || !v.CanInterface() || !hasRenderer(v.Type()) {
+		return "", false
+	}
+	for _, kind := range kinds {
+		it, known := rendererTypes[kind]
+		if !known {
+			continue
+		}
+		m := v
+		if !m.Type().Implements(it) {
+			if !m.CanAddr() || !reflect.PtrTo(m.Type()).Implements(it) {
+				continue
+			}
+			m = m.Addr()
+		}
+		leave, entered := s.enterMethod(m, it)
+		if !entered {
+			return "", false
+		}
+		defer leave()
+		defer func() {
+			if r := recover(); r != nil {
+				str, ok = fmt.Sprintf("<panic: %v>", r), true
+			}
+		}()
+		return render(kind, m.Interface(), s.methodContext), true
+	}
+	return "", false
+}
@@ -0,0 +1,211 @@
+package gdump
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type selfValue struct{ N int }
+
+func (s selfValue) String() string {
+	return "self:" + Sdump(&s, Options{Depth: 2, UseStringer: true})
+}
+
+type selfPointer struct{ N int }
+
+func (s *selfPointer) String() string {
+	return "ptr:" + Sdump(s, Options{Depth: 2, UseStringer: true})
+}
+
+type stringTree struct {
+	Name     string
+	Children []*stringTree
+}
+
+func (t *stringTree) String() string {
+	return t.Name + Sdump(t.Children, Options{Depth: 3, UseStringer: true})
+}
+
+// blockingStringer blocks in String() until release is closed.
+type blockingStringer struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b blockingStringer) String() string {
+	close(b.entered)
+	<-b.release
+	return "called"
+}
+
+func TestStringerRecursion(t *testing.T) {
+	leaf := &stringTree{Name: "leaf"}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{"value receiver", selfValue{1}, []string{"self:", "N:int{1}"}},
+		{"pointer receiver", &selfPointer{2}, []string{"ptr:", "N:int{2}"}},
+		{"pointers", []*selfPointer{{3}, {4}}, []string{"N:int{3}", "N:int{4}"}},
+		{"tree", &stringTree{Name: "root", Children: []*stringTree{leaf}}, []string{"root", "leaf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Sdump(tt.value, Options{Depth: 3, UseStringer: true})
+			for _, w := range tt.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("Sdump = %s, want %q", out, w)
+				}
+			}
+		})
+	}
+}
+
+func TestStringerConcurrent(t *testing.T) {
+	a := blockingStringer{entered: make(chan struct{}), release: make(chan struct{})}
+	b := blockingStringer{entered: make(chan struct{}), release: make(chan struct{})}
+	close(b.release)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Sdump(a, Options{Depth: 1, UseStringer: true})
+	}()
+	<-a.entered
+	// the method of the same type running on the other goroutine doesn't
+	// guard this dump.
+	if out := Sdump(b, Options{Depth: 1, UseStringer: true}); !strings.Contains(out, "called") {
+		t.Errorf("Sdump = %s, want called", out)
+	}
+	close(a.release)
+	wg.Wait()
+}
+
+type contextTag struct{ Name string }
+
+func (c contextTag) String() string { return "tag:" + c.Name }
+
+// contextNode dumps itself by the context of the dump calling it.
+type contextNode struct {
+	Label contextTag
+	Next  *contextNode
+}
+
+func (n *contextNode) String() string { return "plain" }
+
+func (n *contextNode) StringContext(ctx context.Context) string {
+	var b strings.Builder
+	New(WithDepth(4), WithStringer(true)).DumpContext(ctx, &b, n)
+	return "ctx:" + b.String()
+}
+
+// plainNode dumps a Stringer in its String method without the context.
+type plainNode struct{ Label contextTag }
+
+func (n plainNode) String() string {
+	return "plain:" + Sdump(n.Label, Options{Depth: 2, UseStringer: true})
+}
+
+func TestContextStringer(t *testing.T) {
+	n := &contextNode{Label: contextTag{"a"}, Next: &contextNode{Label: contextTag{"b"}}}
+	out := Sdump(n, Options{Depth: 4, UseStringer: true})
+	// the receiver being called is printed by its fields, the others by
+	// their methods sharing the guard.
+	for _, want := range []string{"ctx:", "Label:gdump.contextTag{tag:a}", "Next:*gdump.contextNode{ctx:", "tag:b"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Sdump = %s, want %q", out, want)
+		}
+	}
+	if strings.Contains(out, "plain") {
+		t.Errorf("Sdump = %s, want StringContext called instead of String", out)
+	}
+
+	// the dump without the context doesn't know the receivers being called.
+	out = Sdump(plainNode{contextTag{"c"}}, Options{Depth: 3, UseStringer: true})
+	if !strings.Contains(out, "plain:") || !strings.Contains(out, "Name:string{c}") {
+		t.Errorf("Sdump = %s, want the Stringer printed by its fields in the method", out)
+	}
+	if out := Sdump(contextTag{"d"}, Options{Depth: 1, UseStringer: true}); !strings.Contains(out, "tag:d") {
+		t.Errorf("Sdump = %s after the dumps in the methods, want tag:d", out)
+	}
+}
+
+type queryError struct {
+	Query string
+	Err   error
+}
+
+func (e *queryError) Error() string { return "query " + e.Query + ": " + e.Err.Error() }
+
+func (e *queryError) Unwrap() error { return e.Err }
+
+type queryResult struct {
+	Rows int
+	Err  error
+	Errs []error
+}
+
+// TestUseStringerNested prints the errors in the fields and elements by
+// Error() instead of the fields of the errors they wrap.
+func TestUseStringerNested(t *testing.T) {
+	err := &queryError{"select", fmt.Errorf("conn 2: %w", errors.New("timeout"))}
+	v := queryResult{Rows: 1, Err: err, Errs: []error{err, nil}}
+	want := "gdump.queryResult{\n• Rows:int{1}\n• Err:○*gdump.queryError{query select: conn 2: timeout}\n" +
+		"• Errs:[]error{\n• • ○*gdump.queryError{query select: conn 2: timeout}\n• • ○nil}}"
+	if got := Sdump(v, Options{Depth: 4, UseStringer: true}); got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	if got := Sdump(v, Options{Depth: 4}); !strings.Contains(got, "Err:○*fmt.wrapError{") {
+		t.Errorf("Sdump = %q without UseStringer, want the fields of the errors", got)
+	}
+}
+
+// multiRenderer implements all the interfaces of the renderer kinds.
+type multiRenderer struct{ N int }
+
+func (multiRenderer) Error() string { return "error" }
+
+func (multiRenderer) String() string { return "stringer" }
+
+func (multiRenderer) MarshalText() ([]byte, error) { return []byte("text"), nil }
+
+func (multiRenderer) Value() (driver.Value, error) { return int64(1), nil }
+
+func (multiRenderer) Format(f fmt.State, verb rune) { fmt.Fprintf(f, "format %c", verb) }
+
+type failingText struct{}
+
+func (failingText) MarshalText() ([]byte, error) { return nil, errors.New("bad") }
+
+func TestRendererPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		priority []RendererKind
+		want     string
+	}{
+		{"default", multiRenderer{1}, nil, "gdump.multiRenderer{error}"},
+		{"stringer", multiRenderer{1}, []RendererKind{RenderStringer}, "gdump.multiRenderer{stringer}"},
+		{"text first", multiRenderer{1}, []RendererKind{RenderTextMarshaler, RenderError}, "gdump.multiRenderer{text}"},
+		{"valuer", multiRenderer{1}, []RendererKind{RenderValuer}, "gdump.multiRenderer{1}"},
+		{"formatter", multiRenderer{1}, []RendererKind{RenderFormatter}, "gdump.multiRenderer{format v}"},
+		{"unknown kind skipped", multiRenderer{1}, []RendererKind{RendererKind(99), RenderStringer}, "gdump.multiRenderer{stringer}"},
+		// no interface to check prints the fields
+		{"none", multiRenderer{1}, []RendererKind{}, "gdump.multiRenderer{\n• N:int{1}}"},
+		{"text error", failingText{}, []RendererKind{RenderTextMarshaler}, "gdump.failingText{<error: bad>}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.value, Options{Depth: 2, UseStringer: true, RendererPriority: tt.priority})
+			if got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
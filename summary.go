@@ -0,0 +1,109 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Summarize returns a single-line summary of the structure of value, like
+// "*main.Config (struct, 12 fields, 3 nested, depth 4)", without dumping its
+// content. The depth is estimated from the type of value, and recursive
+// types are counted only once.
+func Summarize(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return "nil"
+	}
+	name := v.Type().String()
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return name + " (nil)"
+		}
+		v = v.Elem()
+	}
+	depth := typeDepth(v.Type(), map[reflect.Type]bool{})
+	switch v.Kind() {
+	case reflect.Struct:
+		nested := 0
+		for i := 0; i < v.NumField(); i++ {
+			if isComposite(getBaseType(v.Type().Field(i).Type)) {
+				nested++
+			}
+		}
+		return fmt.Sprintf("%s (struct, %d fields, %d nested, depth %d)", name, v.NumField(), nested, depth)
+	case reflect.Slice, reflect.Array:
+		return fmt.Sprintf("%s (%s, %d elements, depth %d)", name, v.Kind(), v.Len(), depth)
+	case reflect.Map:
+		return fmt.Sprintf("%s (map, %d entries, depth %d)", name, v.Len(), depth)
+	}
+	return fmt.Sprintf("%s (%s)", name, v.Kind())
+}
+
+// isComposite returns true if t has child values to be dumped.
+func isComposite(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// typeDepth returns the maximum nesting depth of the values of t. The types
+// in visiting are being walked and so not counted again.
+func typeDepth(t reflect.Type, visiting map[reflect.Type]bool) int {
+	t = getBaseType(t)
+	if !isComposite(t) || visiting[t] {
+		return 0
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+	max := 0
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if d := typeDepth(t.Field(i).Type, visiting); d > max {
+				max = d
+			}
+		}
+	default:
+		max = typeDepth(t.Elem(), visiting)
+	}
+	return max + 1
+}
+
+// depthSummary returns the summary of the struct, map, slice or array v
+// printed at the depth limit instead of its collapsed children, e.g.
+// "len:42 …" or "12 fields …". It returns false for the other values, the
+// empty ones, and if PlainEllipsis is set.
+func (s *dumpState) depthSummary(v reflect.Value, depth int) (string, bool) {
+	if depth > 0 || s.PlainEllipsis {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		n := 0
+		for _, f := range s.structFields(v) {
+			if !f.tag.skip && !s.isOmittedField(f.ft.Name) && !s.isExcludedType(f.ft.Type) && !s.isFilteredField(f) {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+			return "", false
+		case 1:
+			return "1 field …", true
+		}
+		return fmt.Sprintf("%d fields …", n), true
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return "", false
+		}
+		fallthrough
+	case reflect.Map:
+		if v.Len() == 0 {
+			return "", false
+		}
+		return fmt.Sprintf("len:%d …", v.Len()), true
+	}
+	return "", false
+}
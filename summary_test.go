@@ -0,0 +1,71 @@
+package gdump
+
+import (
+	"strings"
+	"testing"
+)
+
+// summaryNode - a recursive type counted once in the depth
+type summaryNode struct {
+	Name string
+	Kids []*summaryNode
+	Tags map[string][]int
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "nil"},
+		{1, "int (int)"},
+		{[]int{1, 2}, "[]int (slice, 2 elements, depth 1)"},
+		{[3][2]int{}, "[3][2]int (array, 3 elements, depth 2)"},
+		{map[string][]int{"a": nil}, "map[string][]int (map, 1 entries, depth 2)"},
+		{summaryNode{}, "gdump.summaryNode (struct, 3 fields, 2 nested, depth 3)"},
+		{&summaryNode{}, "*gdump.summaryNode (struct, 3 fields, 2 nested, depth 3)"},
+		{[]summaryNode{{}, {}}, "[]gdump.summaryNode (slice, 2 elements, depth 4)"},
+		{(*summaryNode)(nil), "*gdump.summaryNode (nil)"},
+		{struct{}{}, "struct {} (struct, 0 fields, 0 nested, depth 1)"},
+	}
+	for _, tt := range tests {
+		if got := Summarize(tt.value); got != tt.want {
+			t.Errorf("Summarize(%T) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+type summaryOuter struct {
+	S   []int
+	E   []int
+	M   map[string]int
+	B   []byte
+	N   summaryNode
+	One struct{ A int }
+}
+
+// TestDepthSummary summarizes the values at the depth limit by their
+// lengths and the fields printed, except the empty ones and the bytes.
+func TestDepthSummary(t *testing.T) {
+	v := summaryOuter{S: []int{1, 2, 3}, M: map[string]int{"a": 1}, B: []byte("hi"), N: summaryNode{Name: "n"}, One: struct{ A int }{1}}
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"summary", Options{Depth: 1}, []string{"S:[]int{len:3 …}", "E:[]int{nil}", "M:map[string]int{len:1 …}", `B:[]uint8{"hi"}`, "N:gdump.summaryNode{3 fields …}", "One:struct { A int }{1 field …}"}},
+		{"excluded", Options{Depth: 1, ExcludedField: []string{"Name"}}, []string{"N:gdump.summaryNode{2 fields …}"}},
+		{"plain", Options{Depth: 1, PlainEllipsis: true}, []string{"S:[]int{ ... ... ...}", "M:map[string]int{ a: ...}", "N:gdump.summaryNode{ Name: ... Kids: ... Tags: ...}"}},
+		{"top", Options{Depth: 0}, []string{"gdump.summaryOuter{6 fields …}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(v, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Sdump = %s, want %q", got, want)
+				}
+			}
+		})
+	}
+}
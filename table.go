@@ -0,0 +1,144 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// tableStruct returns the struct type of the elements of the slice or array
+// type t printed as a table, i.e. the structs or the pointers to them.
+func tableStruct(t reflect.Type) (reflect.Type, bool) {
+	et := t.Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return et, et.Kind() == reflect.Struct
+}
+
+// tableString returns the elements of the slice or array v of structs
+// printed as a table if TableSlices is set, e.g.
+//
+//	[]main.User{
+//	• Name   Age
+//	• alice  30
+//	• bob    7}
+//
+// The columns are the exported fields not excluded and the cells are the
+// field values printed in a line without their types.
+func (s *dumpState) tableString(v reflect.Value, depth, level int, indent string, noIndent bool) (string, bool) {
+	if !s.TableSlices || depth <= 0 || noIndent || s.ElementSeparator != "" || v.Len() == 0 || len(s.IncludedPaths) > 0 {
+		return "", false
+	}
+	st, ok := tableStruct(v.Type())
+	if !ok {
+		return "", false
+	}
+	var columns []int
+	header := []string{}
+	fields := typeFields(st)
+	for i, info := range fields {
+		ft, tag := info.ft, info.tag
+		name := ft.Name
+		if tag.name != "" {
+			name = tag.name
+		}
+		if ft.PkgPath != "" || tag.skip || s.isOmittedField(ft.Name) || s.isOmittedField(name) || s.isExcludedType(ft.Type) {
+			continue
+		}
+		columns = append(columns, i)
+		header = append(header, name)
+	}
+	if len(columns) == 0 {
+		return "", false
+	}
+	rows := [][]string{header}
+	indices, elided := s.itemIndices(level, v.Len())
+	for _, i := range indices {
+		if i < 0 {
+			rows = append(rows, []string{moreMarker(elided)})
+			continue
+		}
+		e := v.Index(i)
+		if e.Kind() == reflect.Ptr {
+			if e.IsNil() {
+				rows = append(rows, []string{"nil"})
+				continue
+			}
+			e = e.Elem()
+		}
+		row := make([]string, len(columns))
+		for c, f := range columns {
+			if fields[f].tag.redact || s.isRedactedName(fields[f].ft.Name) {
+				row[c] = redactedValue
+				continue
+			}
+			row[c] = s.tableCell(e.Field(f))
+		}
+		rows = append(rows, row)
+	}
+	s.setNode(v, "")
+	return s.tableRows(rows, indent+s.indentUnit()), true
+}
+
+// tableCell returns the field value v printed in a cell of a table.
+func (s *dumpState) tableCell(v reflect.Value) string {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	var cell string
+	switch {
+	case isValueNil(v) || v.Kind() == reflect.Interface:
+		cell = "nil"
+	case isPrimitiveKind(v.Kind()):
+		if value, ok := enumString(v); ok {
+			cell = value
+		} else {
+			cell = s.format(v)
+		}
+		if v.Kind() == reflect.String && s.RedactHighEntropy && s.isHighEntropy(cell) {
+			cell = redactedValue
+		}
+	default:
+		sub := &dumpState{Options: s.Options}
+		sub.TableSlices = false
+		cell = sub.valueString(v, 1, 0, 0, "", false, true)
+	}
+	cell = strings.ReplaceAll(inlineValue(cell, true), "\t", " ")
+	if s.MaxColumnWidth > 0 {
+		if prefix, more := runePrefix(cell, s.MaxColumnWidth); more > 0 {
+			cell = prefix + "…"
+		}
+	}
+	return cell
+}
+
+// tableRows returns the rows of the cells aligned in the columns, each in a
+// line indented by indent.
+func (s *dumpState) tableRows(rows [][]string, indent string) string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for c, cell := range row {
+			if c < len(widths) && len(row) > 1 {
+				if w := utf8.RuneCountInString(cell); w > widths[c] {
+					widths[c] = w
+				}
+			}
+		}
+	}
+	var b strings.Builder
+	for _, row := range rows {
+		var line strings.Builder
+		for c, cell := range row {
+			if c > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell)
+			if c < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[c]-utf8.RuneCountInString(cell)))
+			}
+		}
+		b.WriteString("\n" + indent + line.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package gdump
+
+import "testing"
+
+type tableUser struct {
+	Name   string
+	Age    int
+	Note   string `gdump:"note"`
+	Tags   []string
+	secret int
+}
+
+// TestTableSlices prints the slices of structs or of the pointers to them
+// as the tables of the exported fields, the cells cut at MaxColumnWidth.
+func TestTableSlices(t *testing.T) {
+	v := []tableUser{{"alice", 30, "a\nb", []string{"x"}, 1}, {"bob", 7, "a long description", nil, 2}}
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"table", v, Options{Depth: 3, TableSlices: true},
+			"[]gdump.tableUser{\n• Name   Age  note                Tags\n• alice  30   \"a\\nb\"              []string{string{x}}\n• bob    7    a long description  nil}"},
+		{"column width", v, Options{Depth: 3, TableSlices: true, MaxColumnWidth: 6},
+			"[]gdump.tableUser{\n• Name   Age  note     Tags\n• alice  30   \"a\\nb\"   []stri…\n• bob    7    a long…  nil}"},
+		{"pointers", []*tableUser{&v[0], nil}, Options{Depth: 3, TableSlices: true, ExcludedField: []string{"Age", "note"}},
+			"[]*gdump.tableUser{\n• Name   Tags\n• alice  []string{string{x}}\n• nil}"},
+		{"nested", struct{ U []tableUser }{v}, Options{Depth: 3, TableSlices: true, MaxItems: 1},
+			"struct { U []gdump.tableUser }{\n• U:[]gdump.tableUser{\n• • Name   Age  note    Tags\n• • alice  30   \"a\\nb\"  []string{string{x}}\n• • …(+1 more)}}"},
+		{"disabled", v[1:], Options{Depth: 1},
+			"[]gdump.tableUser{\n• gdump.tableUser{5 fields …}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagName - the key of the struct tag read by the dump
+const tagName = "gdump"
+
+// legacyTagName - the key of the struct tag read if tagName is not present
+const legacyTagName = "dump"
+
+// fieldTag - the directives of the struct tag of a field, e.g.
+// `gdump:"name,omitempty,validate=required"`
+type fieldTag struct {
+	name      string     // the first item or name=: the field name printed instead
+	skip      bool       // "-": never printed
+	omitEmpty bool       // omitempty: not printed if zero
+	redact    bool       // redact: printed as ***REDACTED***
+	depth     int        // depth=N: the print depth of the field, -1 if not set
+	bits      []bitField // bits=hi:lo:name,...: decoded by Options.DecodeBits
+	validate  []string   // validate=rule,...: checked by Options.ShowValidation
+}
+
+// bitField - a bit range of a packed integer
+type bitField struct {
+	hi, lo uint
+	name   string
+}
+
+// parseTag returns the directives of the struct tag of ft. The first item
+// not in the form of key=value is the field name like encoding/json, which
+// can be also set by name=, and "-" skips the field. The directives are
+// separated by commas as well as the values of the bits and validate
+// directives, so an item not in the form of key=value continues the
+// previous directive. omitempty and redact are never taken as a field name
+// or a value, even as the first item.
+func parseTag(ft reflect.StructField) fieldTag {
+	tag := fieldTag{depth: -1}
+	str, ok := ft.Tag.Lookup(tagName)
+	if !ok {
+		str = ft.Tag.Get(legacyTagName)
+	}
+	if str == "-" {
+		tag.skip = true
+		return tag
+	}
+	var key string
+	for i, item := range strings.Split(str, ",") {
+		value := item
+		if k, v, ok := strings.Cut(item, "="); ok {
+			key, value = k, v
+		} else if item == "redact" {
+			tag.redact = true
+			continue
+		} else if item == "omitempty" {
+			tag.omitEmpty = true
+			continue
+		} else if i == 0 {
+			tag.name = item
+			continue
+		}
+		switch key {
+		case "name":
+			tag.name = value
+		case "bits":
+			tag.bits = append(tag.bits, parseBitField(value))
+		case "validate":
+			tag.validate = append(tag.validate, value)
+		case "depth":
+			if d, err := strconv.Atoi(value); err == nil && d >= 0 {
+				tag.depth = d
+			}
+		}
+	}
+	return tag
+}
+
+// parseBitField returns the bit range of hi:lo:name. The name is empty if
+// the range is invalid.
+func parseBitField(s string) bitField {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[2] == "" {
+		return bitField{}
+	}
+	hi, err1 := strconv.ParseUint(parts[0], 10, 8)
+	lo, err2 := strconv.ParseUint(parts[1], 10, 8)
+	if err1 != nil || err2 != nil || hi < lo {
+		return bitField{}
+	}
+	return bitField{hi: uint(hi), lo: uint(lo), name: parts[2]}
+}
+
+// decodeBits returns the bit ranges of the integer v decoded like
+// uint8{ready=1 mode=3 err=0}. It returns false if v is not an integer or
+// any of the ranges is invalid for v.
+func decodeBits(v reflect.Value, bits []bitField) (string, bool) {
+	var raw uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		raw = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		raw = v.Uint()
+	default:
+		return "", false
+	}
+	size := uint(v.Type().Bits())
+	fields := make([]string, 0, len(bits))
+	for _, b := range bits {
+		if b.name == "" || b.hi >= size {
+			return "", false
+		}
+		width := b.hi - b.lo + 1
+		value := raw >> b.lo
+		if width < 64 {
+			value &= 1<<width - 1
+		}
+		fields = append(fields, fmt.Sprintf("%s=%d", b.name, value))
+	}
+	return fmt.Sprintf("%s{%s}", v.Type(), strings.Join(fields, " ")), len(fields) > 0
+}
+
+// fieldMetadata returns the metadata of the struct field f printed by
+// Options.ShowFieldTags, e.g. (`json:"name"` exported @0+16).
+func fieldMetadata(f structField) string {
+	meta := make([]string, 0, 3)
+	if f.ft.Tag != "" {
+		meta = append(meta, "`"+string(f.ft.Tag)+"`")
+	}
+	if f.ft.PkgPath == "" {
+		meta = append(meta, "exported")
+	} else {
+		meta = append(meta, "unexported")
+	}
+	meta = append(meta, fmt.Sprintf("@%d+%d", f.offset, f.ft.Type.Size()))
+	return "(" + strings.Join(meta, " ") + ")"
+}
@@ -0,0 +1,211 @@
+package gdump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  reflect.StructTag
+		want fieldTag
+	}{
+		{``, fieldTag{depth: -1}},
+		{`gdump:"-"`, fieldTag{skip: true, depth: -1}},
+		{`gdump:"id"`, fieldTag{name: "id", depth: -1}},
+		{`gdump:"name=id"`, fieldTag{name: "id", depth: -1}},
+		{`gdump:"omitempty"`, fieldTag{omitEmpty: true, depth: -1}},
+		{`gdump:"redact"`, fieldTag{redact: true, depth: -1}},
+		{`gdump:"omitempty,redact"`, fieldTag{omitEmpty: true, redact: true, depth: -1}},
+		{`gdump:"id,omitempty"`, fieldTag{name: "id", omitEmpty: true, depth: -1}},
+		{`gdump:",omitempty"`, fieldTag{omitEmpty: true, depth: -1}},
+		{`gdump:"depth=2"`, fieldTag{depth: 2}},
+		{`gdump:"depth=-1"`, fieldTag{depth: -1}},
+		{`gdump:"validate=required,nonzero"`, fieldTag{depth: -1, validate: []string{"required", "nonzero"}}},
+		{`dump:"legacy"`, fieldTag{name: "legacy", depth: -1}},
+		{`gdump:"new" dump:"legacy"`, fieldTag{name: "new", depth: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tag), func(t *testing.T) {
+			got := parseTag(reflect.StructField{Name: "F", Tag: tt.tag})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTag(%s) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOmitEmptyTag(t *testing.T) {
+	type tagged struct {
+		A int `gdump:"omitempty"`
+		B int `gdump:"b,omitempty"`
+	}
+	tests := []struct {
+		value tagged
+		want  string
+	}{
+		{tagged{B: 2}, "gdump.tagged{b:int{2}}"},
+		{tagged{A: 1, B: 2}, "gdump.tagged{A:int{1} b:int{2}}"},
+	}
+	for _, tt := range tests {
+		if got := ValueDumpInline(tt.value, 2, nil); got != tt.want {
+			t.Errorf("ValueDumpInline(%+v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+type TagBase struct{ E int }
+
+type taggedRecord struct {
+	TagBase
+	Secret string `gdump:"-"`
+	ID     int    `gdump:"id"`
+	Note   string `gdump:"note,omitempty"`
+	Keep   int
+}
+
+// TestFieldTags skips, renames and omits the tagged fields, excludes the
+// fields by either of their names and expands the untagged embedded struct.
+func TestFieldTags(t *testing.T) {
+	v := taggedRecord{TagBase{1}, "s", 2, "", 3}
+	const base = "gdump.taggedRecord{\n• TagBase:gdump.TagBase{\n• • E:int{1}}"
+	tests := []struct {
+		name     string
+		value    taggedRecord
+		excluded []string
+		want     string
+	}{
+		{"tags", v, nil, base + "\n• id:int{2}\n• Keep:int{3}}\n"},
+		{"not empty", taggedRecord{Note: "n", Keep: 3}, nil, "gdump.taggedRecord{\n• TagBase:gdump.TagBase{{0}}\n• id:int{0}\n• note:string{n}\n• Keep:int{3}}\n"},
+		{"excluded by the tag name", v, []string{"id", "Keep"}, base + "}\n"},
+		{"excluded by the field name", v, []string{"ID"}, base + "\n• Keep:int{3}}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValueDump(tt.value, 3, nil, tt.excluded...); got != tt.want {
+				t.Errorf("ValueDump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type bitRegister struct {
+	Status uint8  `gdump:"bits=7:7:ready,6:4:mode,3:0:err"`
+	Signed int8   `gdump:"bits=7:7:sign,6:0:low"`
+	Wide   uint64 `gdump:"bits=63:0:all"`
+	Over   uint8  `gdump:"bits=8:0:over"`
+	Text   string `gdump:"bits=1:0:x"`
+	Named  uint16 `gdump:"flags,bits=15:8:hi,7:0:lo"`
+}
+
+// TestDecodeBits decodes the bit ranges of the integer fields, and prints
+// the integers as they are for a range out of their bits and the other
+// fields tagged.
+func TestDecodeBits(t *testing.T) {
+	v := bitRegister{Status: 0xB5, Signed: -1, Wide: 1<<64 - 1, Over: 3, Text: "s", Named: 0x1234}
+	tests := []struct {
+		name   string
+		decode bool
+		want   string
+	}{
+		{"decoded", true, "gdump.bitRegister{\n• Status:uint8{ready=1 mode=3 err=5}\n• Signed:int8{sign=1 low=127}\n" +
+			"• Wide:uint64{all=18446744073709551615}\n• Over:uint8{3}\n• Text:string{s}\n• flags:uint16{hi=18 lo=52}}"},
+		{"raw", false, "gdump.bitRegister{\n• Status:uint8{181}\n• Signed:int8{-1}\n" +
+			"• Wide:uint64{18446744073709551615}\n• Over:uint8{3}\n• Text:string{s}\n• flags:uint16{4660}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, Options{Depth: 2, DecodeBits: tt.decode}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type depthConn struct {
+	Host string
+	Opts struct{ Retry struct{ N int } }
+}
+
+type depthService struct {
+	Name string
+	Conn *depthConn
+	Peer depthConn
+	Deep struct{ A struct{ B struct{ C int } } } `gdump:"depth=1"`
+	Wide struct{ A struct{ B int } }             `gdump:"depth=5"`
+}
+
+// TestTypeDepth replaces the depth left at the values of the types and the
+// pointers to them by TypeDepth, and at the fields by the depth tags.
+func TestTypeDepth(t *testing.T) {
+	v := depthService{Name: "s", Conn: &depthConn{Host: "h"}, Peer: depthConn{Host: "p"}}
+	v.Conn.Opts.Retry.N = 1
+	v.Deep.A.B.C = 2
+	v.Wide.A.B = 3
+	const tagged = "\n• Deep:struct { A struct { B struct { C int } } }{\n• • A:struct { B struct { C int } }{1 field …}}" +
+		"\n• Wide:struct { A struct { B int } }{\n• • A:struct { B int }{\n• • • B:int{3}}}}"
+	conn := reflect.TypeOf(depthConn{})
+	tests := []struct {
+		name  string
+		depth int
+		types map[reflect.Type]int
+		want  string
+	}{
+		{"tags", 2, nil, "gdump.depthService{\n• Name:string{s}" +
+			"\n• Conn:*gdump.depthConn{\n• • Host:string{h}\n• • Opts:struct { Retry struct { N int } }{1 field …}}" +
+			"\n• Peer:gdump.depthConn{\n• • Host:string{p}\n• • Opts:struct { Retry struct { N int } }{{{0}}}}" + tagged},
+		{"collapsed", 2, map[reflect.Type]int{conn: 0}, "gdump.depthService{\n• Name:string{s}" +
+			"\n• Conn:*gdump.depthConn{2 fields …}\n• Peer:gdump.depthConn{2 fields …}" + tagged},
+		{"deepened", 1, map[reflect.Type]int{conn: 3}, "gdump.depthService{\n• Name:string{s}" +
+			"\n• Conn:*gdump.depthConn{\n• • Host:string{h}\n• • Opts:struct { Retry struct { N int } }{\n• • • Retry:struct { N int }{\n• • • • N:int{1}}}}" +
+			"\n• Peer:gdump.depthConn{\n• • Host:string{p}\n• • Opts:struct { Retry struct { N int } }{{{0}}}}" + tagged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, Options{Depth: tt.depth, TypeDepth: tt.types}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type metaRecord struct {
+	ID    int32 `json:"id" gdump:"id"`
+	Flags uint16
+	note  byte
+}
+
+// TestShowFieldTags prints the tags, the exported states and the offsets and
+// sizes of the fields after their names, and of the embedded fields flattened
+// by their own offsets.
+func TestShowFieldTags(t *testing.T) {
+	v := metaRecord{1, 2, 3}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"shown", Options{Depth: 2, ShowFieldTags: true}, "gdump.metaRecord{\n• id(`json:\"id\" gdump:\"id\"` exported @0+4):int32{1}" +
+			"\n• Flags(exported @4+2):uint16{2}\n• note(unexported @6+1):3}"},
+		{"unexported", Options{Depth: 2, ShowFieldTags: true, ShowUnexported: true}, "gdump.metaRecord{\n• id(`json:\"id\" gdump:\"id\"` exported @0+4):int32{1}" +
+			"\n• Flags(exported @4+2):uint16{2}\n• note(unexported @6+1):uint8{3}}"},
+		{"hidden", Options{Depth: 2}, "gdump.metaRecord{\n• id:int32{1}\n• Flags:uint16{2}\n• note:3}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	w := struct {
+		N int32
+		metaRecord
+	}{1, v}
+	got := New(WithDepth(2), WithFieldTags(true), WithFlattenEmbedded(true)).Sdump(w)
+	if want := "• metaRecord.Flags(exported @8+2):uint16{2}\n"; !strings.Contains(got, want) {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package gdump
+
+import (
+	"strings"
+	"time"
+)
+
+// timeoutMarker - the mark of the values not dumped within PerValueTimeout
+const timeoutMarker = "…(timeout)"
+
+// canceledMarker - the mark of the values not dumped before the context of
+// DumpContext is done
+const canceledMarker = "…(canceled)"
+
+// SdumpAll returns the string representations of values dumped with opts
+// in lines. Options.PerValueTimeout is applied to each value independently,
+// so that a large value does not stop dumping the others.
+func SdumpAll(opts Options, values ...interface{}) string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = Sdump(value, opts)
+	}
+	return strings.Join(out, "\n")
+}
+
+// startTimer sets the deadline of the dump if PerValueTimeout is set.
+func (s *dumpState) startTimer() {
+	if s.PerValueTimeout > 0 {
+		s.deadline = time.Now().Add(s.PerValueTimeout)
+	}
+}
+
+// isTimedOut returns true if the deadline of the dump is exceeded or the
+// context of the dump is done. They are checked once in the 64 values dumped.
+func (s *dumpState) isTimedOut() bool {
+	if s.timedOut || s.deadline.IsZero() && s.ctx == nil {
+		return s.timedOut
+	}
+	if !s.timedOut {
+		if s.visits++; s.visits%64 == 0 {
+			s.timedOut = !s.deadline.IsZero() && time.Now().After(s.deadline) ||
+				s.ctx != nil && s.ctx.Err() != nil
+		}
+	}
+	return s.timedOut
+}
+
+// stopMarker returns the mark of the values not dumped since isTimedOut.
+func (s *dumpState) stopMarker() string {
+	if s.ctx != nil && s.ctx.Err() != nil {
+		return canceledMarker
+	}
+	return timeoutMarker
+}
@@ -0,0 +1,29 @@
+package gdump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPerValueTimeout stops the large value at its deadline and dumps the
+// small values around it in full, the deadlines being checked once in the
+// 64 values dumped.
+func TestPerValueTimeout(t *testing.T) {
+	large := make([]int, 10000)
+	opts := Options{Depth: 2, PerValueTimeout: time.Nanosecond}
+	out := SdumpAll(opts, 1, large, "s", large[:3])
+	if !strings.HasPrefix(out, "int{1}\n[]int{\n• int{0}\n") {
+		t.Errorf("SdumpAll = %q, want the large value started after the small one", out[:40])
+	}
+	if want := "\n• int{0}\n• " + timeoutMarker + "}\nstring{s}\n[]int{\n• int{0}\n• int{0}\n• int{0}}"; !strings.HasSuffix(out, want) {
+		t.Errorf("SdumpAll = %q, want the large value stopped before the small ones", out[len(out)-80:])
+	}
+	if n := strings.Count(out, "• int{0}"); n < 3 || n >= len(large) {
+		t.Errorf("SdumpAll dumped %d elements, want the large value stopped", n)
+	}
+
+	if got := Sdump(large[:100], Options{Depth: 2}); strings.Contains(got, timeoutMarker) {
+		t.Errorf("Sdump stopped without PerValueTimeout")
+	}
+}
@@ -0,0 +1,112 @@
+package gdump
+
+import "reflect"
+
+// DumpNode - a node of the tree built from a dumped value
+type DumpNode struct {
+	// Name - the field name, map key or slice index of the node (empty at the top)
+	Name string
+	// Type - the type name of the value (empty for an untyped nil)
+	Type string
+	// Kind - the kind of the value
+	Kind reflect.Kind
+	// Value - the printed value of a leaf node, or "..." or the summary like
+	// "len:3 …" if not dumped due to the depth
+	Value string
+	// Children - the child nodes of structs, slices and maps
+	Children []*DumpNode
+
+	base reflect.Kind // the kind of the value the pointers and interfaces refer to
+}
+
+// BuildTree returns the tree of value dumped with opts. The values dumped
+// before are referred to by their paths, e.g. → Spec.Config, rather than by
+// the numbers printed only in the text dump.
+func BuildTree(value interface{}, opts Options) *DumpNode {
+	_, node := dumpBoth(value, opts, true)
+	return node
+}
+
+// Parse returns the tree of value dumped in depth like ValueDump. The
+// elements of the slices of numbers, strings and booleans are the children
+// of the slices even if CompactPrimitiveSlices is set, and so are the
+// elements printed as a table by TableSlices.
+func Parse(value interface{}, depth int) *DumpNode {
+	opts := globalOptions(depth)
+	opts.CompactPrimitiveSlices = false
+	opts.TableSlices = false
+	return BuildTree(value, opts)
+}
+
+// Parse returns the tree of value dumped with the options of d like the
+// package-level Parse.
+func (d *Dumper) Parse(value interface{}) *DumpNode {
+	opts := d.optionsFor(nil)
+	opts.CompactPrimitiveSlices = false
+	opts.TableSlices = false
+	return BuildTree(value, opts)
+}
+
+// Find returns the nodes of the tree from n matched by match in the
+// depth-first order, e.g. the non-nil errors:
+//
+//	n.Find(func(c *DumpNode) bool { return c.Type == "error" && c.Value != "nil" })
+func (n *DumpNode) Find(match func(node *DumpNode) bool) []*DumpNode {
+	var found []*DumpNode
+	if match(n) {
+		found = append(found, n)
+	}
+	for _, c := range n.Children {
+		found = append(found, c.Find(match)...)
+	}
+	return found
+}
+
+// DumpBoth returns both the string representation and the tree of value
+// dumped with opts. They are built in a single traversal and so share the
+// same snapshot of the value.
+func DumpBoth(value interface{}, opts Options) (string, *DumpNode) {
+	return dumpBoth(value, opts, false)
+}
+
+// dumpBoth returns the dump and the tree of value like DumpBoth. The values
+// dumped before are referred to by their paths if refPaths is set.
+func dumpBoth(value interface{}, opts Options, refPaths bool) (string, *DumpNode) {
+	if opts.SnapshotFirst {
+		value = snapshot(value)
+	}
+	node := &DumpNode{}
+	ds := &dumpState{Options: opts, node: node, refPaths: refPaths}
+	ds.startTimer()
+	s := ds.valueString(reflect.ValueOf(value), opts.Depth, 0, 0, "", false, false)
+	return ds.decorate(value, s), node
+}
+
+// enterNode appends a new child node named name to the node being built and
+// makes it the current node. It returns the previous node.
+func (s *dumpState) enterNode(name string) *DumpNode {
+	parent := s.node
+	if parent != nil {
+		s.node = &DumpNode{Name: name}
+		parent.Children = append(parent.Children, s.node)
+	}
+	return parent
+}
+
+// setNode records the type of v and its printed value to the current node.
+// The type of the outermost pointer or interface is kept.
+func (s *dumpState) setNode(v reflect.Value, value string) {
+	if s.node == nil {
+		return
+	}
+	if s.node.Type == "" && v.IsValid() {
+		s.node.Type = v.Type().String()
+		s.node.Kind = v.Kind()
+	}
+	if v.IsValid() {
+		s.node.base = v.Kind()
+	}
+	if value != "" {
+		s.node.Value = value
+	}
+}
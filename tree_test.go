@@ -0,0 +1,108 @@
+package gdump
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type treeOrder struct {
+	ID    int
+	Tags  []string
+	Owner *string
+	Meta  map[string]int
+}
+
+// treeLines returns the nodes from n as the lines of their paths, types and
+// values in the depth-first order.
+func treeLines(n *DumpNode, path string) []string {
+	if n.Name != "" {
+		path = strings.TrimPrefix(path+"."+n.Name, ".")
+	}
+	lines := []string{path + " " + n.Type + " " + n.Value}
+	for _, c := range n.Children {
+		lines = append(lines, treeLines(c, path)...)
+	}
+	return lines
+}
+
+func TestDumpBoth(t *testing.T) {
+	owner := "me"
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{"struct", treeOrder{7, []string{"a"}, &owner, map[string]int{"k": 1}}, []string{
+			" gdump.treeOrder ",
+			"ID int 7",
+			"Tags []string ",
+			"Tags.0 string a",
+			"Owner *string me",
+			"Meta map[string]int ",
+			"Meta.k int 1",
+		}},
+		{"zero struct", treeOrder{}, []string{" gdump.treeOrder {0 [] <nil> map[]}"}},
+		{"untyped nil", nil, []string{"  nil"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Depth: 3}
+			out, node := DumpBoth(tt.value, opts)
+			if want := Sdump(tt.value, opts); out != want {
+				t.Errorf("DumpBoth = %q, want the Sdump %q", out, want)
+			}
+			if got := treeLines(node, ""); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+type treeJob struct {
+	ID   int
+	Ints []int
+	Err  error
+	Nil  error
+	Self *treeJob
+	Deep struct{ A struct{ B int } }
+}
+
+// TestParse builds the tree with the kinds of the nodes, the primitive
+// slices expanded, the references by the paths and the summaries at the
+// depth, and finds the nodes in it.
+func TestParse(t *testing.T) {
+	v := &treeJob{ID: 1, Ints: []int{1, 2}, Err: errors.New("x")}
+	v.Self = v
+	v.Deep.A.B = 5
+	n := Parse(v, 2)
+	want := []string{
+		" *gdump.treeJob ",
+		"ID int 1",
+		"Ints []int ",
+		"Ints.0 int 1",
+		"Ints.1 int 2",
+		"Err error x",
+		"Nil error nil",
+		"Self *gdump.treeJob ↩ (top)",
+		"Deep struct { A struct { B int } } ",
+		"Deep.A struct { B int } 1 field …",
+	}
+	if got := treeLines(n, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	kinds := []reflect.Kind{reflect.Int, reflect.Slice, reflect.Interface, reflect.Interface, reflect.Ptr, reflect.Struct}
+	for i, c := range n.Children {
+		if c.Kind != kinds[i] {
+			t.Errorf("%s: Kind = %v, want %v", c.Name, c.Kind, kinds[i])
+		}
+	}
+	found := n.Find(func(c *DumpNode) bool { return c.Type == "error" && c.Value != "nil" })
+	if len(found) != 1 || found[0].Name != "Err" {
+		t.Errorf("Find = %+v, want the node of Err", found)
+	}
+	if got := treeLines(New(WithDepth(2), WithTable(true, 0)).Parse([]int{1, 2}), ""); !reflect.DeepEqual(got, []string{" []int ", "0 int 1", "1 int 2"}) {
+		t.Errorf("tree = %q, want the elements", got)
+	}
+}
@@ -0,0 +1,123 @@
+package gdump
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// runePrefix returns the first max runes of str and the number of the rest
+// runes. 0 means unlimited.
+func runePrefix(str string, max int) (string, int) {
+	if max <= 0 {
+		return str, 0
+	}
+	i := 0
+	for r := 0; r < max && i < len(str); r++ {
+		_, size := utf8.DecodeRuneInString(str[i:])
+		i += size
+	}
+	return str[:i], utf8.RuneCountInString(str[i:])
+}
+
+// moreMarker returns the mark of the n runes or bytes elided.
+func moreMarker(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("…(+%d more)", n)
+}
+
+// truncateString returns str truncated by MaxStringLen, e.g. abc…(+123 more).
+func (s *dumpState) truncateString(str string) string {
+	prefix, more := runePrefix(str, s.MaxStringLen)
+	return prefix + moreMarker(more)
+}
+
+// stringValue returns the string str truncated by MaxStringLen and quoted
+// if it is unsafe to print, e.g. "a\nb" or "\x1b[31mred". If
+// MultilineStrings is set, the lines of str are printed in the lines
+// indented by indent instead unless noIndent is set. The strings are
+// printed as they are if RawStrings is set.
+func (s *dumpState) stringValue(str, indent string, noIndent bool) string {
+	if s.RawStrings {
+		return inlineValue(s.truncateString(str), noIndent)
+	}
+	prefix, more := runePrefix(str, s.MaxStringLen)
+	if s.MultilineStrings && !noIndent && strings.Contains(prefix, "\n") {
+		lines := strings.Split(prefix, "\n")
+		for i, line := range lines {
+			lines[i] = quoteUnsafe(line)
+		}
+		inner := "\n" + indent + s.indentUnit()
+		return inner + strings.Join(lines, inner) + moreMarker(more)
+	}
+	return quoteUnsafe(prefix) + moreMarker(more)
+}
+
+// quoteUnsafe returns str quoted if it has any character breaking the lines
+// or the terminals, i.e. the non-printable characters except the tabs or
+// invalid UTF-8.
+func quoteUnsafe(str string) string {
+	for i, r := range str {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(str[i:]); size == 1 {
+				return strconv.Quote(str)
+			}
+		}
+		if r != '\t' && !strconv.IsPrint(r) {
+			return strconv.Quote(str)
+		}
+	}
+	return str
+}
+
+// bytesString returns the byte slice or array v printed in BytesFormat. By
+// default, it is printed as a quoted string if it is valid UTF-8, or in hex
+// otherwise, e.g. "hello" or 0x01ff. The bytes are truncated by MaxStringLen.
+// The lines of BytesHexdump are indented by indent, or it is printed in hex
+// if noIndent is set.
+func (s *dumpState) bytesString(v reflect.Value, indent string, noIndent bool) string {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	max := s.MaxStringLen
+	if s.BytesFormat == BytesAuto && utf8.Valid(b) {
+		prefix, more := runePrefix(string(b), max)
+		return strconv.Quote(prefix) + moreMarker(more)
+	}
+	more := 0
+	if max > 0 && len(b) > max {
+		b, more = b[:max], len(b)-max
+	}
+	switch s.BytesFormat {
+	case BytesBase64:
+		return base64.StdEncoding.EncodeToString(b) + moreMarker(more)
+	case BytesHexdump:
+		if !noIndent && len(b) > 0 {
+			lines := strings.Split(strings.TrimSuffix(hex.Dump(b), "\n"), "\n")
+			return "\n" + indent + strings.Join(lines, "\n"+indent) + moreMarker(more)
+		}
+	}
+	return fmt.Sprintf("0x%x", b) + moreMarker(more)
+}
+
+// BytesFormat - the format of the byte slices and arrays
+type BytesFormat int
+
+const (
+	// BytesAuto - a quoted string if valid UTF-8, or in hex otherwise
+	BytesAuto BytesFormat = iota
+	// BytesHex - in hex, e.g. 0xdeadbeef
+	BytesHex
+	// BytesBase64 - in the standard base64 encoding
+	BytesBase64
+	// BytesHexdump - in the lines of the offsets, hex and characters like
+	// hexdump -C
+	BytesHexdump
+)
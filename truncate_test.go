@@ -0,0 +1,194 @@
+package gdump
+
+import "testing"
+
+func TestTruncateStrategy(t *testing.T) {
+	v := []int{1, 2, 3, 4, 5, 6, 7}
+	m := map[int]int{1: 1, 2: 2, 3: 3, 4: 4}
+	tests := []struct {
+		name     string
+		value    interface{}
+		strategy TruncateStrategy
+		items    int
+		want     string
+	}{
+		{"head", v, TruncHead, 3, "[]int{\n• int{1}\n• int{2}\n• int{3}\n• …(+4 more)}"},
+		{"tail", v, TruncTail, 3, "[]int{\n• …(+4 more)\n• int{5}\n• int{6}\n• int{7}}"},
+		// the extra entry of an odd limit is taken from the head
+		{"middle odd", v, TruncMiddle, 3, "[]int{\n• int{1}\n• int{2}\n• …(+4 more)\n• int{7}}"},
+		{"middle even", v, TruncMiddle, 4, "[]int{\n• int{1}\n• int{2}\n• …(+3 more)\n• int{6}\n• int{7}}"},
+		{"not truncated", v, TruncMiddle, 7, "[]int{\n• int{1}\n• int{2}\n• int{3}\n• int{4}\n• int{5}\n• int{6}\n• int{7}}"},
+		{"map head", m, TruncHead, 2, "map[int]int{\n• 1:int{1}\n• 2:int{2}\n• …(+2 more)}"},
+		{"map tail", m, TruncTail, 2, "map[int]int{\n• …(+2 more)\n• 3:int{3}\n• 4:int{4}}"},
+		{"map middle", m, TruncMiddle, 2, "map[int]int{\n• 1:int{1}\n• …(+2 more)\n• 4:int{4}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.value, Options{Depth: 2, MaxItems: tt.items, TruncateStrategy: tt.strategy})
+			if got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMaxStringLen cuts the strings by runes and the bytes by bytes, the
+// valid UTF-8 bytes being quoted before the marker.
+func TestMaxStringLen(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"runes", "héllo wörld", "string{hél…(+8 more)}"},
+		{"wide runes", "日本語テキスト", "string{日本語…(+4 more)}"},
+		{"short", "ab", "string{ab}"},
+		{"bytes", []byte("hello"), `[]uint8{"hel"…(+2 more)}`},
+		{"binary", []byte{0xff, 1, 2, 3, 4}, "[]uint8{0xff0102…(+2 more)}"},
+		{"array", [4]byte{'a', 'b', 'c', 'd'}, `[4]uint8{"abc"…(+1 more)}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 2, MaxStringLen: 3}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	defer func(max int) { MaxStringLen = max }(MaxStringLen)
+	MaxStringLen = 2
+	if got, want := ValueDump("héllo", 2, nil), "string{hé…(+3 more)}\n"; got != want {
+		t.Errorf("ValueDump = %q, want %q by the package-level limit", got, want)
+	}
+}
+
+type quotedRecord struct {
+	Lines   string
+	ANSI    string
+	Invalid string
+	Tab     string
+	Keys    map[string]int
+}
+
+// TestStringQuoting quotes the strings and the map keys breaking the lines
+// or the terminals, prints them as they are by RawStrings, and the lines of
+// the strings in the indented lines by MultilineStrings.
+func TestStringQuoting(t *testing.T) {
+	v := quotedRecord{"a\nb", "\x1b[31mred", "bad\xff", "tab\there", map[string]int{"k\n": 1}}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"quoted", Options{Depth: 2}, "gdump.quotedRecord{\n• Lines:string{\"a\\nb\"}\n• ANSI:string{\"\\x1b[31mred\"}\n• Invalid:string{\"bad\\xff\"}" +
+			"\n• Tab:string{tab\there}\n• Keys:map[string]int{\n• • \"k\\n\":int{1}}}"},
+		{"raw", Options{Depth: 2, RawStrings: true}, "gdump.quotedRecord{\n• Lines:string{a\nb}\n• ANSI:string{\x1b[31mred}\n• Invalid:string{bad\xff}" +
+			"\n• Tab:string{tab\there}\n• Keys:map[string]int{\n• • k\n:int{1}}}"},
+		{"multiline", Options{Depth: 2, MultilineStrings: true}, "gdump.quotedRecord{\n• Lines:string{\n• • a\n• • b}\n• ANSI:string{\"\\x1b[31mred\"}\n• Invalid:string{\"bad\\xff\"}" +
+			"\n• Tab:string{tab\there}\n• Keys:map[string]int{\n• • \"k\\n\":int{1}}}"},
+		{"multiline truncated", Options{Depth: 2, MultilineStrings: true, MaxStringLen: 2}, "gdump.quotedRecord{\n• Lines:string{\n• • a\n• • …(+1 more)}\n• ANSI:string{\"\\x1b[\"…(+6 more)}" +
+			"\n• Invalid:string{ba…(+2 more)}\n• Tab:string{ta…(+6 more)}\n• Keys:map[string]int{\n• • \"k\\n\":int{1}}}"},
+		{"json", Options{Depth: 2, MultilineStrings: true, Format: FormatJSON}, "{\n  \"Lines\": \"a\\nb\",\n  \"ANSI\": \"\\u001b[31mred\",\n  \"Invalid\": \"bad\ufffd\"," +
+			"\n  \"Tab\": \"tab\\there\",\n  \"Keys\": {\n    \"k\\n\": 1\n  }\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got, want := ValueDumpInline(v.Keys, 2, nil), `map[string]int{"k\n":int{1}}`; got != want {
+		t.Errorf("ValueDumpInline = %q, want %q", got, want)
+	}
+}
+
+type budgetRecord struct {
+	A []int
+	M map[int]int
+	B [][]int
+}
+
+// TestMaxNodes stops the containers at the nodes counted across the whole
+// dump, and cuts the output at MaxOutputBytes.
+func TestMaxNodes(t *testing.T) {
+	v := budgetRecord{[]int{1, 2, 3, 4, 5}, map[int]int{1: 1, 2: 2, 3: 3}, [][]int{{1, 2}, {3, 4}}}
+	const a = "\n• A:[]int{\n• • int{1}\n• • int{2}\n• • int{3}\n• • int{4}\n• • int{5}}"
+	tests := []struct {
+		nodes int
+		want  string
+	}{
+		{1, "gdump.budgetRecord{\n• …(truncated, 0 of 3 shown)}"},
+		{3, "gdump.budgetRecord{\n• A:[]int{\n• • int{1}\n• • …(truncated, 1 of 5 shown)}\n• …(truncated, 1 of 3 shown)}"},
+		{9, "gdump.budgetRecord{" + a + "\n• M:map[int]int{\n• • 1:int{1}\n• • …(truncated, 1 of 3 shown)}\n• …(truncated, 2 of 3 shown)}"},
+		// the count is shared by the branches, B getting only the nodes left by A and M
+		{14, "gdump.budgetRecord{" + a + "\n• M:map[int]int{\n• • 1:int{1}\n• • 2:int{2}\n• • 3:int{3}}\n• B:[][]int{\n• • []int{\n• • • int{1}\n• • • …(truncated, 1 of 2 shown)}\n• • …(truncated, 1 of 2 shown)}}"},
+		{0, Sdump(v, Options{Depth: 3})},
+	}
+	for _, tt := range tests {
+		if got := Sdump(v, Options{Depth: 3, MaxNodes: tt.nodes}); got != tt.want {
+			t.Errorf("Sdump with MaxNodes %d = %q, want %q", tt.nodes, got, tt.want)
+		}
+	}
+
+	got := Sdump(make([]int, 1000000), Options{Depth: 1, MaxNodes: 3})
+	if want := "[]int{\n• int{0}\n• int{0}\n• …(truncated, 2 of 1000000 shown)}"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+	got = Sdump(v, Options{Depth: 3, MaxOutputBytes: 40})
+	if want := "gdump.budgetRecord{\n• A:[]int{\n• •\n…(truncated at 40 bytes)"; got != want {
+		t.Errorf("Sdump = %q, want %q", got, want)
+	}
+}
+
+type bytesRecord struct {
+	Key  []byte
+	Arr  [4]byte
+	Nil  []byte
+	Text []byte
+}
+
+// TestBytesFormat prints the byte slices and arrays in hex, base64 and the
+// hexdump lines indented, cut at MaxStringLen, and the valid UTF-8 quoted
+// by default.
+func TestBytesFormat(t *testing.T) {
+	v := bytesRecord{Key: []byte{0xde, 0xad, 0xbe, 0xef}, Arr: [4]byte{1, 2, 3, 4}, Text: []byte("hello, world! 0123456789")}
+	tests := []struct {
+		name   string
+		format BytesFormat
+		max    int
+		want   string
+	}{
+		{"auto", BytesAuto, 0, "gdump.bytesRecord{\n• Key:[]uint8{0xdeadbeef}\n• Arr:[4]uint8{\"\\x01\\x02\\x03\\x04\"}\n• Nil:[]uint8{nil}\n• Text:[]uint8{\"hello, world! 0123456789\"}}"},
+		{"hex", BytesHex, 0, "gdump.bytesRecord{\n• Key:[]uint8{0xdeadbeef}\n• Arr:[4]uint8{0x01020304}\n• Nil:[]uint8{nil}\n• Text:[]uint8{0x68656c6c6f2c20776f726c64212030313233343536373839}}"},
+		{"base64", BytesBase64, 0, "gdump.bytesRecord{\n• Key:[]uint8{3q2+7w==}\n• Arr:[4]uint8{AQIDBA==}\n• Nil:[]uint8{nil}\n• Text:[]uint8{aGVsbG8sIHdvcmxkISAwMTIzNDU2Nzg5}}"},
+		{"hexdump", BytesHexdump, 0, "gdump.bytesRecord{" +
+			"\n• Key:[]uint8{\n• • 00000000  de ad be ef                                       |....|}" +
+			"\n• Arr:[4]uint8{\n• • 00000000  01 02 03 04                                       |....|}" +
+			"\n• Nil:[]uint8{nil}" +
+			"\n• Text:[]uint8{\n• • 00000000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 20 30 31  |hello, world! 01|" +
+			"\n• • 00000010  32 33 34 35 36 37 38 39                           |23456789|}}"},
+		{"hexdump cut", BytesHexdump, 3, "gdump.bytesRecord{" +
+			"\n• Key:[]uint8{\n• • 00000000  de ad be                                          |...|…(+1 more)}" +
+			"\n• Arr:[4]uint8{\n• • 00000000  01 02 03                                          |...|…(+1 more)}" +
+			"\n• Nil:[]uint8{nil}" +
+			"\n• Text:[]uint8{\n• • 00000000  68 65 6c                                          |hel|…(+21 more)}}"},
+		{"hex cut", BytesHex, 2, "gdump.bytesRecord{\n• Key:[]uint8{0xdead…(+2 more)}\n• Arr:[4]uint8{0x0102…(+2 more)}\n• Nil:[]uint8{nil}\n• Text:[]uint8{0x6865…(+22 more)}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(v, Options{Depth: 2, BytesFormat: tt.format, MaxStringLen: tt.max}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// the lines of the documents are not indented
+	got := Sdump(v.Text, Options{Depth: 1, BytesFormat: BytesHexdump, Format: FormatJSON})
+	if want := `"00000000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 20 30 31  |hello, world! 01|\n00000010  32 33 34 35 36 37 38 39                           |23456789|"`; got != want {
+		t.Errorf("Sdump = %s, want %s", got, want)
+	}
+	if got, want := ValueDumpInline(v.Key, 1, nil), "[]uint8{0xdeadbeef}"; got != want {
+		t.Errorf("ValueDumpInline = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,15 @@
+package gdump
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// exposeField returns the unexported field fv readable like an exported one
+// if it is addressable. Otherwise, fv is returned as it is.
+func exposeField(fv reflect.Value) reflect.Value {
+	if !fv.CanAddr() {
+		return fv
+	}
+	return reflect.NewAt(fv.Type(), unsafe.Pointer(fv.UnsafeAddr())).Elem()
+}
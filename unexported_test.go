@@ -0,0 +1,57 @@
+package gdump
+
+import (
+	"reflect"
+	"testing"
+)
+
+type unexportedInner struct {
+	n int
+	s []string
+}
+
+type unexportedOuter struct {
+	Pub  int
+	priv unexportedInner
+	p    *int
+	m    map[string]int
+}
+
+// TestShowUnexported dumps the unexported fields of the addressable or copied
+// structs like the exported ones, and falls back to the markers for the
+// ones not readable.
+func TestShowUnexported(t *testing.T) {
+	x := 7
+	v := unexportedOuter{Pub: 1, priv: unexportedInner{2, []string{"a"}}, p: &x, m: map[string]int{"k": 3}}
+	const fields = "• Pub:int{1}\n• priv:gdump.unexportedInner{\n• • n:int{2}\n• • s:[]string{\n• • • string{a}}}\n• p:*int{&7}\n• m:map[string]int{\n• • k:int{3}}}"
+	tests := []struct {
+		name  string
+		value interface{}
+		opts  Options
+		want  string
+	}{
+		{"pointer", &v, Options{Depth: 3, ShowUnexported: true}, "*gdump.unexportedOuter{\n" + fields},
+		{"copied", v, Options{Depth: 3, ShowUnexported: true}, "gdump.unexportedOuter{\n" + fields},
+		{"map value", map[string]unexportedInner{"a": {1, nil}}, Options{Depth: 3, ShowUnexported: true}, "map[string]gdump.unexportedInner{\n• a:gdump.unexportedInner{\n• • n:int{1}\n• • s:[]string{nil}}}"},
+		{"hidden", &v, Options{Depth: 3}, "*gdump.unexportedOuter{\n• Pub:int{1}\n• priv:gdump.unexportedInner{…}\n• p:*int{…}\n• m:map[string]int{…}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, tt.opts); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// with the compact slices and the newline of the Dumpers
+	want := "*gdump.unexportedOuter{\n• Pub:int{1}\n• priv:gdump.unexportedInner{\n• • n:int{2}\n• • s:[]string{a}}\n• p:*int{&7}\n• m:map[string]int{\n• • k:int{3}}}\n"
+	if got := New(WithDepth(3), WithUnexported(true)).Sdump(&v); got != want {
+		t.Errorf("Dumper.Sdump = %q, want %q", got, want)
+	}
+
+	// a field read without an address can be neither copied nor exposed
+	ds := &dumpState{Options: Options{Depth: 2, ShowUnexported: true}}
+	got := ds.valueString(reflect.ValueOf(v).Field(1), 2, 0, 0, "", false, false)
+	if want = "gdump.unexportedInner{\n• n:2\n• s:[]string{unexported}}"; got != want {
+		t.Errorf("valueString = %q, want %q", got, want)
+	}
+}
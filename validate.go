@@ -0,0 +1,74 @@
+package gdump
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validationMarker - the marker of the rules violated by a field
+const validationMarker = "⚠"
+
+// validationString returns the rules of the validate directive violated by
+// the field value v, e.g. " ⚠ required ⚠ min:1", or an empty string.
+// The rules are:
+//   - required: v is not zero.
+//   - nonempty: v has elements or characters.
+//   - min:N and max:N: the number v or the length of v is in the range.
+func validationString(v reflect.Value, rules []string) string {
+	var out string
+	for _, rule := range rules {
+		if !isValid(v, rule) {
+			out += " " + validationMarker + " " + rule
+		}
+	}
+	return out
+}
+
+// isValid returns false if v violates the rule. Unknown rules and the rules
+// not applicable to v are ignored.
+func isValid(v reflect.Value, rule string) bool {
+	name, arg, _ := strings.Cut(rule, ":")
+	switch name {
+	case "required":
+		return !v.IsZero()
+	case "nonempty":
+		if n, ok := lengthOf(v); ok {
+			return n > 0
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return true
+		}
+		var x float64
+		switch {
+		case isInt(v):
+			x = float64(v.Int())
+		case isUint(v):
+			x = float64(v.Uint())
+		case isFloat(v):
+			x = v.Float()
+		default:
+			n, ok := lengthOf(v)
+			if !ok {
+				return true
+			}
+			x = float64(n)
+		}
+		if name == "min" {
+			return x >= limit
+		}
+		return x <= limit
+	}
+	return true
+}
+
+// lengthOf returns the length of the string, slice, array or map v.
+func lengthOf(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	}
+	return 0, false
+}
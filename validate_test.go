@@ -0,0 +1,44 @@
+package gdump
+
+import "testing"
+
+type validatedConfig struct {
+	Name   string         `gdump:"validate=required"`
+	Port   int            `gdump:"validate=min:1,max:65535"`
+	Hosts  []string       `gdump:"validate=nonempty,max:2"`
+	Ratio  float64        `gdump:"validate=max:1"`
+	Legacy string         `dump:"validate=required"`
+	Extra  map[string]int `gdump:"validate=unknown"`
+}
+
+// TestShowValidation marks the fields violating their rules, the lengths
+// checked against the limits of the slices, and ignores the unknown rules.
+func TestShowValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		value validatedConfig
+		want  string
+	}{
+		{"missing", validatedConfig{Port: 70000, Ratio: 1.5},
+			"gdump.validatedConfig{\n• Name:string{} ⚠ required\n• Port:int{70000} ⚠ max:65535\n• Hosts:[]string{nil} ⚠ nonempty\n" +
+				"• Ratio:float64{1.5} ⚠ max:1\n• Legacy:string{} ⚠ required\n• Extra:map[string]int{nil}}"},
+		{"too many", validatedConfig{Name: "a", Port: 80, Hosts: []string{"a", "b", "c"}, Legacy: "x"},
+			"gdump.validatedConfig{\n• Name:string{a}\n• Port:int{80}\n• Hosts:[]string{\n• • string{a}\n• • string{b}\n• • string{c}} ⚠ max:2\n" +
+				"• Ratio:float64{0}\n• Legacy:string{x}\n• Extra:map[string]int{nil}}"},
+		{"valid", validatedConfig{Name: "a", Port: 1, Hosts: []string{"a"}, Ratio: 1, Legacy: "x"},
+			"gdump.validatedConfig{\n• Name:string{a}\n• Port:int{1}\n• Hosts:[]string{\n• • string{a}}\n" +
+				"• Ratio:float64{1}\n• Legacy:string{x}\n• Extra:map[string]int{nil}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.value, Options{Depth: 2, ShowValidation: true}); got != tt.want {
+				t.Errorf("Sdump = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	want := "gdump.validatedConfig{\n• Name:string{}\n• Port:int{70000}\n• Hosts:[]string{nil}\n• Ratio:float64{0}\n• Legacy:string{}\n• Extra:map[string]int{nil}}"
+	if got := Sdump(validatedConfig{Port: 70000}, Options{Depth: 2}); got != want {
+		t.Errorf("Sdump = %q without ShowValidation, want %q", got, want)
+	}
+}
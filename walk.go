@@ -0,0 +1,45 @@
+package gdump
+
+import "strings"
+
+// Walk calls fn for each node of the tree of value built with opts in the
+// depth-first order with the dotted path of the node from the top, e.g.
+// "Spec.Items.0.Name". The struct fields are visited in the declaration order
+// and the map entries in the sorted order of the keys like the dump, so that
+// the walks of the same value visit the same sequence of the nodes. The
+// children of the node are skipped if fn returns false.
+func Walk(value interface{}, opts Options, fn func(path string, node *DumpNode) bool) {
+	walkNode(BuildTree(value, opts), nil, fn)
+}
+
+func walkNode(node *DumpNode, path []string, fn func(path string, node *DumpNode) bool) {
+	if !fn(strings.Join(path, "."), node) {
+		return
+	}
+	for _, child := range node.Children {
+		walkNode(child, append(path, child.Name), fn)
+	}
+}
+
+// FlatEntry - a leaf value of a flattened value
+type FlatEntry struct {
+	// Path - the dotted path of the value from the top
+	Path string
+	// Type - the type name of the value
+	Type string
+	// Value - the printed value
+	Value string
+}
+
+// Flatten returns the leaf values of value dumped with opts in the order
+// visited by Walk.
+func Flatten(value interface{}, opts Options) []FlatEntry {
+	var entries []FlatEntry
+	Walk(value, opts, func(path string, node *DumpNode) bool {
+		if len(node.Children) == 0 {
+			entries = append(entries, FlatEntry{Path: path, Type: node.Type, Value: node.Value})
+		}
+		return true
+	})
+	return entries
+}
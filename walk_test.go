@@ -0,0 +1,74 @@
+package gdump
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type walkOrder struct {
+	B int
+	A map[string]int
+	C []string
+}
+
+// walkPaths returns the paths and values of the nodes visited by Walk.
+func walkPaths(value interface{}, opts Options) []string {
+	var nodes []string
+	Walk(value, opts, func(path string, node *DumpNode) bool {
+		nodes = append(nodes, path+"="+node.Value)
+		return true
+	})
+	return nodes
+}
+
+// TestWalkOrder visits the fields in the declaration order and the map
+// entries in the order of the keys printed by the dump, the same on every
+// walk of the value.
+func TestWalkOrder(t *testing.T) {
+	m := map[string]int{}
+	for i := 0; i < 32; i++ {
+		m[fmt.Sprintf("k%02d", i)] = i
+	}
+	v := walkOrder{B: 1, A: m, C: []string{"x"}}
+	opts := Options{Depth: 3}
+	first := walkPaths(v, opts)
+	for i := 0; i < 20; i++ {
+		if got := walkPaths(v, opts); !reflect.DeepEqual(got, first) {
+			t.Fatalf("walk %d = %q, want %q", i, got, first)
+		}
+	}
+	if want := []string{"=", "B=1", "A=", "A.k00=0", "A.k01=1"}; !reflect.DeepEqual(first[:5], want) {
+		t.Errorf("Walk = %q, want to start with %q", first, want)
+	}
+	if got, want := first[len(first)-3:], []string{"A.k31=31", "C=", "C.0=x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk = %q, want to end with %q", first, want)
+	}
+
+	// the flattened leaves follow the lines of the dump
+	text := Sdump(v, opts)
+	last := -1
+	for _, e := range Flatten(v, opts) {
+		name := e.Path[strings.LastIndex(e.Path, ".")+1:]
+		i := strings.Index(text, "• "+name+":")
+		if i < 0 {
+			i = strings.Index(text[last+1:], "• "+e.Type+"{"+e.Value+"}") + last + 1
+		}
+		if i <= last {
+			t.Errorf("Flatten entry %q out of the order of the dump:\n%s", e.Path, text)
+		}
+		last = i
+	}
+
+	// in the configured order of the keys
+	desc := Options{Depth: 3, MapKeyLess: func(a, b reflect.Value) bool { return a.String() > b.String() }}
+	flat := Flatten(walkOrder{A: map[string]int{"a": 1, "m": 2, "z": 3}}, desc)
+	var paths []string
+	for _, e := range flat {
+		paths = append(paths, e.Path)
+	}
+	if want := []string{"B", "A.z", "A.m", "A.a", "C"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Flatten paths = %q, want %q", paths, want)
+	}
+}
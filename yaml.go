@@ -0,0 +1,88 @@
+package gdump
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// yamlPlain - the strings printed without quotes in YAML
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./()-]*$`)
+
+// yamlReserved - the plain strings read as booleans or null in YAML
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true,
+}
+
+// yamlString returns value dumped as a YAML document.
+func (s *dumpState) yamlString(value interface{}) string {
+	var b bytes.Buffer
+	s.writeYAML(&b, s.documentTree(value), "")
+	return strings.TrimSpace(b.String())
+}
+
+// writeYAML writes the node n printed after its key or list marker. The
+// entries of n are indented by indent. The empty lists and objects, e.g. the
+// structs without the fields, are printed in the flow style as [] and {}.
+func (s *dumpState) writeYAML(b *bytes.Buffer, n *DumpNode, indent string) {
+	switch {
+	case isDocumentLeaf(n):
+		b.WriteString(" " + yamlScalar(n.Value, jsonLeaf(n)) + "\n")
+	case isDocumentList(n):
+		if len(n.Children) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		s.writeYAMLItems(b, n, indent)
+	default:
+		if keys, _ := s.documentEntries(n); len(keys) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		s.writeYAMLEntries(b, n, indent, indent)
+	}
+}
+
+// writeYAMLEntries writes the entries of the object n indented by indent.
+// The first entry is prefixed by first instead, e.g. to follow a list marker.
+func (s *dumpState) writeYAMLEntries(b *bytes.Buffer, n *DumpNode, first, indent string) {
+	keys, values := s.documentEntries(n)
+	for i, key := range keys {
+		prefix := indent
+		if i == 0 {
+			prefix = first
+		}
+		b.WriteString(prefix + yamlScalar(key, jsonQuote(key)) + ":")
+		s.writeYAML(b, values[i], indent+"  ")
+	}
+}
+
+// writeYAMLItems writes the elements of the list n indented by indent. The
+// first entry of an object element is printed in the line of its marker.
+func (s *dumpState) writeYAMLItems(b *bytes.Buffer, n *DumpNode, indent string) {
+	for _, c := range n.Children {
+		if keys, _ := s.documentEntries(c); !isDocumentLeaf(c) && !isDocumentList(c) && len(keys) > 0 {
+			s.writeYAMLEntries(b, c, indent+"- ", indent+"  ")
+			continue
+		}
+		b.WriteString(indent + "-")
+		s.writeYAML(b, c, indent+"  ")
+	}
+}
+
+// yamlScalar returns the string str printed in YAML. The JSON value of str,
+// which is also valid in YAML, is used unless str is safely printed as it is.
+func yamlScalar(str, json string) string {
+	if !strings.HasPrefix(json, `"`) {
+		// null, numbers and booleans
+		return json
+	}
+	if yamlPlain.MatchString(str) && !yamlReserved[strings.ToLower(str)] &&
+		!strings.HasSuffix(str, " ") && !strings.Contains(str, " #") {
+		return str
+	}
+	return json
+}
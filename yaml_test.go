@@ -0,0 +1,39 @@
+package gdump
+
+import "testing"
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     interface{}
+		types bool
+		want  string
+	}{
+		{"empty struct", struct{}{}, false, `{}`},
+		{"empty struct typed", jsonEmpty{}, true, `__type: gdump.jsonEmpty`},
+		{"empty fields", struct {
+			E jsonEmpty
+			P *jsonEmpty
+			M map[string]int
+			N map[string]int
+			L []int
+		}{P: &jsonEmpty{}, M: map[string]int{}, L: []int{}}, false,
+			"E: {}\nP: {}\nM: {}\n\"N\": null\nL: []"},
+		{"empty elements", []interface{}{jsonEmpty{}, map[string]int{}, []int{}}, false,
+			"- {}\n- {}\n- []"},
+		{"zero struct", struct {
+			I jsonInner
+			X int
+		}{X: 1}, false, "I:\n  A: 0\n  B: \"\"\nX: 1"},
+		{"list of structs", []jsonInner{{1, "yes"}, {2, "b c"}}, false,
+			"- A: 1\n  B: \"yes\"\n- A: 2\n  B: b c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sdump(tt.v, Options{Depth: 4, Format: FormatYAML, JSONTypes: tt.types})
+			if got != tt.want {
+				t.Errorf("Sdump =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}